@@ -0,0 +1,136 @@
+package go_nationalflooddata
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CacheStatusHeader is set to "HIT" on responses served from a Cache.
+const CacheStatusHeader = "X-Cache"
+
+// Cache stores raw response bodies keyed by request. Implementations must be
+// safe for concurrent use.
+type Cache interface {
+	// Get returns the cached body for key, if present.
+	Get(key string) ([]byte, bool)
+
+	// Set stores body under key.
+	Set(key string, body []byte)
+}
+
+// MemoryCache is an in-process Cache whose entries expire after a fixed TTL.
+type MemoryCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+}
+
+type memoryCacheEntry struct {
+	body    []byte
+	expires time.Time
+}
+
+// NewMemoryCache returns a MemoryCache whose entries live for ttl. A ttl of
+// zero keeps entries for the lifetime of the cache.
+func NewMemoryCache(ttl time.Duration) *MemoryCache {
+	return &MemoryCache{
+		ttl:     ttl,
+		entries: make(map[string]memoryCacheEntry),
+	}
+}
+
+// Get implements Cache.
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.body, true
+}
+
+// Set implements Cache.
+func (c *MemoryCache) Set(key string, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := memoryCacheEntry{body: body}
+	if c.ttl > 0 {
+		entry.expires = time.Now().Add(c.ttl)
+	}
+	c.entries[key] = entry
+}
+
+// cacheMiddleware serves repeated GET requests from cache. Only successful
+// responses are stored, under cacheKey.
+func cacheMiddleware(cache Cache) Middleware {
+	return func(next Handler) Handler {
+		return func(inv *Invocation) (*http.Response, error) {
+			if inv.Request.Method != http.MethodGet {
+				return next(inv)
+			}
+
+			key := cacheKey(inv.Request)
+			if body, ok := cache.Get(key); ok {
+				return &http.Response{
+					Status:        "200 OK",
+					StatusCode:    http.StatusOK,
+					Proto:         "HTTP/1.1",
+					ProtoMajor:    1,
+					ProtoMinor:    1,
+					Header:        http.Header{CacheStatusHeader: []string{"HIT"}},
+					Body:          io.NopCloser(bytes.NewReader(body)),
+					ContentLength: int64(len(body)),
+					Request:       inv.Request,
+				}, nil
+			}
+
+			resp, err := next(inv)
+			if err != nil || resp.StatusCode < 200 || resp.StatusCode > 299 {
+				return resp, err
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+
+			cache.Set(key, body)
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+
+			return resp, nil
+		}
+	}
+}
+
+// cacheKey returns the key a request's response is cached under: the
+// request URL, which encodes every option the endpoint accepts, scoped to
+// a hash of the API key. Keys with different entitlements then never share
+// a response, and the key itself, sent in the x-api-key header or the
+// dynamic map's key parameter, never reaches the Cache.
+func cacheKey(req *http.Request) string {
+	u := *req.URL
+	q := u.Query()
+	apiKey := req.Header.Get("x-api-key")
+	if k := q.Get("key"); k != "" {
+		apiKey = k
+		q.Del("key")
+		u.RawQuery = q.Encode()
+	}
+
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:8]) + " " + u.String()
+}
@@ -0,0 +1,200 @@
+// Package cassette records National Flood Data API interactions to files and
+// replays them, so integration tests exercise real payloads without network
+// access. API keys and presigned URL credentials are scrubbed before
+// anything is written.
+package cassette
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Redacted replaces scrubbed secrets in recorded interactions.
+const Redacted = "REDACTED"
+
+// Cassette is the set of interactions stored in a cassette file.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single request and the response it received.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded HTTP request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response.
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitempty"`
+}
+
+// Body is a payload that is stored as text when it is valid UTF-8, such as
+// JSON, and base64 encoded otherwise, such as tiles and map images.
+type Body []byte
+
+type encodedBody struct {
+	Text   string `json:"text,omitempty"`
+	Base64 string `json:"base64,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (b Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(encodedBody{Text: string(b)})
+	}
+	return json.Marshal(encodedBody{Base64: base64.StdEncoding.EncodeToString(b)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var enc encodedBody
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	if enc.Base64 == "" {
+		*b = Body(enc.Text)
+		return nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(enc.Base64)
+	if err != nil {
+		return fmt.Errorf("decoding body: %w", err)
+	}
+	*b = raw
+	return nil
+}
+
+// Load reads a cassette file.
+func Load(path string) (*Cassette, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading cassette: %w", err)
+	}
+
+	var c Cassette
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, fmt.Errorf("parsing cassette %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to path, creating parent directories as needed.
+func (c *Cassette) Save(path string) error {
+	raw, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding cassette: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating cassette directory: %w", err)
+	}
+	return os.WriteFile(path, append(raw, '\n'), 0o644)
+}
+
+// MatchKey identifies the requests an interaction can replay: the method,
+// the path and the query with keys and values sorted. Secrets are scrubbed
+// first, so a recording made with one key replays for any other.
+func MatchKey(method string, u *url.URL) string {
+	q := scrubQuery(u.Query())
+	for _, values := range q {
+		sort.Strings(values)
+	}
+	return method + " " + u.Path + "?" + q.Encode()
+}
+
+// scrubQuery returns a copy of q with the dynamic map's key parameter and
+// the X-Amz-* parameters of presigned batch result URLs redacted. The
+// presigned parameters are credentials, and change with every batch.
+func scrubQuery(q url.Values) url.Values {
+	scrubbed := make(url.Values, len(q))
+	for k, v := range q {
+		if k == "key" || strings.HasPrefix(strings.ToLower(k), "x-amz-") {
+			scrubbed.Set(k, Redacted)
+			continue
+		}
+		scrubbed[k] = append([]string(nil), v...)
+	}
+	return scrubbed
+}
+
+// scrubURL returns raw with its query scrubbed, or raw unchanged if it is
+// not a URL.
+func scrubURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.RawQuery == "" {
+		return raw
+	}
+	u.RawQuery = scrubQuery(u.Query()).Encode()
+	return u.String()
+}
+
+// scrubHeader returns a copy of h with the x-api-key header redacted.
+func scrubHeader(h http.Header) http.Header {
+	scrubbed := h.Clone()
+	if scrubbed.Get("x-api-key") != "" {
+		scrubbed.Set("x-api-key", Redacted)
+	}
+	return scrubbed
+}
+
+// scrubBody redacts a top-level "apiKey" field in a JSON body, as sent to
+// /databatch. Other bodies are returned unchanged.
+func scrubBody(body []byte) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return body
+	}
+	if _, ok := fields["apiKey"]; !ok {
+		return body
+	}
+
+	fields["apiKey"], _ = json.Marshal(Redacted)
+	scrubbed, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return scrubbed
+}
+
+// scrubResponseBody redacts the presigned URL in the top-level "result"
+// field of a JSON body, as returned by /databatch. Other bodies are
+// returned unchanged.
+func scrubResponseBody(body []byte) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return body
+	}
+	var result string
+	if err := json.Unmarshal(fields["result"], &result); err != nil {
+		return body
+	}
+	scrubbedURL := scrubURL(result)
+	if scrubbedURL == result {
+		return body
+	}
+
+	fields["result"], _ = json.Marshal(scrubbedURL)
+	scrubbed, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return scrubbed
+}
@@ -0,0 +1,195 @@
+package cassette
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+)
+
+// ErrNoInteraction is returned in replay mode when the cassette holds no
+// unused interaction matching a request.
+var ErrNoInteraction = errors.New("cassette: no matching interaction")
+
+// Mode selects whether a Recorder talks to the network.
+type Mode int
+
+const (
+	// ModeReplay serves requests from the cassette and never touches the
+	// network.
+	ModeReplay Mode = iota
+
+	// ModeRecord sends requests to the real transport and records them.
+	ModeRecord
+)
+
+// Recorder is an http.RoundTripper that records or replays interactions.
+// Install it as Service.HTTPClient's transport, for example with
+// nfd.WithHTTPClient(rec.Client()).
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// Option configures a Recorder.
+type Option func(*Recorder)
+
+// WithTransport sets the transport used in ModeRecord. It defaults to
+// http.DefaultTransport.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(r *Recorder) {
+		r.transport = rt
+	}
+}
+
+// New returns a Recorder for the cassette at path. In ModeReplay the file
+// must exist; in ModeRecord it is overwritten by Stop.
+func New(path string, mode Mode, opts ...Option) (*Recorder, error) {
+	r := &Recorder{
+		path:      path,
+		mode:      mode,
+		transport: http.DefaultTransport,
+		cassette:  &Cassette{},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if mode == ModeReplay {
+		c, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = c
+		r.used = make([]bool, len(c.Interactions))
+	}
+
+	return r, nil
+}
+
+// ModeFromEnv returns ModeRecord when the named environment variable is
+// set to a non-empty value, and ModeReplay otherwise. It lets a suite
+// re-record cassettes with, say, NFD_RECORD=1 go test ./...
+func ModeFromEnv(name string) Mode {
+	if os.Getenv(name) != "" {
+		return ModeRecord
+	}
+	return ModeReplay
+}
+
+// Client returns an http.Client that uses the Recorder as its transport.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Stop writes the recorded interactions to the cassette file. It does
+// nothing in ModeReplay.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.cassette.Save(r.path)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.mode == ModeRecord {
+		return r.record(req)
+	}
+	return r.replay(req)
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("cassette: reading request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("cassette: reading response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    scrubURL(req.URL.String()),
+			Header: scrubHeader(req.Header),
+			Body:   scrubBody(reqBody),
+		},
+		Response: Response{
+			Status: resp.StatusCode,
+			Header: resp.Header.Clone(),
+			Body:   scrubResponseBody(respBody),
+		},
+	})
+
+	return resp, nil
+}
+
+// replay serves the first unused interaction matching req, so repeated
+// identical requests replay in the order they were recorded.
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	key := MatchKey(req.Method, req.URL)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] {
+			continue
+		}
+
+		u, err := url.Parse(in.Request.URL)
+		if err != nil {
+			return nil, fmt.Errorf("cassette: interaction %d: %w", i, err)
+		}
+		if MatchKey(in.Request.Method, u) != key {
+			continue
+		}
+
+		r.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+			StatusCode:    in.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Response.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(in.Response.Body)),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrNoInteraction, key)
+}
@@ -0,0 +1,182 @@
+package cassette_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+	"github.com/kmesiab/go-nationalflooddata/cassette"
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+)
+
+var floodDataOpts = client.FloodDataOptions{
+	SearchType: client.SearchTypeAddressParcel,
+	Address:    "430 Australian Ave Palm Beach, FL 33480",
+	Elevation:  true,
+}
+
+// recordCassette records a /data lookup, a batch and a vector tile against
+// the fake server and returns the cassette path.
+func recordCassette(t *testing.T) string {
+	t.Helper()
+
+	srv := nfdtest.NewServer()
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "flood.json")
+	rec, err := cassette.New(path, cassette.ModeRecord, cassette.WithTransport(srv.Client().Transport))
+	require.NoError(t, err)
+
+	svc, err := nfd.NewService(nfdtest.DefaultAPIKey, nfd.WithBaseURL(srv.BaseURL()), nfd.WithHTTPClient(rec.Client()))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = svc.GetFloodData(ctx, floodDataOpts)
+	require.NoError(t, err)
+	_, err = svc.GetFloodDataBatch(ctx, client.BatchDataRequest{
+		Requests: []client.BatchRequest{{ID: "r1", SearchType: client.SearchTypeCoord, Lat: "26.70", Lng: "-80.04"}},
+	})
+	require.NoError(t, err)
+	_, err = svc.GetFloodVectorTile(ctx, 13, 2043, 3140)
+	require.NoError(t, err)
+
+	require.NoError(t, rec.Stop())
+	return path
+}
+
+func TestRecorder_ShouldScrubAPIKeys(t *testing.T) {
+	path := recordCassette(t)
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), nfdtest.DefaultAPIKey)
+
+	c, err := cassette.Load(path)
+	require.NoError(t, err)
+	require.Len(t, c.Interactions, 3)
+	assert.Equal(t, cassette.Redacted, c.Interactions[0].Request.Header.Get("x-api-key"))
+
+	var batch client.BatchDataRequest
+	require.NoError(t, json.Unmarshal(c.Interactions[1].Request.Body, &batch))
+	assert.Equal(t, cassette.Redacted, batch.APIKey)
+}
+
+func TestRecorder_ShouldReplayWithoutNetwork(t *testing.T) {
+	path := recordCassette(t)
+
+	rec, err := cassette.New(path, cassette.ModeReplay)
+	require.NoError(t, err)
+
+	// The server is gone, so any request that is not replayed fails.
+	svc, err := nfd.NewService("another-key", nfd.WithBaseURL("http://127.0.0.1:1/v3"), nfd.WithHTTPClient(rec.Client()))
+	require.NoError(t, err)
+
+	resp, err := svc.GetFloodData(context.Background(), floodDataOpts)
+	require.NoError(t, err)
+	assert.Equal(t, floodDataOpts.Address, resp.Request.Address)
+	assert.NotNil(t, resp.Result.Elevation)
+
+	tile, err := svc.GetFloodVectorTile(context.Background(), 13, 2043, 3140)
+	require.NoError(t, err)
+	assert.Equal(t, nfdtest.DefaultFixtures().VectorTile, tile)
+
+	// Each interaction replays once.
+	_, err = svc.GetFloodVectorTile(context.Background(), 13, 2043, 3140)
+	assert.ErrorIs(t, err, cassette.ErrNoInteraction)
+}
+
+func TestRecorder_ShouldNotReplayMismatchedQuery(t *testing.T) {
+	rec, err := cassette.New(recordCassette(t), cassette.ModeReplay)
+	require.NoError(t, err)
+
+	svc, err := nfd.NewService("k", nfd.WithHTTPClient(rec.Client()))
+	require.NoError(t, err)
+
+	opts := floodDataOpts
+	opts.Address = "1 Other St"
+	_, err = svc.GetFloodData(context.Background(), opts)
+	assert.ErrorIs(t, err, cassette.ErrNoInteraction)
+}
+
+func TestMatchKey_ShouldNormalizeQuery(t *testing.T) {
+	a, _ := url.Parse("https://example.com/v3/data?lng=2&lat=1&key=secret")
+	b, _ := url.Parse("http://other/v3/data?lat=1&key=other&lng=2")
+
+	assert.Equal(t, cassette.MatchKey("GET", a), cassette.MatchKey("GET", b))
+	assert.NotEqual(t, cassette.MatchKey("GET", a), cassette.MatchKey("POST", b))
+}
+
+type roundTripFunc func(req *http.Request) *http.Response
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+// presigned is a batch result URL signed like the API's S3 links.
+func presigned(signature string) string {
+	return "https://nfd-batch.s3.amazonaws.com/results/b1.json?X-Amz-Algorithm=AWS4-HMAC-SHA256" +
+		"&X-Amz-Credential=AKIAEXAMPLE%2F20260401%2Fus-east-1%2Fs3%2Faws4_request" +
+		"&X-Amz-Date=20260401T120000Z&X-Amz-Expires=3600&X-Amz-Security-Token=session-token" +
+		"&X-Amz-Signature=" + signature + "&X-Amz-SignedHeaders=host"
+}
+
+func TestRecorder_ShouldScrubPresignedURLs(t *testing.T) {
+	transport := roundTripFunc(func(req *http.Request) *http.Response {
+		body := `[{"id": "r1", "status": "OK"}]`
+		if req.Method == http.MethodPost {
+			body = `{"batch_id": "b1", "result": "` + presigned("first-signature") + `"}`
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     make(http.Header),
+		}
+	})
+
+	path := filepath.Join(t.TempDir(), "batch.json")
+	rec, err := cassette.New(path, cassette.ModeRecord, cassette.WithTransport(transport))
+	require.NoError(t, err)
+	svc, err := nfd.NewService("k", nfd.WithHTTPClient(rec.Client()))
+	require.NoError(t, err)
+
+	batch, err := svc.GetFloodDataBatch(context.Background(), client.BatchDataRequest{
+		Requests: []client.BatchRequest{{ID: "r1", SearchType: client.SearchTypeCoord, Lat: "26.70", Lng: "-80.04"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, presigned("first-signature"), batch.Result, "the caller sees the real URL")
+	_, err = svc.GetFloodDataBatchResult(context.Background(), batch)
+	require.NoError(t, err)
+	require.NoError(t, rec.Stop())
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	for _, secret := range []string{"first-signature", "AKIAEXAMPLE", "session-token"} {
+		assert.NotContains(t, string(raw), secret)
+	}
+
+	// A later batch is signed differently but still replays.
+	rec, err = cassette.New(path, cassette.ModeReplay)
+	require.NoError(t, err)
+	svc, err = nfd.NewService("k", nfd.WithHTTPClient(rec.Client()))
+	require.NoError(t, err)
+
+	_, err = svc.GetFloodDataBatch(context.Background(), client.BatchDataRequest{
+		Requests: []client.BatchRequest{{ID: "r1", SearchType: client.SearchTypeCoord, Lat: "26.70", Lng: "-80.04"}},
+	})
+	require.NoError(t, err)
+	results, err := svc.GetFloodDataBatchResult(context.Background(), &client.FloodDataBatch{BatchID: "b1", Result: presigned("second-signature")})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "r1", results[0].ID)
+}
@@ -12,6 +12,12 @@ type ErrorResponse struct {
 	Message  string         `json:"message"` // Error message
 }
 
+// StatusCode returns the status of the error. The typed errors embedding
+// ErrorResponse share it.
+func (r *ErrorResponse) StatusCode() int {
+	return r.Status
+}
+
 func (r *ErrorResponse) Error() string {
 	return fmt.Sprintf("%v %v: %d %v",
 		r.Response.Request.Method, r.Response.Request.URL,
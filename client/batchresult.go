@@ -0,0 +1,10 @@
+package client
+
+// BatchResult is one element of a batch result: the flood data for the
+// BatchRequest with the same ID.
+type BatchResult struct {
+	// ID is the ID of the BatchRequest this result answers.
+	ID string `json:"id"`
+
+	Response
+}
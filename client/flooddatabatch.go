@@ -0,0 +1,12 @@
+// Code generated by nfdgen from docs/openapi.json; DO NOT EDIT.
+
+package client
+
+// FloodDataBatch represents a batch response from the FEMA Flood Data API, used for processing multiple requests at once.
+type FloodDataBatch struct {
+	// BatchID is the unique identifier for the batch request.
+	BatchID string `json:"batch_id"`
+
+	// Result is a presigned URL for an S3 object containing the batch result data.
+	Result string `json:"result"`
+}
@@ -68,12 +68,3 @@ type BatchDataRequest struct {
 	// Requests is a list of batch request items to be processed together.
 	Requests []BatchRequest `json:"requests"`
 }
-
-// FloodDataBatch represents a batch response from the FEMA Flood Data API, used for processing multiple requests at once.
-type FloodDataBatch struct {
-	// BatchID is the unique identifier for the batch request.
-	BatchID string `json:"batch_id"`
-
-	// Result is a presigned URL for an S3 object containing the batch result data.
-	Result string `json:"result"`
-}
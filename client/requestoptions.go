@@ -1,46 +1,157 @@
-package client
+// Code generated by nfdgen from docs/openapi.json; DO NOT EDIT.
 
-// SearchType represents the type of search for the API
-type SearchType string
+package client
 
-const (
-	SearchTypeAddressCoord  SearchType = "addresscoord"
-	SearchTypeAddressParcel SearchType = "addressparcel"
-	SearchTypeCoord         SearchType = "coord"
-	SearchTypeCoordParcel   SearchType = "coordparcel"
-	SearchTypePolygon       SearchType = "polygon"
+import (
+	"net/url"
+	"strconv"
 )
 
 // FloodDataOptions represents options for the flood data query
 type FloodDataOptions struct {
+	// SearchType corresponds to the "searchtype" parameter. Specification of location
+	// type.
 	SearchType SearchType
-	Address    string
-	Lat        float64
-	Lng        float64
-	Polygon    string
-	LOMA       bool
-	Elevation  bool
-	Property   bool
-	Parcel     bool
+
+	// Address corresponds to the "address" parameter. Address with street number,
+	// street type, city, state, zip and without punctuation. _Required_ for
+	// searchtypes `addresscoord` and `addressparcel`. Do not include 4 digit zipcode
+	// extension.
+	Address string
+
+	// Lat corresponds to the "lat" parameter. Location's latitude. _Required_ for
+	// searchtype `coord`.
+	Lat float64
+
+	// Lng corresponds to the "lng" parameter. Location's longitude. _Required_ for
+	// searchtype `coord`.
+	Lng float64
+
+	// Polygon corresponds to the "polygon" parameter. Polygon in well known text
+	// format.
+	Polygon string
+
+	// LOMA corresponds to the "loma" parameter. Include LOMA updates.
+	LOMA bool
+
+	// Elevation corresponds to the "elevation" parameter. Include Elevation Data
+	// (Elevation, BFE, Stormsurge) (_Special key required_).
+	Elevation bool
+
+	// Property corresponds to the "property" parameter. Include property data (Only
+	// for seachtype addressparcel) (_Special key required_).
+	Property bool
+
+	// Parcel corresponds to the "parcel" parameter. Include parcel data (_Special key
+	// required_).
+	Parcel bool
+}
+
+// Values encodes the options as /data query parameters.
+func (o FloodDataOptions) Values() url.Values {
+	q := url.Values{}
+	q.Set("searchtype", string(o.SearchType))
+	if o.Address != "" {
+		q.Set("address", o.Address)
+	}
+	if o.Lat != 0 {
+		q.Set("lat", strconv.FormatFloat(o.Lat, 'f', -1, 64))
+	}
+	if o.Lng != 0 {
+		q.Set("lng", strconv.FormatFloat(o.Lng, 'f', -1, 64))
+	}
+	if o.Polygon != "" {
+		q.Set("polygon", o.Polygon)
+	}
+	if o.LOMA {
+		q.Set("loma", "true")
+	}
+	if o.Elevation {
+		q.Set("elevation", "true")
+	}
+	if o.Property {
+		q.Set("property", "true")
+	}
+	if o.Parcel {
+		q.Set("parcel", "true")
+	}
+	return q
 }
 
 // FloodMapRawOptions represents options for the flood map raw query
 type FloodMapRawOptions struct {
-	Lat       float64
-	Lng       float64
-	Size      float64
-	GeoJSON   bool
-	ExcludeX  bool
+	// Lat is the latitude of the center of the square.
+	Lat float64
+
+	// Lng is the longitude of the center of the square.
+	Lng float64
+
+	// Size is the side of the square polygons are returned for, in degrees: 0.04,
+	// 0.06 or 0.08. The API uses 0.08 when it is zero.
+	Size float64
+
+	// GeoJSON corresponds to the "geojson" parameter. When set to false, geojson
+	// content will not be included. The geojson content can be large, so not including
+	// it is often faster.
+	GeoJSON bool
+
+	// ExcludeX corresponds to the "excludex" parameter. When set to true, polygons
+	// with flood zone (fld_zone) "X" will not be included in the query. This generally
+	// makes the response load much smaller and easier to process quickly.
+	ExcludeX bool
+
+	// Elevation corresponds to the "elevation" parameter. When set to true, base flood
+	// elevations (BFE) will be included in geojson format.
 	Elevation bool
 }
 
+// Values encodes the options as /floodmapraw query parameters.
+func (o FloodMapRawOptions) Values() url.Values {
+	q := url.Values{}
+	q.Set("lat", strconv.FormatFloat(o.Lat, 'f', -1, 64))
+	q.Set("lng", strconv.FormatFloat(o.Lng, 'f', -1, 64))
+	if o.Size != 0 {
+		q.Set("size", strconv.FormatFloat(o.Size, 'f', 2, 64))
+	}
+	q.Set("geojson", strconv.FormatBool(o.GeoJSON))
+	q.Set("excludex", strconv.FormatBool(o.ExcludeX))
+	q.Set("elevation", strconv.FormatBool(o.Elevation))
+	return q
+}
+
 // StaticMapOptions represents options for the static map query
 type StaticMapOptions struct {
-	Lat        float64 // Latitude for the map center
-	Lng        float64 // Longitude for the map center
-	Height     int     // Height of the map image
-	Width      int     // Width of the map image
-	ShowMarker bool    // Whether to show a marker on the map
-	ShowLegend bool    // Whether to show a legend on the map
-	Zoom       int     // Zoom level for the map
+	// Lat is the latitude of the map center.
+	Lat float64
+
+	// Lng is the longitude of the map center.
+	Lng float64
+
+	// Height is the height of the map image in pixels.
+	Height int
+
+	// Width is the width of the map image in pixels.
+	Width int
+
+	// ShowMarker shows a marker at Lat, Lng.
+	ShowMarker bool
+
+	// ShowLegend shows the flood zone legend.
+	ShowLegend bool
+
+	// Zoom is the zoom level, between 14 and 16.
+	Zoom int
+}
+
+// Values encodes the options as /staticmap query parameters.
+func (o StaticMapOptions) Values() url.Values {
+	q := url.Values{}
+	q.Set("lat", strconv.FormatFloat(o.Lat, 'f', -1, 64))
+	q.Set("lng", strconv.FormatFloat(o.Lng, 'f', -1, 64))
+	q.Set("height", strconv.Itoa(o.Height))
+	q.Set("width", strconv.Itoa(o.Width))
+	q.Set("showMarker", strconv.FormatBool(o.ShowMarker))
+	q.Set("showLegend", strconv.FormatBool(o.ShowLegend))
+	q.Set("zoom", strconv.Itoa(o.Zoom))
+	return q
 }
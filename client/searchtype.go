@@ -0,0 +1,12 @@
+package client
+
+// SearchType represents the type of search for the API
+type SearchType string
+
+const (
+	SearchTypeAddressCoord  SearchType = "addresscoord"
+	SearchTypeAddressParcel SearchType = "addressparcel"
+	SearchTypeCoord         SearchType = "coord"
+	SearchTypeCoordParcel   SearchType = "coordparcel"
+	SearchTypePolygon       SearchType = "polygon"
+)
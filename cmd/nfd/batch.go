@@ -0,0 +1,445 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+	"github.com/kmesiab/go-nationalflooddata/client"
+)
+
+// Batch modes accepted by -mode.
+const (
+	modeBatch = "batch"
+	modeLocal = "local"
+)
+
+// maxBatchSize is the most requests the API accepts in one batch.
+const maxBatchSize = 20000
+
+// batchFields are the BatchRequest fields a column can be mapped to.
+var batchFields = []string{"id", "searchtype", "address", "lat", "lng", "polygon", "loma", "elevation", "property", "parcel"}
+
+// batchConfig holds the nfd batch flags that shape the requests.
+type batchConfig struct {
+	columns    map[string]string
+	searchType string
+	defaults   client.BatchRequest
+}
+
+// column returns the input column for a BatchRequest field.
+func (c *batchConfig) column(field string) string {
+	if col, ok := c.columns[field]; ok {
+		return col
+	}
+	return field
+}
+
+func runBatch(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: nfd batch -in FILE [flags]")
+		fmt.Fprintln(fs.Output())
+		fmt.Fprintln(fs.Output(), "Looks up flood data for every row of a CSV or JSONL file and writes the rows")
+		fmt.Fprintln(fs.Output(), "back with nfd_* flood columns and an nfd_error column. Columns named like the")
+		fmt.Fprintf(fs.Output(), "request fields (%s) are used\n", strings.Join(batchFields, ", "))
+		fmt.Fprintln(fs.Output(), "as such; use -columns to map others.")
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+
+	var sf serviceFlags
+	sf.register(fs)
+
+	var cfg batchConfig
+	in := fs.String("in", "", `input file, or "-" for stdin`)
+	out := fs.String("out", "-", `output file, or "-" for stdout`)
+	inFormat := fs.String("in-format", "", "input format: csv or jsonl (default from the -in extension)")
+	outFormat := fs.String("out-format", "", "output format: csv or jsonl (default the input format)")
+	columns := fs.String("columns", "", "map request fields to input columns, e.g. address=Street,id=Loan")
+	fs.StringVar(&cfg.searchType, "searchtype", string(client.SearchTypeAddressCoord), "search type for rows without one")
+	fs.BoolVar(&cfg.defaults.LOMA, "loma", false, "include LOMA updates for rows without a loma value")
+	fs.BoolVar(&cfg.defaults.Elevation, "elevation", false, "include elevation data for rows without an elevation value")
+	fs.BoolVar(&cfg.defaults.Property, "property", false, "include property data for rows without a property value")
+	fs.BoolVar(&cfg.defaults.Parcel, "parcel", false, "include parcel data for rows without a parcel value")
+	mode := fs.String("mode", modeBatch, "batch submits to /databatch and polls; local calls /data from a worker pool")
+	workers := fs.Int("workers", 4, "concurrent requests in local mode")
+	poll := fs.Duration("poll", nfd.DefaultBatchPollInterval, "interval between polls for a batch result")
+
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	usageErr := func(format string, a ...interface{}) error {
+		fmt.Fprintf(fs.Output(), format+"\n", a...)
+		fs.Usage()
+		return errUsage
+	}
+	if *in == "" {
+		return usageErr("-in is required")
+	}
+	if *mode != modeBatch && *mode != modeLocal {
+		return usageErr("invalid -mode %q", *mode)
+	}
+	if *workers < 1 {
+		return usageErr("-workers must be at least 1")
+	}
+	if *poll <= 0 {
+		return usageErr("-poll must be positive")
+	}
+	var err error
+	if cfg.columns, err = parseColumns(*columns); err != nil {
+		return usageErr("invalid -columns: %v", err)
+	}
+
+	if *inFormat == "" {
+		if *in == "-" {
+			return usageErr("-in-format is required when reading stdin")
+		}
+		if *inFormat, err = recordFormat(*in); err != nil {
+			return usageErr("%v", err)
+		}
+	}
+	if *outFormat == "" {
+		*outFormat = *inFormat
+	}
+	for _, f := range []string{*inFormat, *outFormat} {
+		if f != formatCSV && f != formatJSONL {
+			return usageErr("invalid format %q", f)
+		}
+	}
+
+	records, err := readInput(*in, *inFormat)
+	if err != nil {
+		return err
+	}
+
+	svc, err := sf.service()
+	if err != nil {
+		return err
+	}
+
+	rows, requests := buildRequests(records, &cfg)
+	if *mode == modeBatch {
+		runRemoteBatch(ctx, svc, rows, requests, *poll, stderr)
+	} else {
+		runLocalBatch(ctx, svc, rows, requests, *workers)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, row := range rows {
+		if row.err != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(stderr, "%d of %d rows failed; see the %s column\n", failed, len(rows), errorColumn)
+	}
+
+	return writeOutput(stdout, *out, *outFormat, rows)
+}
+
+// parseColumns parses a -columns value.
+func parseColumns(s string) (map[string]string, error) {
+	columns := make(map[string]string)
+	if s == "" {
+		return columns, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		field, col, ok := strings.Cut(pair, "=")
+		field = strings.TrimSpace(strings.ToLower(field))
+		if !ok || col == "" {
+			return nil, fmt.Errorf("%q is not field=column", pair)
+		}
+		if !contains(batchFields, field) {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		columns[field] = col
+	}
+	return columns, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func readInput(path, format string) ([]record, error) {
+	if path == "-" {
+		return readRecords(os.Stdin, format)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return readRecords(f, format)
+}
+
+func writeOutput(stdout io.Writer, path, format string, rows []output) error {
+	if path == "-" {
+		return writeRecords(stdout, format, rows)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeRecords(f, format, rows); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// buildRequests turns every record into a BatchRequest. Rows that cannot
+// be turned into a valid request get an error instead; their request is
+// left zero and must be skipped.
+func buildRequests(records []record, cfg *batchConfig) ([]output, []client.BatchRequest) {
+	rows := make([]output, len(records))
+	requests := make([]client.BatchRequest, len(records))
+	ids := make(map[string]bool, len(records))
+
+	for i, rec := range records {
+		rows[i].record = rec
+
+		req, err := buildRequest(rec, i, cfg)
+		if err == nil && ids[req.ID] {
+			err = fmt.Errorf("duplicate id %q", req.ID)
+		}
+		if err != nil {
+			rows[i].err = err.Error()
+			continue
+		}
+
+		ids[req.ID] = true
+		requests[i] = req
+	}
+	return rows, requests
+}
+
+// buildRequest maps a record onto a BatchRequest. Rows without an ID are
+// numbered from 1 in input order.
+func buildRequest(rec record, i int, cfg *batchConfig) (client.BatchRequest, error) {
+	get := func(field string) string { return rec.get(cfg.column(field)) }
+
+	req := cfg.defaults
+	req.ID = get("id")
+	if req.ID == "" {
+		req.ID = strconv.Itoa(i + 1)
+	}
+	req.SearchType = client.SearchType(strings.ToLower(get("searchtype")))
+	if req.SearchType == "" {
+		req.SearchType = client.SearchType(cfg.searchType)
+	}
+	req.Address = get("address")
+	req.Lat = get("lat")
+	req.Lng = get("lng")
+	req.Polygon = get("polygon")
+
+	for _, b := range []struct {
+		field string
+		dst   *bool
+	}{
+		{"loma", &req.LOMA},
+		{"elevation", &req.Elevation},
+		{"property", &req.Property},
+		{"parcel", &req.Parcel},
+	} {
+		v := get(b.field)
+		if v == "" {
+			continue
+		}
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			return req, fmt.Errorf("invalid %s %q", b.field, v)
+		}
+		*b.dst = parsed
+	}
+
+	return req, validateRequest(req)
+}
+
+// validateRequest checks a request has what its search type needs, so one
+// bad row does not fail a whole batch.
+func validateRequest(req client.BatchRequest) error {
+	switch req.SearchType {
+	case client.SearchTypeAddressCoord, client.SearchTypeAddressParcel:
+		if req.Address == "" {
+			return fmt.Errorf("searchtype %s requires an address", req.SearchType)
+		}
+	case client.SearchTypeCoord, client.SearchTypeCoordParcel:
+		if _, err := strconv.ParseFloat(req.Lat, 64); err != nil {
+			return fmt.Errorf("searchtype %s requires a numeric lat, got %q", req.SearchType, req.Lat)
+		}
+		if _, err := strconv.ParseFloat(req.Lng, 64); err != nil {
+			return fmt.Errorf("searchtype %s requires a numeric lng, got %q", req.SearchType, req.Lng)
+		}
+	case client.SearchTypePolygon:
+		if req.Polygon == "" {
+			return fmt.Errorf("searchtype polygon requires a polygon")
+		}
+	default:
+		return fmt.Errorf("invalid searchtype %q", req.SearchType)
+	}
+	return nil
+}
+
+// setResult records a response, or its failure, on row.
+func setResult(row *output, resp *client.Response, err error) {
+	switch {
+	case err != nil:
+		row.err = err.Error()
+	case resp.Status != "OK":
+		row.fields.Status = resp.Status
+		row.err = "status " + resp.Status
+	default:
+		row.fields = flatten(resp)
+	}
+}
+
+// runRemoteBatch submits the valid rows in batches of at most maxBatchSize
+// and waits for each result. A failed batch fails all of its rows.
+func runRemoteBatch(ctx context.Context, svc *nfd.Service, rows []output, requests []client.BatchRequest, poll time.Duration, stderr io.Writer) {
+	var pending []int
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		submitBatch(ctx, svc, rows, requests, pending, poll, stderr)
+		pending = pending[:0]
+	}
+
+	for i := range rows {
+		if rows[i].err != "" {
+			continue
+		}
+		pending = append(pending, i)
+		if len(pending) == maxBatchSize {
+			flush()
+		}
+	}
+	flush()
+}
+
+func submitBatch(ctx context.Context, svc *nfd.Service, rows []output, requests []client.BatchRequest, indexes []int, poll time.Duration, stderr io.Writer) {
+	fail := func(err error) {
+		for _, i := range indexes {
+			rows[i].err = err.Error()
+		}
+	}
+
+	batch := client.BatchDataRequest{Requests: make([]client.BatchRequest, len(indexes))}
+	for j, i := range indexes {
+		batch.Requests[j] = requests[i]
+	}
+
+	submitted, err := svc.GetFloodDataBatch(ctx, batch)
+	if err != nil {
+		fail(err)
+		return
+	}
+	fmt.Fprintf(stderr, "submitted batch %s with %d requests\n", submitted.BatchID, len(indexes))
+
+	results, err := svc.WaitFloodDataBatch(ctx, submitted, poll)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	byID := make(map[string]*client.Response, len(results))
+	for k := range results {
+		byID[results[k].ID] = &results[k].Response
+	}
+	for _, i := range indexes {
+		resp, ok := byID[requests[i].ID]
+		if !ok {
+			rows[i].err = fmt.Sprintf("batch %s has no result for id %q", submitted.BatchID, requests[i].ID)
+			continue
+		}
+		setResult(&rows[i], resp, nil)
+	}
+}
+
+// runLocalBatch looks up the valid rows one by one with GetFloodData from
+// a pool of workers.
+func runLocalBatch(ctx context.Context, svc *nfd.Service, rows []output, requests []client.BatchRequest, workers int) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				opts, err := floodDataOptions(requests[i])
+				if err != nil {
+					rows[i].err = err.Error()
+					continue
+				}
+				resp, err := svc.GetFloodData(ctx, opts)
+				setResult(&rows[i], resp, err)
+			}
+		}()
+	}
+
+	for i := range rows {
+		if rows[i].err != "" {
+			continue
+		}
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// floodDataOptions converts a validated BatchRequest for GetFloodData.
+func floodDataOptions(req client.BatchRequest) (client.FloodDataOptions, error) {
+	opts := client.FloodDataOptions{
+		SearchType: req.SearchType,
+		Address:    req.Address,
+		Polygon:    req.Polygon,
+		LOMA:       req.LOMA,
+		Elevation:  req.Elevation,
+		Property:   req.Property,
+		Parcel:     req.Parcel,
+	}
+
+	var err error
+	if req.Lat != "" {
+		if opts.Lat, err = strconv.ParseFloat(req.Lat, 64); err != nil {
+			return opts, errors.New("invalid lat " + strconv.Quote(req.Lat))
+		}
+	}
+	if req.Lng != "" {
+		if opts.Lng, err = strconv.ParseFloat(req.Lng, 64); err != nil {
+			return opts, errors.New("invalid lng " + strconv.Quote(req.Lng))
+		}
+	}
+	return opts, nil
+}
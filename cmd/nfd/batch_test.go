@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+)
+
+// writeInput writes an input file and returns its path.
+func writeInput(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// readCSVOutput parses CSV output into maps keyed by header.
+func readCSVOutput(t *testing.T, out string) ([]string, []map[string]string) {
+	t.Helper()
+
+	lines, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	require.NoError(t, err)
+	require.NotEmpty(t, lines)
+
+	var rows []map[string]string
+	for _, line := range lines[1:] {
+		row := make(map[string]string)
+		for i, key := range lines[0] {
+			row[key] = line[i]
+		}
+		rows = append(rows, row)
+	}
+	return lines[0], rows
+}
+
+const addressesCSV = `Loan,Street,Notes
+L-1,"430 Australian Ave Palm Beach, FL 33480",first
+L-2,,missing address
+L-3,"1 Main St Mapleville KS 12345",third
+`
+
+func TestBatch_ShouldEnrichCSVFromBatchEndpoint(t *testing.T) {
+	srv := newServer(t, nfdtest.WithBatchPending(2))
+	in := writeInput(t, "addresses.csv", addressesCSV)
+
+	stdout, stderr, code := runNFD(t, "batch", "-in", in, "-columns", "id=Loan,address=Street", "-elevation", "-poll", "1ms")
+	require.Equal(t, 0, code, stderr)
+
+	header, rows := readCSVOutput(t, stdout)
+	assert.Equal(t, append(append([]string{"Loan", "Street", "Notes"}, floodColumns...), errorColumn), header)
+	require.Len(t, rows, 3)
+
+	assert.Equal(t, "L-1", rows[0]["Loan"])
+	assert.Equal(t, "OK", rows[0]["nfd_status"])
+	assert.Equal(t, "AE", rows[0]["nfd_zone"])
+	assert.Equal(t, "true", rows[0]["nfd_sfha"])
+	assert.Equal(t, "6", rows[0]["nfd_bfe"])
+	assert.Equal(t, "12099C0583G", rows[0]["nfd_firm_panel"])
+	assert.Empty(t, rows[0][errorColumn])
+
+	assert.Equal(t, "missing address", rows[1]["Notes"])
+	assert.Empty(t, rows[1]["nfd_zone"])
+	assert.Contains(t, rows[1][errorColumn], "requires an address")
+
+	assert.Equal(t, "AE", rows[2]["nfd_zone"])
+	assert.Contains(t, stderr, "1 of 3 rows failed")
+
+	// Only the valid rows are submitted, in one batch.
+	var batches int
+	for _, req := range srv.Requests() {
+		if req.Endpoint == nfd.EndpointGetFloodDataBatch {
+			batches++
+			var body client.BatchDataRequest
+			require.NoError(t, json.Unmarshal(req.Body, &body))
+			require.Len(t, body.Requests, 2)
+			assert.Equal(t, "L-1", body.Requests[0].ID)
+			assert.Equal(t, client.SearchTypeAddressCoord, body.Requests[0].SearchType)
+			assert.True(t, body.Requests[0].Elevation)
+		}
+	}
+	assert.Equal(t, 1, batches)
+}
+
+func TestBatch_ShouldEnrichJSONLWithLocalWorkers(t *testing.T) {
+	srv := newServer(t)
+	in := writeInput(t, "points.jsonl", `{"name":"a","searchtype":"coord","lat":26.7,"lng":-80.04}
+
+{"name":"b","searchtype":"coord","lat":"north","lng":-80.04}
+{"name":"c","searchtype":"addressparcel","address":"430 Australian Ave Palm Beach, FL 33480","property":true}
+`)
+	out := filepath.Join(t.TempDir(), "out.jsonl")
+
+	_, stderr, code := runNFD(t, "batch", "-in", in, "-out", out, "-mode", "local", "-workers", "2")
+	require.Equal(t, 0, code, stderr)
+
+	raw, err := os.ReadFile(out)
+	require.NoError(t, err)
+
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(string(raw)))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.Len(t, lines, 3)
+
+	// Input keys keep their order and values, followed by the flood columns.
+	assert.True(t, strings.HasPrefix(lines[0], `{"name":"a","searchtype":"coord","lat":26.7,"lng":-80.04,"nfd_status":"OK",`), lines[0])
+
+	var rows []map[string]interface{}
+	for _, line := range lines {
+		var row map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &row))
+		rows = append(rows, row)
+	}
+	assert.Equal(t, "AE", rows[0]["nfd_zone"])
+	assert.Contains(t, rows[1][errorColumn], "numeric lat")
+	assert.Equal(t, "AE", rows[2]["nfd_zone"])
+	assert.Empty(t, rows[2][errorColumn])
+
+	var lookups int
+	for _, req := range srv.Requests() {
+		if req.Endpoint == nfd.EndpointGetFloodData {
+			lookups++
+			if req.URL.Query().Get("searchtype") == "addressparcel" {
+				assert.Equal(t, "true", req.URL.Query().Get("property"))
+			}
+		}
+	}
+	assert.Equal(t, 2, lookups)
+}
+
+func TestBatch_ShouldWriteCSVFromJSONLInput(t *testing.T) {
+	newServer(t)
+	in := writeInput(t, "points.jsonl", `{"id":"x","searchtype":"coord","lat":26.7,"lng":-80.04}
+{"id":"y","searchtype":"coord","lat":26.7,"lng":-80.04,"extra":"z"}
+`)
+
+	stdout, stderr, code := runNFD(t, "batch", "-in", in, "-out-format", "csv", "-poll", "1ms")
+	require.Equal(t, 0, code, stderr)
+
+	header, rows := readCSVOutput(t, stdout)
+	assert.Equal(t, []string{"id", "searchtype", "lat", "lng", "extra"}, header[:5])
+	require.Len(t, rows, 2)
+	assert.Equal(t, "26.7", rows[0]["lat"])
+	assert.Equal(t, "z", rows[1]["extra"])
+	assert.Equal(t, "AE", rows[1]["nfd_zone"])
+}
+
+func TestBatch_ShouldFailEveryRowOfAFailedBatch(t *testing.T) {
+	srv := newServer(t)
+	srv.Inject(nfd.EndpointGetFloodDataBatch, nfdtest.Fault{Status: http.StatusInternalServerError})
+	in := writeInput(t, "addresses.csv", addressesCSV)
+
+	stdout, stderr, code := runNFD(t, "batch", "-in", in, "-columns", "id=Loan,address=Street")
+	require.Equal(t, 0, code, stderr)
+
+	_, rows := readCSVOutput(t, stdout)
+	require.Len(t, rows, 3)
+	assert.Contains(t, rows[0][errorColumn], "Internal Server Error")
+	assert.Contains(t, rows[1][errorColumn], "requires an address")
+	assert.Contains(t, rows[2][errorColumn], "Internal Server Error")
+	assert.Contains(t, stderr, "3 of 3 rows failed")
+}
+
+func TestBatch_ShouldRejectDuplicateIDs(t *testing.T) {
+	newServer(t)
+	in := writeInput(t, "points.csv", "id,searchtype,lat,lng\na,coord,26.7,-80.04\na,coord,26.7,-80.04\n")
+
+	stdout, stderr, code := runNFD(t, "batch", "-in", in, "-poll", "1ms")
+	require.Equal(t, 0, code, stderr)
+
+	_, rows := readCSVOutput(t, stdout)
+	require.Len(t, rows, 2)
+	assert.Empty(t, rows[0][errorColumn])
+	assert.Equal(t, `duplicate id "a"`, rows[1][errorColumn])
+}
+
+func TestBatch_ShouldRejectInvalidFlags(t *testing.T) {
+	newServer(t)
+	in := writeInput(t, "points.csv", "id\n")
+
+	tests := map[string][]string{
+		"missing in":        {"batch"},
+		"bad mode":          {"batch", "-in", in, "-mode", "remote"},
+		"stdin no format":   {"batch", "-in", "-"},
+		"unknown extension": {"batch", "-in", "points.xlsx"},
+		"bad out format":    {"batch", "-in", in, "-out-format", "xml"},
+		"unknown field":     {"batch", "-in", in, "-columns", "zip=Zip"},
+		"malformed columns": {"batch", "-in", in, "-columns", "address"},
+		"no workers":        {"batch", "-in", in, "-workers", "0"},
+	}
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, code := runNFD(t, args...)
+			assert.Equal(t, 2, code)
+		})
+	}
+}
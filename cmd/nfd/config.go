@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+)
+
+// Config is the nfd config file.
+type Config struct {
+	APIKey  string        `yaml:"api_key"`
+	BaseURL string        `yaml:"base_url"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// defaultConfigPath returns nfd/config.yaml in the user config directory,
+// or "" if there is none.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "nfd", "config.yaml")
+}
+
+// loadConfig reads the config file at path. A missing file is only an
+// error when it was named explicitly.
+func loadConfig(path string, explicit bool) (*Config, error) {
+	var cfg Config
+	if path == "" {
+		return &cfg, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return &cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// serviceFlags are the flags every command that calls the API accepts.
+type serviceFlags struct {
+	config  string
+	baseURL string
+	timeout time.Duration
+}
+
+func (f *serviceFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.config, "config", "", "config file (default "+defaultConfigPath()+")")
+	fs.StringVar(&f.baseURL, "base-url", "", "API base URL (default $"+nfd.EnvBaseURL+", the config file, or "+nfd.DefaultBaseURL+")")
+	fs.DurationVar(&f.timeout, "timeout", 0, "timeout for each request (default from the config file)")
+}
+
+// service builds a Service from the flags, the environment and the config
+// file, in that order of precedence.
+func (f *serviceFlags) service() (*nfd.Service, error) {
+	apiKey, opts, err := f.settings()
+	if err != nil {
+		return nil, err
+	}
+	return nfd.NewService(apiKey, opts...)
+}
+
+// settings resolves the API key and Service options.
+func (f *serviceFlags) settings() (string, []nfd.Option, error) {
+	path, explicit := f.config, f.config != ""
+	if !explicit {
+		path = defaultConfigPath()
+	}
+	cfg, err := loadConfig(path, explicit)
+	if err != nil {
+		return "", nil, err
+	}
+
+	apiKey := os.Getenv(nfd.EnvAPIKey)
+	if apiKey == "" {
+		apiKey = cfg.APIKey
+	}
+	if apiKey == "" {
+		return "", nil, fmt.Errorf("no API key: set %s or api_key in %s", nfd.EnvAPIKey, path)
+	}
+
+	var opts []nfd.Option
+	baseURL := firstNonEmpty(f.baseURL, os.Getenv(nfd.EnvBaseURL), cfg.BaseURL)
+	if baseURL != "" {
+		opts = append(opts, nfd.WithBaseURL(baseURL))
+	}
+
+	timeout := f.timeout
+	if timeout == 0 {
+		timeout = cfg.Timeout
+	}
+	if timeout != 0 {
+		opts = append(opts, nfd.WithTimeout(timeout))
+	}
+
+	return apiKey, opts, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
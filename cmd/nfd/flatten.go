@@ -0,0 +1,93 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/export"
+)
+
+// floodFields are the headline values of a response as strings, shared by
+// the summary output and the batch output columns. Missing values are
+// empty.
+type floodFields struct {
+	Status                 string
+	Zone                   string
+	ZoneSubtype            string
+	SFHA                   string
+	BFE                    string
+	BFEUnit                string
+	BFEDatum               string
+	BFEDistKm              string
+	Panel                  string
+	PanelEffective         string
+	Community              string
+	CommunityParticipating string
+}
+
+// floodColumns are the batch output columns, in the order values returns
+// them.
+var floodColumns = []string{
+	"nfd_status",
+	"nfd_zone",
+	"nfd_zone_subtype",
+	"nfd_sfha",
+	"nfd_bfe",
+	"nfd_bfe_unit",
+	"nfd_bfe_datum",
+	"nfd_bfe_distkm",
+	"nfd_firm_panel",
+	"nfd_panel_effective",
+	"nfd_community",
+	"nfd_community_participating",
+}
+
+func (f floodFields) values() []string {
+	return []string{
+		f.Status,
+		f.Zone,
+		f.ZoneSubtype,
+		f.SFHA,
+		f.BFE,
+		f.BFEUnit,
+		f.BFEDatum,
+		f.BFEDistKm,
+		f.Panel,
+		f.PanelEffective,
+		f.Community,
+		f.CommunityParticipating,
+	}
+}
+
+// flatten formats the headline values of resp's export.Row as strings.
+func flatten(resp *client.Response) floodFields {
+	row := export.Flatten(resp)
+	f := floodFields{
+		Status:         row.Status,
+		Zone:           row.FloodZone,
+		ZoneSubtype:    row.ZoneSubtype,
+		BFEUnit:        row.BFEUnit,
+		BFEDatum:       row.BFEDatum,
+		Panel:          row.FIRMPanel,
+		PanelEffective: row.PanelEffectiveDate,
+		Community:      row.CommunityName,
+	}
+	if row.SFHA != nil {
+		f.SFHA = strconv.FormatBool(*row.SFHA)
+	}
+	if row.BFEDistanceKm != nil {
+		f.BFE = formatFloat(row.BFE)
+		f.BFEDistKm = formatFloat(row.BFEDistanceKm)
+	}
+	if row.CommunityParticipating != nil {
+		f.CommunityParticipating = strconv.FormatBool(*row.CommunityParticipating)
+	}
+	return f
+}
+
+func formatFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
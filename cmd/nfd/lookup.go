@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+)
+
+var searchTypes = []client.SearchType{
+	client.SearchTypeAddressCoord,
+	client.SearchTypeAddressParcel,
+	client.SearchTypeCoord,
+	client.SearchTypeCoordParcel,
+	client.SearchTypePolygon,
+}
+
+func runLookup(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("lookup", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: nfd lookup -searchtype TYPE [flags]")
+		fmt.Fprintln(fs.Output())
+		fmt.Fprintln(fs.Output(), "Looks up flood data for one location and prints it.")
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+
+	var sf serviceFlags
+	sf.register(fs)
+
+	var opts client.FloodDataOptions
+	searchType := fs.String("searchtype", "", fmt.Sprintf("search type: one of %v", searchTypes))
+	fs.StringVar(&opts.Address, "address", "", "address, for the address search types")
+	fs.Float64Var(&opts.Lat, "lat", 0, "latitude, for the coord search types")
+	fs.Float64Var(&opts.Lng, "lng", 0, "longitude, for the coord search types")
+	fs.StringVar(&opts.Polygon, "polygon", "", "polygon in well known text, for the polygon search type")
+	fs.BoolVar(&opts.LOMA, "loma", false, "include LOMA updates")
+	fs.BoolVar(&opts.Elevation, "elevation", false, "include elevation, BFE and storm surge data (special key required)")
+	fs.BoolVar(&opts.Property, "property", false, "include property data (special key required)")
+	fs.BoolVar(&opts.Parcel, "parcel", false, "include parcel data (special key required)")
+	format := fs.String("o", formatJSON, fmt.Sprintf("output format: one of %v", outputFormats))
+
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	opts.SearchType = client.SearchType(*searchType)
+	if !validSearchType(opts.SearchType) {
+		fmt.Fprintf(fs.Output(), "invalid -searchtype %q\n", *searchType)
+		fs.Usage()
+		return errUsage
+	}
+	write, ok := outputFormats[*format]
+	if !ok {
+		fmt.Fprintf(fs.Output(), "invalid -o %q\n", *format)
+		fs.Usage()
+		return errUsage
+	}
+
+	svc, err := sf.service()
+	if err != nil {
+		return err
+	}
+
+	resp, err := svc.GetFloodData(ctx, opts)
+	if err != nil {
+		return err
+	}
+	return write(stdout, resp)
+}
+
+func validSearchType(t client.SearchType) bool {
+	for _, valid := range searchTypes {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+)
+
+var lookupArgs = []string{
+	"lookup",
+	"-searchtype", "addressparcel",
+	"-address", "430 Australian Ave Palm Beach, FL 33480",
+	"-elevation",
+}
+
+func TestLookup_ShouldPrintJSON(t *testing.T) {
+	srv := newServer(t)
+
+	stdout, stderr, code := runNFD(t, lookupArgs...)
+	require.Equal(t, 0, code, stderr)
+
+	var resp client.Response
+	require.NoError(t, json.Unmarshal([]byte(stdout), &resp))
+	assert.Equal(t, "OK", resp.Status)
+	assert.Equal(t, "AE", resp.Result.FloodFldHazAr[0].FldZone)
+	assert.NotNil(t, resp.Result.Elevation)
+	assert.Nil(t, resp.Result.Property)
+
+	require.Len(t, srv.Requests(), 1)
+	query := srv.Requests()[0].URL.Query()
+	assert.Equal(t, "addressparcel", query.Get("searchtype"))
+	assert.Equal(t, "true", query.Get("elevation"))
+	assert.Empty(t, query.Get("property"))
+}
+
+func TestLookup_ShouldPrintYAMLWithJSONKeys(t *testing.T) {
+	newServer(t)
+
+	stdout, stderr, code := runNFD(t, append(lookupArgs, "-o", "yaml")...)
+	require.Equal(t, 0, code, stderr)
+
+	var doc map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(stdout), &doc))
+	assert.Equal(t, "OK", doc["status"])
+	assert.Contains(t, doc["result"], "flood.s_fld_haz_ar")
+	assert.Contains(t, stdout, "status: OK\n")
+}
+
+func TestLookup_ShouldPrintSummary(t *testing.T) {
+	newServer(t)
+
+	stdout, stderr, code := runNFD(t, append(lookupArgs, "-o", "summary")...)
+	require.Equal(t, 0, code, stderr)
+
+	assert.Contains(t, stdout, "Location:    430 Australian Ave Palm Beach, FL 33480\n")
+	assert.Contains(t, stdout, "Flood zone:  AE (COASTAL FLOODPLAIN)\n")
+	assert.Contains(t, stdout, "SFHA:        yes\n")
+	assert.Contains(t, stdout, "BFE:         6 Feet (NAVD88)\n")
+	assert.Contains(t, stdout, "FIRM panel:  12099C0583G, effective 2024-12-20\n")
+	assert.Contains(t, stdout, "Community:   PALM BEACH, TOWN OF (participating in the NFIP)\n")
+}
+
+func TestLookup_ShouldSummarizeMissingSectionsAsDash(t *testing.T) {
+	newServer(t)
+
+	stdout, stderr, code := runNFD(t, "lookup", "-searchtype", "coord", "-lat", "26.7", "-lng", "-80.04", "-o", "summary")
+	require.Equal(t, 0, code, stderr)
+
+	assert.Contains(t, stdout, "BFE:         -\n")
+}
+
+func TestLookup_ShouldRejectInvalidFlags(t *testing.T) {
+	newServer(t)
+
+	tests := map[string][]string{
+		"missing searchtype": {"lookup", "-address", "x"},
+		"bad searchtype":     {"lookup", "-searchtype", "zip"},
+		"bad format":         {"lookup", "-searchtype", "coord", "-o", "xml"},
+		"stray argument":     {"lookup", "-searchtype", "coord", "extra"},
+		"unknown flag":       {"lookup", "-nope"},
+	}
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, code := runNFD(t, args...)
+			assert.Equal(t, 2, code)
+		})
+	}
+}
+
+func TestLookup_ShouldReportAPIErrors(t *testing.T) {
+	srv := newServer(t)
+	srv.Inject(nfd.EndpointGetFloodData, nfdtest.Fault{Status: http.StatusNotFound})
+
+	stdout, stderr, code := runNFD(t, lookupArgs...)
+
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "nfd lookup:")
+}
@@ -0,0 +1,134 @@
+// Command nfd queries the National Flood Data API from the command line.
+//
+// Usage:
+//
+//	nfd <command> [flags]
+//
+// The API key is read from NFD_API_KEY or, failing that, from the api_key
+// of the config file (by default nfd/config.yaml in the user config
+// directory). Run "nfd <command> -h" for a command's flags.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+)
+
+// command is an nfd subcommand.
+type command struct {
+	summary string
+	run     func(ctx context.Context, args []string, stdout, stderr io.Writer) error
+}
+
+var commands = map[string]command{
+	"batch":  {summary: "look up flood data for every row of a CSV or JSONL file", run: runBatch},
+	"lookup": {summary: "look up flood data for an address, point or polygon", run: runLookup},
+	"map":    {summary: "save a static or dynamic flood map", run: runMap},
+	"tiles":  {summary: "download flood vector or storm surge tiles", run: runTiles},
+}
+
+// errUsage reports invalid arguments; the flag package has already
+// printed the details.
+var errUsage = errors.New("usage error")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command named by args[0] and returns the exit code.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		usage(stderr)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "nfd: unknown command %q\n\n", args[0])
+		usage(stderr)
+		return 2
+	}
+
+	err := cmd.run(ctx, args[1:], stdout, stderr)
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	default:
+		fmt.Fprintf(stderr, "nfd %s: %v\n", args[0], err)
+		return 1
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: nfd <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	listCommands(w, commands)
+}
+
+func listCommands(w io.Writer, commands map[string]command) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].summary)
+	}
+}
+
+// runSubcommand runs the subcommand of parent named by args[0].
+func runSubcommand(ctx context.Context, parent string, subcommands map[string]command, args []string, stdout, stderr io.Writer) error {
+	usage := func() {
+		fmt.Fprintf(stderr, "Usage: nfd %s <command> [flags]\n\n", parent)
+		fmt.Fprintln(stderr, "Commands:")
+		listCommands(stderr, subcommands)
+	}
+
+	if len(args) == 0 {
+		usage()
+		return errUsage
+	}
+	if args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		usage()
+		return flag.ErrHelp
+	}
+
+	cmd, ok := subcommands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", parent+" "+args[0])
+		usage()
+		return errUsage
+	}
+	return cmd.run(ctx, args[1:], stdout, stderr)
+}
+
+// parseFlags parses args into fs, mapping parse failures to errUsage.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(fs.Output(), "unexpected arguments: %v\n", fs.Args())
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
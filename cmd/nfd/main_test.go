@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+)
+
+// newServer starts a fake API and points the environment at it, so
+// commands find both the key and the base URL without a config file.
+func newServer(t *testing.T, opts ...nfdtest.Option) *nfdtest.Server {
+	t.Helper()
+
+	srv := nfdtest.NewServer(opts...)
+	t.Cleanup(srv.Close)
+
+	t.Setenv(nfd.EnvAPIKey, nfdtest.DefaultAPIKey)
+	t.Setenv(nfd.EnvBaseURL, srv.BaseURL())
+	isolateConfig(t)
+
+	return srv
+}
+
+// isolateConfig stops tests reading the user's own config file.
+func isolateConfig(t *testing.T) {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+}
+
+// writeConfig writes a config file and returns its path.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// runNFD runs the CLI and returns its output and exit code.
+func runNFD(t *testing.T, args ...string) (string, string, int) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestRun_ShouldPrintUsageWithoutCommand(t *testing.T) {
+	_, stderr, code := runNFD(t)
+
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "Usage: nfd <command>")
+	assert.Contains(t, stderr, "lookup")
+}
+
+func TestRun_ShouldRejectUnknownCommand(t *testing.T) {
+	_, stderr, code := runNFD(t, "frobnicate")
+
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown command "frobnicate"`)
+}
+
+func TestRun_ShouldExitZeroForHelp(t *testing.T) {
+	_, stderr, code := runNFD(t, "lookup", "-h")
+
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stderr, "-searchtype")
+}
+
+func TestService_ShouldReadKeyAndBaseURLFromConfig(t *testing.T) {
+	srv := newServer(t)
+	t.Setenv(nfd.EnvAPIKey, "")
+	t.Setenv(nfd.EnvBaseURL, "")
+	path := writeConfig(t, "api_key: "+nfdtest.DefaultAPIKey+"\nbase_url: "+srv.BaseURL()+"\ntimeout: 5s\n")
+
+	_, stderr, code := runNFD(t, "lookup", "-config", path, "-searchtype", "coord", "-lat", "26.7", "-lng", "-80.04")
+
+	assert.Equal(t, 0, code, stderr)
+	require.Len(t, srv.Requests(), 1)
+	assert.Equal(t, nfdtest.DefaultAPIKey, srv.Requests()[0].APIKey)
+}
+
+func TestService_ShouldPreferEnvironmentKeyOverConfig(t *testing.T) {
+	srv := newServer(t)
+	path := writeConfig(t, "api_key: stale-key\n")
+
+	_, stderr, code := runNFD(t, "lookup", "-config", path, "-searchtype", "coord", "-lat", "26.7", "-lng", "-80.04")
+
+	assert.Equal(t, 0, code, stderr)
+	require.Len(t, srv.Requests(), 1)
+	assert.Equal(t, nfdtest.DefaultAPIKey, srv.Requests()[0].APIKey)
+}
+
+func TestService_ShouldFailWithoutKey(t *testing.T) {
+	newServer(t)
+	t.Setenv(nfd.EnvAPIKey, "")
+
+	_, stderr, code := runNFD(t, "lookup", "-searchtype", "coord", "-lat", "26.7", "-lng", "-80.04")
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "no API key")
+}
+
+func TestService_ShouldFailForMissingExplicitConfig(t *testing.T) {
+	newServer(t)
+
+	_, stderr, code := runNFD(t, "lookup", "-config", filepath.Join(t.TempDir(), "missing.yaml"), "-searchtype", "coord")
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "reading config")
+}
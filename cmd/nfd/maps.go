@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+	"github.com/kmesiab/go-nationalflooddata/client"
+)
+
+var mapCommands = map[string]command{
+	"static":  {summary: "save a static flood map image", run: runStaticMap},
+	"dynamic": {summary: "save a dynamic flood map HTML page", run: runDynamicMap},
+}
+
+func runMap(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	return runSubcommand(ctx, "map", mapCommands, args, stdout, stderr)
+}
+
+// mapFlags are the flags shared by the map commands.
+type mapFlags struct {
+	lat, lng float64
+	zoom     int
+	legend   bool
+	out      string
+}
+
+func (m *mapFlags) register(fs *flag.FlagSet, zoom int) {
+	fs.Float64Var(&m.lat, "lat", 0, "latitude of the map center (required)")
+	fs.Float64Var(&m.lng, "lng", 0, "longitude of the map center (required)")
+	fs.IntVar(&m.zoom, "zoom", zoom, "zoom level")
+	fs.BoolVar(&m.legend, "legend", true, "show the flood zone legend")
+	fs.StringVar(&m.out, "out", "", `output file, or "-" for stdout (required)`)
+}
+
+// validate checks the required flags were set and zoom is within
+// [minZoom, maxZoom].
+func (m *mapFlags) validate(fs *flag.FlagSet, minZoom, maxZoom int) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	var problem string
+	switch {
+	case !set["lat"] || !set["lng"]:
+		problem = "-lat and -lng are required"
+	case m.out == "":
+		problem = "-out is required"
+	case m.zoom < minZoom || m.zoom > maxZoom:
+		problem = fmt.Sprintf("-zoom must be between %d and %d", minZoom, maxZoom)
+	default:
+		return nil
+	}
+
+	fmt.Fprintln(fs.Output(), problem)
+	fs.Usage()
+	return errUsage
+}
+
+func newMapFlagSet(name, usage string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: nfd map %s -lat LAT -lng LNG -out FILE [flags]\n\n", name)
+		fmt.Fprintln(fs.Output(), usage)
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func runStaticMap(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newMapFlagSet("static", "Saves a PNG flood map centered on a point.", stderr)
+
+	var sf serviceFlags
+	sf.register(fs)
+
+	var m mapFlags
+	m.register(fs, 15)
+	width := fs.Int("width", 600, "image width in pixels")
+	height := fs.Int("height", 400, "image height in pixels")
+	marker := fs.Bool("marker", true, "show a marker at the center")
+
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := m.validate(fs, 14, 16); err != nil {
+		return err
+	}
+	if *width <= 0 || *height <= 0 {
+		fmt.Fprintln(fs.Output(), "-width and -height must be positive")
+		fs.Usage()
+		return errUsage
+	}
+
+	svc, err := sf.service()
+	if err != nil {
+		return err
+	}
+
+	image, err := svc.GetStaticFloodMap(ctx, client.StaticMapOptions{
+		Lat:        m.lat,
+		Lng:        m.lng,
+		Height:     *height,
+		Width:      *width,
+		ShowMarker: *marker,
+		ShowLegend: m.legend,
+		Zoom:       m.zoom,
+	})
+	if err != nil {
+		return err
+	}
+	return writeFile(stdout, m.out, image)
+}
+
+func runDynamicMap(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newMapFlagSet("dynamic", "Saves an interactive HTML flood map centered on a point.", stderr)
+
+	var sf serviceFlags
+	sf.register(fs)
+
+	var m mapFlags
+	m.register(fs, 15)
+	key := fs.String("key", "", "key embedded in the page (default the API key)")
+
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := m.validate(fs, 6, 17); err != nil {
+		return err
+	}
+
+	apiKey, opts, err := sf.settings()
+	if err != nil {
+		return err
+	}
+	svc, err := nfd.NewService(apiKey, opts...)
+	if err != nil {
+		return err
+	}
+	if *key == "" {
+		*key = apiKey
+	}
+
+	page, err := svc.GetDynamicFloodMap(ctx, *key, m.lat, m.lng, m.zoom, m.legend)
+	if err != nil {
+		return err
+	}
+	return writeFile(stdout, m.out, []byte(page))
+}
+
+// writeFile writes data to path, or to stdout if path is "-".
+func writeFile(stdout io.Writer, path string, data []byte) error {
+	if path == "-" {
+		_, err := stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+func TestMapStatic_ShouldWriteImage(t *testing.T) {
+	srv := newServer(t)
+	out := filepath.Join(t.TempDir(), "map.png")
+
+	_, stderr, code := runNFD(t, "map", "static", "-lat", "26.7", "-lng", "-80.04", "-width", "800", "-marker=false", "-out", out)
+	require.Equal(t, 0, code, stderr)
+
+	image, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(image, pngSignature))
+
+	require.Len(t, srv.Requests(), 1)
+	query := srv.Requests()[0].URL.Query()
+	assert.Equal(t, "800", query.Get("width"))
+	assert.Equal(t, "400", query.Get("height"))
+	assert.Equal(t, "15", query.Get("zoom"))
+	assert.Equal(t, "false", query.Get("showMarker"))
+	assert.Equal(t, "true", query.Get("showLegend"))
+}
+
+func TestMapStatic_ShouldWriteToStdout(t *testing.T) {
+	newServer(t)
+
+	stdout, stderr, code := runNFD(t, "map", "static", "-lat", "26.7", "-lng", "-80.04", "-out", "-")
+	require.Equal(t, 0, code, stderr)
+	assert.True(t, bytes.HasPrefix([]byte(stdout), pngSignature))
+}
+
+func TestMapDynamic_ShouldEmbedAPIKeyByDefault(t *testing.T) {
+	srv := newServer(t)
+	out := filepath.Join(t.TempDir(), "map.html")
+
+	_, stderr, code := runNFD(t, "map", "dynamic", "-lat", "26.7", "-lng", "-80.04", "-zoom", "12", "-legend=false", "-out", out)
+	require.Equal(t, 0, code, stderr)
+
+	page, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "<!DOCTYPE html>")
+
+	require.Len(t, srv.Requests(), 1)
+	query := srv.Requests()[0].URL.Query()
+	assert.Equal(t, nfdtest.DefaultAPIKey, query.Get("key"))
+	assert.Equal(t, "12", query.Get("zoom"))
+	assert.Equal(t, "false", query.Get("showLegend"))
+}
+
+func TestMapDynamic_ShouldUseKeyFlag(t *testing.T) {
+	srv := newServer(t, nfdtest.WithAPIKey(""))
+
+	_, stderr, code := runNFD(t, "map", "dynamic", "-lat", "26.7", "-lng", "-80.04", "-key", "embed-key", "-out", "-")
+	require.Equal(t, 0, code, stderr)
+
+	require.Len(t, srv.Requests(), 1)
+	assert.Equal(t, "embed-key", srv.Requests()[0].URL.Query().Get("key"))
+}
+
+func TestMap_ShouldReportAPIErrors(t *testing.T) {
+	srv := newServer(t)
+	srv.Inject(nfd.EndpointGetStaticFloodMap, nfdtest.Fault{Status: 402})
+	out := filepath.Join(t.TempDir(), "map.png")
+
+	_, stderr, code := runNFD(t, "map", "static", "-lat", "26.7", "-lng", "-80.04", "-out", out)
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "nfd map:")
+	assert.NoFileExists(t, out)
+}
+
+func TestMap_ShouldRejectInvalidFlags(t *testing.T) {
+	newServer(t)
+
+	tests := map[string][]string{
+		"no subcommand":       {"map"},
+		"unknown subcommand":  {"map", "satellite"},
+		"missing lat":         {"map", "static", "-lng", "-80", "-out", "-"},
+		"missing out":         {"map", "static", "-lat", "26", "-lng", "-80"},
+		"static zoom too low": {"map", "static", "-lat", "26", "-lng", "-80", "-zoom", "13", "-out", "-"},
+		"dynamic zoom high":   {"map", "dynamic", "-lat", "26", "-lng", "-80", "-zoom", "18", "-out", "-"},
+		"zero width":          {"map", "static", "-lat", "26", "-lng", "-80", "-width", "0", "-out", "-"},
+	}
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, code := runNFD(t, args...)
+			assert.Equal(t, 2, code)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+)
+
+// Output formats accepted by -o.
+const (
+	formatJSON    = "json"
+	formatYAML    = "yaml"
+	formatSummary = "summary"
+)
+
+// formats maps an output format to the function that writes a response
+// in it.
+type formats map[string]func(w io.Writer, resp *client.Response) error
+
+func (f formats) String() string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+var outputFormats = formats{
+	formatJSON:    writeJSON,
+	formatYAML:    writeYAML,
+	formatSummary: writeSummary,
+}
+
+func writeJSON(w io.Writer, resp *client.Response) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(resp)
+}
+
+// writeYAML writes the response with the same keys, in the same order,
+// as the JSON output. The models have no yaml tags, so the JSON is
+// re-read as YAML, of which it is a subset.
+func writeYAML(w io.Writer, resp *client.Response) error {
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(raw, &node); err != nil {
+		return err
+	}
+	clearStyle(&node)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// clearStyle drops the flow style and quoting the JSON input gave the
+// nodes, so they are written as block YAML.
+func clearStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, child := range n.Content {
+		clearStyle(child)
+	}
+}
+
+// writeSummary writes the fields an analyst usually wants at a glance.
+func writeSummary(w io.Writer, resp *client.Response) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, row := range summarize(resp) {
+		fmt.Fprintf(tw, "%s:\t%s\n", row[0], row[1])
+	}
+	return tw.Flush()
+}
+
+// summarize returns the summary as label, value pairs. Missing values are
+// shown as "-".
+func summarize(resp *client.Response) [][2]string {
+	f := flatten(resp)
+
+	location := resp.Request.Address
+	if location == "" && resp.Coords.Lat != "" {
+		location = resp.Coords.Lat + ", " + resp.Coords.Lng
+	}
+
+	zone := f.Zone
+	if zone != "" && f.ZoneSubtype != "" {
+		zone += " (" + f.ZoneSubtype + ")"
+	}
+
+	sfha := f.SFHA
+	if sfha != "" {
+		sfha = yesNo(sfha == "true")
+	}
+
+	bfe := ""
+	if f.BFE != "" {
+		bfe = fmt.Sprintf("%s %s (%s)", f.BFE, f.BFEUnit, f.BFEDatum)
+		if f.BFEDistKm != "" && f.BFEDistKm != "0" {
+			bfe += ", " + f.BFEDistKm + " km away"
+		}
+	}
+
+	panel := f.Panel
+	if panel != "" && f.PanelEffective != "" {
+		panel += ", effective " + f.PanelEffective
+	}
+
+	community := f.Community
+	if community != "" {
+		participation := "not participating in the NFIP"
+		if f.CommunityParticipating == "true" {
+			participation = "participating in the NFIP"
+		}
+		community += " (" + participation + ")"
+	}
+
+	return [][2]string{
+		{"Status", orDash(f.Status)},
+		{"Location", orDash(location)},
+		{"Flood zone", orDash(zone)},
+		{"SFHA", orDash(sfha)},
+		{"BFE", orDash(bfe)},
+		{"FIRM panel", orDash(panel)},
+		{"Community", orDash(community)},
+	}
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
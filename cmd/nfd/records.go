@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Record formats accepted by nfd batch.
+const (
+	formatCSV   = "csv"
+	formatJSONL = "jsonl"
+)
+
+// record is one input row. keys keeps the input's column order; values
+// holds strings for CSV input and raw JSON for JSONL input.
+type record struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// get returns the value of column key as a string, or "" if it is absent
+// or null.
+func (r record) get(key string) string {
+	switch v := r.values[key].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case json.RawMessage:
+		var s string
+		if err := json.Unmarshal(v, &s); err == nil {
+			return strings.TrimSpace(s)
+		}
+		if string(v) == "null" {
+			return ""
+		}
+		return string(v)
+	default:
+		return ""
+	}
+}
+
+// recordFormat infers a record format from a file name.
+func recordFormat(name string) (string, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return formatCSV, nil
+	case ".jsonl", ".ndjson", ".json":
+		return formatJSONL, nil
+	default:
+		return "", fmt.Errorf("cannot infer the format of %q; pass -in-format or -out-format", name)
+	}
+}
+
+func readRecords(r io.Reader, format string) ([]record, error) {
+	switch format {
+	case formatCSV:
+		return readCSV(r)
+	case formatJSONL:
+		return readJSONL(r)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func readCSV(r io.Reader) ([]record, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+	// Spreadsheet exports often start with a byte order mark.
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	var records []record
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading CSV: %w", err)
+		}
+
+		rec := record{keys: header, values: make(map[string]interface{}, len(header))}
+		for i, key := range header {
+			if i < len(row) {
+				rec.values[key] = row[i]
+			}
+		}
+		records = append(records, rec)
+	}
+}
+
+func readJSONL(r io.Reader) ([]record, error) {
+	var records []record
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		rec, err := decodeObject(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading JSONL: %w", err)
+	}
+	return records, nil
+}
+
+// decodeObject decodes a JSON object, keeping its key order.
+func decodeObject(raw []byte) (record, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return record{}, fmt.Errorf("expected a JSON object")
+	}
+
+	rec := record{values: make(map[string]interface{})}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return record{}, err
+		}
+		key := tok.(string)
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return record{}, err
+		}
+		if _, dup := rec.values[key]; !dup {
+			rec.keys = append(rec.keys, key)
+		}
+		rec.values[key] = value
+	}
+	if _, err := dec.Token(); err != nil {
+		return record{}, err
+	}
+	return rec, nil
+}
+
+// output is an input record enriched with flood fields or an error.
+type output struct {
+	record
+	fields floodFields
+	err    string
+}
+
+// errorColumn holds the per-row error, empty on success.
+const errorColumn = "nfd_error"
+
+func writeRecords(w io.Writer, format string, rows []output) error {
+	switch format {
+	case formatCSV:
+		return writeCSV(w, rows)
+	case formatJSONL:
+		return writeJSONL(w, rows)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func writeCSV(w io.Writer, rows []output) error {
+	// Rows from JSONL input may have different keys; the header is their
+	// union, in order of first appearance.
+	var keys []string
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		for _, key := range row.keys {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	header := append(append(append([]string{}, keys...), floodColumns...), errorColumn)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		line := make([]string, 0, len(header))
+		for _, key := range keys {
+			line = append(line, row.get(key))
+		}
+		line = append(line, row.fields.values()...)
+		line = append(line, row.err)
+		if err := cw.Write(line); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeJSONL(w io.Writer, rows []output) error {
+	bw := bufio.NewWriter(w)
+
+	for _, row := range rows {
+		var buf bytes.Buffer
+		buf.WriteByte('{')
+
+		write := func(key string, value interface{}) error {
+			if buf.Len() > 1 {
+				buf.WriteByte(',')
+			}
+			k, _ := json.Marshal(key)
+			v, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("encoding %s: %w", key, err)
+			}
+			buf.Write(k)
+			buf.WriteByte(':')
+			buf.Write(v)
+			return nil
+		}
+
+		for _, key := range row.keys {
+			if err := write(key, row.values[key]); err != nil {
+				return err
+			}
+		}
+		for i, value := range row.fields.values() {
+			if err := write(floodColumns[i], value); err != nil {
+				return err
+			}
+		}
+		if err := write(errorColumn, row.err); err != nil {
+			return err
+		}
+
+		buf.WriteString("}\n")
+		if _, err := bw.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCSV_ShouldStripByteOrderMark(t *testing.T) {
+	records, err := readRecords(strings.NewReader("\ufeffaddress,zip\n1 Main St,12345\n"), formatCSV)
+	require.NoError(t, err)
+
+	require.Len(t, records, 1)
+	assert.Equal(t, []string{"address", "zip"}, records[0].keys)
+	assert.Equal(t, "1 Main St", records[0].get("address"))
+}
+
+func TestReadCSV_ShouldAllowShortRows(t *testing.T) {
+	records, err := readRecords(strings.NewReader("a,b,c\n1\n"), formatCSV)
+	require.NoError(t, err)
+
+	require.Len(t, records, 1)
+	assert.Equal(t, "1", records[0].get("a"))
+	assert.Empty(t, records[0].get("c"))
+}
+
+func TestReadJSONL_ShouldKeepKeyOrderAndRawValues(t *testing.T) {
+	records, err := readRecords(strings.NewReader(`{"z":1.50,"a":" x ","m":null,"b":true}`+"\n"), formatJSONL)
+	require.NoError(t, err)
+
+	require.Len(t, records, 1)
+	rec := records[0]
+	assert.Equal(t, []string{"z", "a", "m", "b"}, rec.keys)
+	assert.Equal(t, "1.50", rec.get("z"))
+	assert.Equal(t, "x", rec.get("a"))
+	assert.Empty(t, rec.get("m"))
+	assert.Equal(t, "true", rec.get("b"))
+	assert.Empty(t, rec.get("missing"))
+}
+
+func TestReadJSONL_ShouldReportLineOfInvalidObject(t *testing.T) {
+	_, err := readRecords(strings.NewReader("{\"a\":1}\n[1,2]\n"), formatJSONL)
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestRecordFormat_ShouldInferFromExtension(t *testing.T) {
+	for name, want := range map[string]string{
+		"in.csv":    formatCSV,
+		"IN.CSV":    formatCSV,
+		"in.jsonl":  formatJSONL,
+		"in.ndjson": formatJSONL,
+	} {
+		got, err := recordFormat(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, got, name)
+	}
+
+	_, err := recordFormat("in.xlsx")
+	assert.Error(t, err)
+}
+
+func TestWriteJSONL_ShouldRoundTripRecords(t *testing.T) {
+	records, err := readRecords(strings.NewReader(`{"b":[1,2],"a":{"c":"d"}}`+"\n"), formatJSONL)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, writeRecords(&buf, formatJSONL, []output{{record: records[0], err: "boom"}}))
+
+	assert.True(t, strings.HasPrefix(buf.String(), `{"b":[1,2],"a":{"c":"d"},"nfd_status":""`), buf.String())
+	assert.True(t, strings.HasSuffix(buf.String(), `"nfd_error":"boom"}`+"\n"), buf.String())
+}
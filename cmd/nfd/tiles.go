@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+)
+
+// Tile layers accepted by -layer.
+const (
+	layerFlood      = "flood"
+	layerStormSurge = "stormsurge"
+)
+
+// maxLat is the latitude limit of the Web Mercator tile grid.
+const maxLat = 85.05112878
+
+var tileCommands = map[string]command{
+	"fetch": {summary: "download tiles by coordinate or bounding box", run: runTilesFetch},
+}
+
+func runTiles(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	return runSubcommand(ctx, "tiles", tileCommands, args, stdout, stderr)
+}
+
+// tile is a slippy map tile address.
+type tile struct{ z, x, y int }
+
+func (t tile) String() string { return fmt.Sprintf("%d/%d/%d", t.z, t.x, t.y) }
+
+// parseTile parses "z/x/y".
+func parseTile(s string) (tile, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 3 {
+		return tile{}, fmt.Errorf("%q is not z/x/y", s)
+	}
+
+	var n [3]int
+	for i, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 0 {
+			return tile{}, fmt.Errorf("%q is not z/x/y", s)
+		}
+		n[i] = v
+	}
+
+	t := tile{z: n[0], x: n[1], y: n[2]}
+	if t.x >= 1<<t.z || t.y >= 1<<t.z {
+		return tile{}, fmt.Errorf("tile %s is outside the grid at zoom %d", t, t.z)
+	}
+	return t, nil
+}
+
+// bbox is a bounding box in degrees.
+type bbox struct{ minLng, minLat, maxLng, maxLat float64 }
+
+// parseBBox parses "minLng,minLat,maxLng,maxLat".
+func parseBBox(s string) (bbox, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return bbox{}, fmt.Errorf("%q is not minLng,minLat,maxLng,maxLat", s)
+	}
+
+	var v [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return bbox{}, fmt.Errorf("%q is not minLng,minLat,maxLng,maxLat", s)
+		}
+		v[i] = f
+	}
+
+	b := bbox{minLng: v[0], minLat: v[1], maxLng: v[2], maxLat: v[3]}
+	if b.minLng > b.maxLng || b.minLat > b.maxLat {
+		return bbox{}, fmt.Errorf("bbox %q has min greater than max", s)
+	}
+	if b.minLng < -180 || b.maxLng > 180 || b.minLat < -90 || b.maxLat > 90 {
+		return bbox{}, fmt.Errorf("bbox %q is outside -180,-90,180,90", s)
+	}
+	return b, nil
+}
+
+// parseZoomRange parses "z" or "min-max".
+func parseZoomRange(s string) (int, int, error) {
+	lo, hi, isRange := strings.Cut(s, "-")
+	if !isRange {
+		hi = lo
+	}
+
+	minZoom, err1 := strconv.Atoi(lo)
+	maxZoom, err2 := strconv.Atoi(hi)
+	if err1 != nil || err2 != nil || minZoom < 0 || maxZoom < minZoom || maxZoom > 22 {
+		return 0, 0, fmt.Errorf("%q is not a zoom level or range such as 12-14", s)
+	}
+	return minZoom, maxZoom, nil
+}
+
+// tileAt returns the tile containing lng, lat at zoom z.
+func tileAt(lng, lat float64, z int) tile {
+	lat = math.Max(-maxLat, math.Min(maxLat, lat))
+	n := float64(int(1) << z)
+
+	x := int(math.Floor((lng + 180) / 360 * n))
+	rad := lat * math.Pi / 180
+	y := int(math.Floor((1 - math.Log(math.Tan(rad)+1/math.Cos(rad))/math.Pi) / 2 * n))
+
+	last := int(n) - 1
+	return tile{z: z, x: min(max(x, 0), last), y: min(max(y, 0), last)}
+}
+
+// tilesIn returns the tiles covering b at every zoom in [minZoom, maxZoom],
+// failing once there are more than limit.
+func tilesIn(b bbox, minZoom, maxZoom, limit int) ([]tile, error) {
+	var tiles []tile
+	for z := minZoom; z <= maxZoom; z++ {
+		nw := tileAt(b.minLng, b.maxLat, z)
+		se := tileAt(b.maxLng, b.minLat, z)
+
+		count := (se.x - nw.x + 1) * (se.y - nw.y + 1)
+		if len(tiles)+count > limit {
+			return nil, fmt.Errorf("the bbox covers more than %d tiles by zoom %d; raise -max-tiles or narrow the range", limit, z)
+		}
+		for x := nw.x; x <= se.x; x++ {
+			for y := nw.y; y <= se.y; y++ {
+				tiles = append(tiles, tile{z: z, x: x, y: y})
+			}
+		}
+	}
+	return tiles, nil
+}
+
+// tileList collects repeated -tile flags.
+type tileList []tile
+
+func (l *tileList) String() string {
+	parts := make([]string, len(*l))
+	for i, t := range *l {
+		parts[i] = t.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+func (l *tileList) Set(s string) error {
+	t, err := parseTile(s)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, t)
+	return nil
+}
+
+func runTilesFetch(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("fetch", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: nfd tiles fetch -dir DIR (-tile Z/X/Y ... | -bbox BBOX -zoom Z[-Z]) [flags]")
+		fmt.Fprintln(fs.Output())
+		fmt.Fprintln(fs.Output(), "Downloads tiles to DIR/Z/X/Y.mvt (flood) or DIR/Z/X/Y.png (stormsurge).")
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+
+	var sf serviceFlags
+	sf.register(fs)
+
+	var explicit tileList
+	layer := fs.String("layer", layerFlood, "tile layer: flood (vector) or stormsurge (PNG)")
+	category := fs.String("category", "", "storm surge hurricane category, 1 to 5 (required for stormsurge)")
+	fs.Var(&explicit, "tile", "tile to fetch as z/x/y; may be repeated")
+	bboxFlag := fs.String("bbox", "", "fetch the tiles covering minLng,minLat,maxLng,maxLat")
+	zoom := fs.String("zoom", "", "zoom level or range, such as 14 or 12-14, for -bbox")
+	dir := fs.String("dir", "", "output directory (required)")
+	workers := fs.Int("workers", 4, "concurrent downloads")
+	maxTiles := fs.Int("max-tiles", 1000, "refuse a -bbox covering more tiles than this")
+	skipExisting := fs.Bool("skip-existing", false, "do not download tiles whose file already exists")
+
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	usageErr := func(format string, a ...interface{}) error {
+		fmt.Fprintf(fs.Output(), format+"\n", a...)
+		fs.Usage()
+		return errUsage
+	}
+	if *dir == "" {
+		return usageErr("-dir is required")
+	}
+	if *workers < 1 {
+		return usageErr("-workers must be at least 1")
+	}
+
+	var fetch func(ctx context.Context, svc *nfd.Service, t tile) ([]byte, error)
+	var ext string
+	switch *layer {
+	case layerFlood:
+		if *category != "" {
+			return usageErr("-category only applies to -layer stormsurge")
+		}
+		ext = ".mvt"
+		fetch = func(ctx context.Context, svc *nfd.Service, t tile) ([]byte, error) {
+			return svc.GetFloodVectorTile(ctx, t.z, t.x, t.y)
+		}
+	case layerStormSurge:
+		n, err := strconv.Atoi(strings.TrimPrefix(*category, "category"))
+		if err != nil || n < 1 || n > 5 {
+			return usageErr("-category must be 1 to 5 for -layer stormsurge")
+		}
+		cat := "category" + strconv.Itoa(n)
+		ext = ".png"
+		fetch = func(ctx context.Context, svc *nfd.Service, t tile) ([]byte, error) {
+			return svc.GetStormSurgeTile(ctx, cat, t.z, t.x, t.y)
+		}
+	default:
+		return usageErr("invalid -layer %q", *layer)
+	}
+
+	tiles := []tile(explicit)
+	switch {
+	case *bboxFlag != "" && *zoom == "":
+		return usageErr("-bbox needs -zoom")
+	case *bboxFlag == "" && *zoom != "":
+		return usageErr("-zoom only applies to -bbox")
+	case *bboxFlag != "":
+		b, err := parseBBox(*bboxFlag)
+		if err != nil {
+			return usageErr("invalid -bbox: %v", err)
+		}
+		minZoom, maxZoom, err := parseZoomRange(*zoom)
+		if err != nil {
+			return usageErr("invalid -zoom: %v", err)
+		}
+		covering, err := tilesIn(b, minZoom, maxZoom, *maxTiles)
+		if err != nil {
+			return err
+		}
+		tiles = append(tiles, covering...)
+	}
+	if len(tiles) == 0 {
+		return usageErr("pass -tile or -bbox")
+	}
+
+	svc, err := sf.service()
+	if err != nil {
+		return err
+	}
+
+	var (
+		mu                       sync.Mutex
+		fetched, skipped, failed int
+		wg                       sync.WaitGroup
+	)
+	jobs := make(chan tile)
+	for w := 0; w < *workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range jobs {
+				path := filepath.Join(*dir, strconv.Itoa(t.z), strconv.Itoa(t.x), strconv.Itoa(t.y)+ext)
+				done, err := fetchTile(ctx, svc, fetch, t, path, *skipExisting)
+
+				mu.Lock()
+				switch {
+				case err != nil:
+					failed++
+					fmt.Fprintf(stderr, "tile %s: %v\n", t, err)
+				case done:
+					fetched++
+				default:
+					skipped++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, t := range tiles {
+		select {
+		case jobs <- t:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stderr, "fetched %d tiles, skipped %d, %d failed\n", fetched, skipped, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tiles failed", failed, len(tiles))
+	}
+	return nil
+}
+
+// fetchTile downloads t to path. It reports false if the file already
+// existed and skipExisting is set.
+func fetchTile(ctx context.Context, svc *nfd.Service, fetch func(context.Context, *nfd.Service, tile) ([]byte, error), t tile, path string, skipExisting bool) (bool, error) {
+	if skipExisting {
+		if _, err := os.Stat(path); err == nil {
+			return false, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return false, err
+		}
+	}
+
+	data, err := fetch(ctx, svc, t)
+	if err != nil {
+		return false, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+)
+
+func TestTileAt_ShouldMatchSlippyMapTiles(t *testing.T) {
+	assert.Equal(t, tile{z: 14, x: 4549, y: 6930}, tileAt(-80.0424, 26.7032, 14))
+	assert.Equal(t, tile{z: 1, x: 1, y: 1}, tileAt(0, 0, 1))
+
+	// The edges of the world clamp into the grid.
+	assert.Equal(t, tile{z: 2, x: 3, y: 0}, tileAt(180, 89, 2))
+	assert.Equal(t, tile{z: 2, x: 0, y: 3}, tileAt(-180, -89, 2))
+}
+
+func TestTilesIn_ShouldCoverBBoxAtEveryZoom(t *testing.T) {
+	b := bbox{minLng: -80.05, minLat: 26.70, maxLng: -80.03, maxLat: 26.71}
+
+	tiles, err := tilesIn(b, 14, 15, 100)
+	require.NoError(t, err)
+
+	assert.Len(t, tiles, 4+6)
+	assert.Equal(t, tile{z: 14, x: 4548, y: 6929}, tiles[0])
+	assert.Equal(t, tile{z: 15, x: 9099, y: 13860}, tiles[len(tiles)-1])
+
+	_, err = tilesIn(b, 14, 15, 9)
+	assert.ErrorContains(t, err, "more than 9 tiles by zoom 15")
+}
+
+func TestParseZoomRange(t *testing.T) {
+	lo, hi, err := parseZoomRange("12-14")
+	require.NoError(t, err)
+	assert.Equal(t, []int{12, 14}, []int{lo, hi})
+
+	lo, hi, err = parseZoomRange("9")
+	require.NoError(t, err)
+	assert.Equal(t, []int{9, 9}, []int{lo, hi})
+
+	for _, bad := range []string{"", "a", "14-12", "-1", "3-30"} {
+		_, _, err := parseZoomRange(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestTilesFetch_ShouldWriteBBoxVectorTiles(t *testing.T) {
+	srv := newServer(t)
+	dir := t.TempDir()
+
+	_, stderr, code := runNFD(t, "tiles", "fetch", "-bbox", "-80.05,26.70,-80.03,26.71", "-zoom", "14", "-dir", dir)
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stderr, "fetched 4 tiles, skipped 0, 0 failed")
+
+	for _, path := range []string{"14/4548/6929.mvt", "14/4548/6930.mvt", "14/4549/6929.mvt", "14/4549/6930.mvt"} {
+		assert.FileExists(t, filepath.Join(dir, path))
+	}
+	assert.Len(t, srv.Requests(), 4)
+}
+
+func TestTilesFetch_ShouldWriteStormSurgeTiles(t *testing.T) {
+	srv := newServer(t)
+	dir := t.TempDir()
+
+	_, stderr, code := runNFD(t, "tiles", "fetch", "-layer", "stormsurge", "-category", "3", "-tile", "10/284/433", "-tile", "10/285/433", "-dir", dir)
+	require.Equal(t, 0, code, stderr)
+
+	image, err := os.ReadFile(filepath.Join(dir, "10", "284", "433.png"))
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(image, pngSignature))
+
+	require.Len(t, srv.Requests(), 2)
+	assert.Contains(t, srv.Requests()[0].URL.Path, "/tiles/stormsurge/category3/10/")
+}
+
+func TestTilesFetch_ShouldSkipExistingTiles(t *testing.T) {
+	srv := newServer(t)
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "10", "284", "433.mvt")
+	require.NoError(t, os.MkdirAll(filepath.Dir(existing), 0o755))
+	require.NoError(t, os.WriteFile(existing, []byte("cached"), 0o644))
+
+	_, stderr, code := runNFD(t, "tiles", "fetch", "-tile", "10/284/433", "-tile", "10/285/433", "-dir", dir, "-skip-existing")
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stderr, "fetched 1 tiles, skipped 1, 0 failed")
+
+	cached, err := os.ReadFile(existing)
+	require.NoError(t, err)
+	assert.Equal(t, "cached", string(cached))
+	assert.Len(t, srv.Requests(), 1)
+}
+
+func TestTilesFetch_ShouldReportFailedTiles(t *testing.T) {
+	srv := newServer(t)
+	srv.Inject(nfd.EndpointGetFloodVectorTile, nfdtest.Fault{Status: 500, Times: 1})
+
+	_, stderr, code := runNFD(t, "tiles", "fetch", "-tile", "10/284/433", "-tile", "10/285/433", "-dir", t.TempDir(), "-workers", "1")
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "tile 10/284/433:")
+	assert.Contains(t, stderr, "fetched 1 tiles, skipped 0, 1 failed")
+	assert.Contains(t, stderr, "1 of 2 tiles failed")
+}
+
+func TestTilesFetch_ShouldRejectInvalidFlags(t *testing.T) {
+	newServer(t)
+	dir := t.TempDir()
+
+	tests := map[string][]string{
+		"no subcommand":          {"tiles"},
+		"missing dir":            {"tiles", "fetch", "-tile", "1/0/0"},
+		"no tiles":               {"tiles", "fetch", "-dir", dir},
+		"bad tile":               {"tiles", "fetch", "-dir", dir, "-tile", "1/2/0"},
+		"bbox without zoom":      {"tiles", "fetch", "-dir", dir, "-bbox", "-80,26,-79,27"},
+		"zoom without bbox":      {"tiles", "fetch", "-dir", dir, "-zoom", "3"},
+		"inverted bbox":          {"tiles", "fetch", "-dir", dir, "-bbox", "-79,26,-80,27", "-zoom", "3"},
+		"bad layer":              {"tiles", "fetch", "-dir", dir, "-tile", "1/0/0", "-layer", "roads"},
+		"stormsurge no category": {"tiles", "fetch", "-dir", dir, "-tile", "1/0/0", "-layer", "stormsurge"},
+		"flood with category":    {"tiles", "fetch", "-dir", dir, "-tile", "1/0/0", "-category", "2"},
+	}
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, code := runNFD(t, args...)
+			assert.Equal(t, 2, code)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// Package datum converts elevations between the NGVD29 and NAVD88 vertical
+// datums. Older flood maps publish base flood elevations on NGVD29, while
+// property elevations and newer maps use NAVD88, so they must be brought
+// onto one datum before they can be compared.
+//
+// The shift between the datums varies by location, from about -1.5 m to
+// +0.5 m across the conterminous United States. An OffsetProvider supplies
+// it: Grid interpolates a local VERTCON grid file, and Fixed applies one
+// conversion factor, such as the one printed in a Flood Insurance Study.
+package datum
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kmesiab/go-nationalflooddata/models"
+)
+
+// OffsetProvider supplies the datum shift at a location.
+type OffsetProvider interface {
+	// Offset returns NAVD88 minus NGVD29 at lat, lng in meters: the value
+	// to add to an NGVD29 height to get the NAVD88 height of the same
+	// point.
+	Offset(ctx context.Context, lat, lng float64) (float64, error)
+}
+
+// Fixed is an OffsetProvider that returns the same offset, in meters,
+// everywhere. It suits a single county, whose Flood Insurance Study gives
+// one conversion factor.
+type Fixed float64
+
+// Offset returns f.
+func (f Fixed) Offset(context.Context, float64, float64) (float64, error) {
+	return float64(f), nil
+}
+
+// FixedFeet returns a Fixed for an offset given in feet, as Flood
+// Insurance Studies give them.
+func FixedFeet(feet float64) Fixed {
+	return Fixed(models.NewHeight(feet, models.Feet, models.NAVD88).Meters)
+}
+
+// Convert returns h on the datum to, using p for the shift at lat, lng.
+// Heights already on to are returned unchanged without consulting p.
+func Convert(ctx context.Context, p OffsetProvider, h models.Height, to models.VerticalDatum, lat, lng float64) (models.Height, error) {
+	if h.Datum == to {
+		return h, nil
+	}
+
+	var sign float64
+	switch {
+	case h.Datum == models.NGVD29 && to == models.NAVD88:
+		sign = 1
+	case h.Datum == models.NAVD88 && to == models.NGVD29:
+		sign = -1
+	default:
+		return models.Height{}, fmt.Errorf("datum: cannot convert %s to %s", h.Datum, to)
+	}
+
+	offset, err := p.Offset(ctx, lat, lng)
+	if err != nil {
+		return models.Height{}, fmt.Errorf("datum: offset at %g, %g: %w", lat, lng, err)
+	}
+	return models.Height{Meters: h.Meters + sign*offset, Datum: to}, nil
+}
+
+// ToNAVD88 returns h on NAVD88, the datum of the API's property
+// elevations.
+func ToNAVD88(ctx context.Context, p OffsetProvider, h models.Height, lat, lng float64) (models.Height, error) {
+	return Convert(ctx, p, h, models.NAVD88, lat, lng)
+}
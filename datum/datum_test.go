@@ -0,0 +1,55 @@
+package datum_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata/datum"
+	"github.com/kmesiab/go-nationalflooddata/models"
+)
+
+type failingProvider struct{}
+
+func (failingProvider) Offset(context.Context, float64, float64) (float64, error) {
+	return 0, errors.New("boom")
+}
+
+func TestConvert(t *testing.T) {
+	ctx := context.Background()
+
+	// Palm Beach County: NAVD88 = NGVD29 - 1.5 ft.
+	p := datum.FixedFeet(-1.5)
+	assert.InDelta(t, -0.4572, float64(p), 1e-9)
+
+	ngvd := models.NewHeight(7.5, models.Feet, models.NGVD29)
+	navd, err := datum.ToNAVD88(ctx, p, ngvd, 26.7, -80.04)
+	require.NoError(t, err)
+	assert.Equal(t, models.NAVD88, navd.Datum)
+	assert.InDelta(t, 6.0, navd.Feet(), 1e-9)
+
+	back, err := datum.Convert(ctx, p, navd, models.NGVD29, 26.7, -80.04)
+	require.NoError(t, err)
+	assert.Equal(t, models.NGVD29, back.Datum)
+	assert.InDelta(t, 7.5, back.Feet(), 1e-9)
+}
+
+func TestConvert_ShouldSkipProviderOnSameDatum(t *testing.T) {
+	h := models.NewHeight(2, models.Meters, models.NAVD88)
+	got, err := datum.Convert(context.Background(), failingProvider{}, h, models.NAVD88, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, h, got)
+}
+
+func TestConvert_ShouldReturnErrors(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := datum.ToNAVD88(ctx, failingProvider{}, models.Height{Datum: models.NGVD29}, 1, 2)
+	assert.ErrorContains(t, err, "boom")
+
+	_, err = datum.ToNAVD88(ctx, datum.Fixed(0), models.Height{Datum: "MLLW"}, 1, 2)
+	assert.ErrorContains(t, err, "cannot convert MLLW to NAVD88")
+}
@@ -0,0 +1,152 @@
+package datum
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// ErrOutsideGrid is returned by Grid.Offset for locations the grid does
+// not cover, or where it has no data.
+var ErrOutsideGrid = errors.New("datum: location outside grid")
+
+// gtxNoData marks cells without a value in GTX files.
+const gtxNoData = -88.8888
+
+// Grid is an OffsetProvider backed by a regular latitude/longitude grid of
+// offsets in meters, as distributed by NOAA for VERTCON in GTX format. It
+// interpolates bilinearly between the four cells around a location.
+type Grid struct {
+	// South and West are the latitude and longitude of the first cell,
+	// and DLat and DLng the cell spacing, in degrees.
+	South, West float64
+	DLat, DLng  float64
+
+	Rows, Cols int
+
+	// Values holds Rows*Cols offsets in meters, row by row from the south,
+	// each row from the west. NaN marks missing data.
+	Values []float32
+}
+
+// OpenGrid reads the GTX file at path.
+func OpenGrid(path string) (*Grid, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadGrid(f)
+}
+
+// ReadGrid reads a GTX grid: a big-endian header of the south latitude,
+// west longitude, latitude and longitude spacing as float64s and the row
+// and column counts as int32s, followed by the values as float32s.
+func ReadGrid(r io.Reader) (*Grid, error) {
+	br := bufio.NewReader(r)
+
+	var header struct {
+		South, West, DLat, DLng float64
+		Rows, Cols              int32
+	}
+	if err := binary.Read(br, binary.BigEndian, &header); err != nil {
+		return nil, fmt.Errorf("datum: reading grid header: %w", err)
+	}
+	if header.Rows < 2 || header.Cols < 2 || header.DLat <= 0 || header.DLng <= 0 ||
+		int64(header.Rows)*int64(header.Cols) > 1<<28 {
+		return nil, fmt.Errorf("datum: invalid grid %dx%d with spacing %g, %g",
+			header.Rows, header.Cols, header.DLat, header.DLng)
+	}
+
+	g := &Grid{
+		South: header.South,
+		West:  header.West,
+		DLat:  header.DLat,
+		DLng:  header.DLng,
+		Rows:  int(header.Rows),
+		Cols:  int(header.Cols),
+	}
+	g.Values = make([]float32, g.Rows*g.Cols)
+	if err := binary.Read(br, binary.BigEndian, g.Values); err != nil {
+		return nil, fmt.Errorf("datum: reading grid values: %w", err)
+	}
+	for i, v := range g.Values {
+		if math.Abs(float64(v)-gtxNoData) < 1e-3 {
+			g.Values[i] = float32(math.NaN())
+		}
+	}
+	return g, nil
+}
+
+// WriteTo writes g in GTX format.
+func (g *Grid) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	header := struct {
+		South, West, DLat, DLng float64
+		Rows, Cols              int32
+	}{g.South, g.West, g.DLat, g.DLng, int32(g.Rows), int32(g.Cols)}
+	if err := binary.Write(bw, binary.BigEndian, header); err != nil {
+		return 0, err
+	}
+
+	values := make([]float32, len(g.Values))
+	for i, v := range g.Values {
+		if math.IsNaN(float64(v)) {
+			v = gtxNoData
+		}
+		values[i] = v
+	}
+	if err := binary.Write(bw, binary.BigEndian, values); err != nil {
+		return 0, err
+	}
+	return int64(40 + 4*len(values)), bw.Flush()
+}
+
+// Offset interpolates the grid at lat, lng. Longitudes are matched modulo
+// 360, so grids with eastings from 0 to 360 work with negative longitudes.
+func (g *Grid) Offset(_ context.Context, lat, lng float64) (float64, error) {
+	y := (lat - g.South) / g.DLat
+	x := math.Mod(lng-g.West, 360)
+	if x < 0 {
+		x += 360
+	}
+	x /= g.DLng
+
+	const eps = 1e-9
+	if y < -eps || x < -eps || y > float64(g.Rows-1)+eps || x > float64(g.Cols-1)+eps {
+		return 0, ErrOutsideGrid
+	}
+
+	// Clamp so locations on the north and east edges use the last cell.
+	row := min(max(int(math.Floor(y)), 0), g.Rows-2)
+	col := min(max(int(math.Floor(x)), 0), g.Cols-2)
+	fy, fx := y-float64(row), x-float64(col)
+
+	// Corners with no weight are skipped, so a missing neighbour does not
+	// hide a value exactly on a grid line.
+	corners := [4]struct {
+		r, c int
+		w    float64
+	}{
+		{row, col, (1 - fx) * (1 - fy)},
+		{row, col + 1, fx * (1 - fy)},
+		{row + 1, col, (1 - fx) * fy},
+		{row + 1, col + 1, fx * fy},
+	}
+	var v float64
+	for _, corner := range corners {
+		if corner.w > 0 {
+			v += corner.w * float64(g.Values[corner.r*g.Cols+corner.c])
+		}
+	}
+	if math.IsNaN(v) {
+		return 0, ErrOutsideGrid
+	}
+	return v, nil
+}
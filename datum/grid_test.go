@@ -0,0 +1,96 @@
+package datum_test
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata/datum"
+)
+
+// testGrid covers 26-27N, 81-80W at half degree spacing, in eastings from
+// 0 to 360, with one missing cell in the north east.
+func testGrid() *datum.Grid {
+	return &datum.Grid{
+		South: 26, West: 279, DLat: 0.5, DLng: 0.5,
+		Rows: 3, Cols: 3,
+		Values: []float32{
+			-0.40, -0.44, -0.48,
+			-0.42, -0.46, -0.50,
+			-0.44, -0.48, float32(math.NaN()),
+		},
+	}
+}
+
+func TestGrid_Offset(t *testing.T) {
+	ctx := context.Background()
+	g := testGrid()
+
+	tests := []struct {
+		lat, lng float64
+		want     float64
+	}{
+		{26, -81, -0.40},
+		{26.5, -80.5, -0.46},
+		{26.25, -80.75, -0.43},
+		{26, -80, -0.48},
+		{26.5, -80, -0.50},
+		{26.25, -80.25, -0.47},
+	}
+	for _, tt := range tests {
+		got, err := g.Offset(ctx, tt.lat, tt.lng)
+		require.NoError(t, err, "%g, %g", tt.lat, tt.lng)
+		assert.InDelta(t, tt.want, got, 1e-6, "%g, %g", tt.lat, tt.lng)
+	}
+
+	for _, ll := range [][2]float64{{25.9, -80.5}, {27.1, -80.5}, {26.5, -81.1}, {26.5, -79.9}, {26.9, -80.1}} {
+		_, err := g.Offset(ctx, ll[0], ll[1])
+		assert.ErrorIs(t, err, datum.ErrOutsideGrid, "%g, %g", ll[0], ll[1])
+	}
+}
+
+func TestReadGrid(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := testGrid().WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(40+9*4), n)
+	assert.Equal(t, 40+9*4, buf.Len())
+
+	path := filepath.Join(t.TempDir(), "vertcon.gtx")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+	g, err := datum.OpenGrid(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, g.Rows)
+	assert.Equal(t, 279.0, g.West)
+	assert.True(t, math.IsNaN(float64(g.Values[8])))
+
+	got, err := g.Offset(context.Background(), 26.25, -80.75)
+	require.NoError(t, err)
+	assert.InDelta(t, -0.43, got, 1e-6)
+}
+
+func TestReadGrid_ShouldRejectBadFiles(t *testing.T) {
+	_, err := datum.ReadGrid(bytes.NewReader([]byte{1, 2, 3}))
+	assert.ErrorContains(t, err, "reading grid header")
+
+	var buf bytes.Buffer
+	_, err = testGrid().WriteTo(&buf)
+	require.NoError(t, err)
+
+	_, err = datum.ReadGrid(bytes.NewReader(buf.Bytes()[:50]))
+	assert.ErrorContains(t, err, "reading grid values")
+
+	bad := &datum.Grid{Rows: 1, Cols: 3, DLat: 1, DLng: 1, Values: make([]float32, 3)}
+	buf.Reset()
+	_, err = bad.WriteTo(&buf)
+	require.NoError(t, err)
+	_, err = datum.ReadGrid(&buf)
+	assert.ErrorContains(t, err, "invalid grid")
+}
@@ -0,0 +1,358 @@
+// Package determination decides whether the Flood Disaster Protection Act
+// requires flood insurance for a property, from a flood data response.
+//
+// Determine follows the questions a lender answers on a flood hazard
+// determination: is the building in a Special Flood Hazard Area, does the
+// community participate in the NFIP, is the building in a Coastal Barrier
+// Resources System area, and has a Letter of Map Amendment removed it from
+// the SFHA. Every finding cites the response fields it was based on.
+package determination
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/models"
+)
+
+// Outcome is whether flood insurance must be purchased.
+type Outcome string
+
+// Outcomes of a determination.
+const (
+	// Required means the building is in an SFHA of a participating
+	// community, so flood insurance must be purchased for a federally
+	// regulated or backed loan.
+	Required Outcome = "required"
+
+	// NotRequired means the building is outside the SFHA, or a LOMA has
+	// removed it. Lenders may still require insurance.
+	NotRequired Outcome = "not_required"
+
+	// NotAvailable means the building is in an SFHA but federal flood
+	// insurance is not available: the community does not participate in
+	// the NFIP, is suspended, or the building is in a CBRS area. Federally
+	// backed loans for such buildings are restricted.
+	NotAvailable Outcome = "not_available"
+
+	// Undetermined means the response lacks the data to decide.
+	Undetermined Outcome = "undetermined"
+)
+
+// Rules a Reason can come from.
+const (
+	RuleSFHA      = "sfha"
+	RuleCBRS      = "cbrs"
+	RuleLOMA      = "loma"
+	RuleCommunity = "community"
+)
+
+// Decision is the result of Determine.
+type Decision struct {
+	Outcome Outcome `json:"outcome"`
+
+	// SFHA is whether the building is in a Special Flood Hazard Area, as
+	// mapped; a LOMA may still remove it. It is false when Outcome is
+	// Undetermined for lack of hazard data.
+	SFHA bool `json:"sfha"`
+
+	// Reasons are the findings that led to Outcome, in the order they
+	// were evaluated.
+	Reasons []Reason `json:"reasons"`
+
+	// LOMA is the letter that removed the building from the SFHA, when
+	// one did.
+	LOMA *models.Loma `json:"loma,omitempty"`
+}
+
+// Required reports whether flood insurance must be purchased.
+func (d Decision) Required() bool {
+	return d.Outcome == Required
+}
+
+// Reason is one finding of a determination.
+type Reason struct {
+	// Rule is the question the finding answers, one of the Rule
+	// constants.
+	Rule string `json:"rule"`
+
+	Finding string   `json:"finding"`
+	Sources []Source `json:"sources,omitempty"`
+}
+
+// Source cites a response field a finding was based on.
+type Source struct {
+	// Field is the field's JSON path in the response, such as
+	// "result.flood.s_fld_haz_ar[0].sfha_tf".
+	Field string `json:"field"`
+	Value string `json:"value"`
+}
+
+// Options adjust a determination.
+type Options struct {
+	// CBRS is whether the building is in a Coastal Barrier Resources
+	// System area or Otherwise Protected Area, and was built or
+	// substantially improved after the area's designation. The API does
+	// not report CBRS areas, so this must come from another source, such
+	// as the U.S. Fish and Wildlife Service CBRS mapper. Nil means
+	// unknown.
+	CBRS *bool
+
+	// LOMARadiusMiles is how close a LOMA must be to the property to be
+	// taken as applying to it. Zero means DefaultLOMARadiusMiles.
+	LOMARadiusMiles float64
+}
+
+// DefaultLOMARadiusMiles is the default Options.LOMARadiusMiles, about 16
+// meters.
+const DefaultLOMARadiusMiles = 0.01
+
+// Determine decides whether flood insurance is required for the property
+// in resp.
+func Determine(resp *client.Response, opts Options) Decision {
+	var d Decision
+	result := resp.Result
+
+	sfha, ok := d.sfha(result.FloodFldHazAr)
+	d.SFHA = sfha
+	if !ok {
+		d.Outcome = Undetermined
+		return d
+	}
+	if !sfha {
+		d.Outcome = NotRequired
+		return d
+	}
+
+	if d.cbrs(opts.CBRS) {
+		d.Outcome = NotAvailable
+		return d
+	}
+
+	if d.loma(result, opts) {
+		d.Outcome = NotRequired
+		return d
+	}
+
+	d.Outcome = d.community(result.Community)
+	return d
+}
+
+func (d *Decision) add(rule, finding string, sources ...Source) {
+	d.Reasons = append(d.Reasons, Reason{Rule: rule, Finding: finding, Sources: sources})
+}
+
+// sfha reports whether any hazard area is an SFHA, and whether there were
+// any to decide from. sfha_tf is authoritative; the zone is used only when
+// it is missing.
+func (d *Decision) sfha(hazards []models.FloodFieldHazard) (sfha, ok bool) {
+	if len(hazards) == 0 {
+		d.add(RuleSFHA, "no flood hazard area was returned for the location",
+			Source{Field: "result.flood.s_fld_haz_ar", Value: "[]"})
+		return false, false
+	}
+
+	var sources []Source
+	for i, h := range hazards {
+		prefix := fmt.Sprintf("result.flood.s_fld_haz_ar[%d].", i)
+		sources = append(sources, Source{Field: prefix + "fld_zone", Value: h.FldZone})
+
+		switch strings.ToUpper(strings.TrimSpace(h.SfhaTf)) {
+		case "T":
+			sfha = true
+			sources = append(sources, Source{Field: prefix + "sfha_tf", Value: h.SfhaTf})
+		case "F":
+			sources = append(sources, Source{Field: prefix + "sfha_tf", Value: h.SfhaTf})
+		default:
+			sfha = sfha || h.Zone().IsSFHA()
+		}
+	}
+
+	if sfha {
+		d.add(RuleSFHA, "the building is in a Special Flood Hazard Area", sources...)
+	} else {
+		d.add(RuleSFHA, "the building is outside the Special Flood Hazard Area", sources...)
+	}
+	return sfha, true
+}
+
+// cbrs reports whether the building is in a CBRS area.
+func (d *Decision) cbrs(in *bool) bool {
+	switch {
+	case in == nil:
+		d.add(RuleCBRS, "CBRS status is unknown; the API does not report Coastal Barrier Resources System areas")
+		return false
+	case *in:
+		d.add(RuleCBRS, "the building is in a CBRS area or Otherwise Protected Area, where federal flood insurance is not available",
+			Source{Field: "options.cbrs", Value: "true"})
+		return true
+	default:
+		d.add(RuleCBRS, "the building is not in a CBRS area", Source{Field: "options.cbrs", Value: "false"})
+		return false
+	}
+}
+
+// loma reports whether a LOMA removes the building from the SFHA. A LOMA
+// applies if it is a completed determination in the property's community,
+// within the radius, and ended on or after the current panel's effective
+// date; a map revision supersedes earlier letters.
+func (d *Decision) loma(result client.Result, opts Options) bool {
+	if result.Loma == nil || len(*result.Loma) == 0 {
+		return false
+	}
+
+	radius := opts.LOMARadiusMiles
+	if radius == 0 {
+		radius = DefaultLOMARadiusMiles
+	}
+
+	cids := make(map[string]bool)
+	for _, p := range result.FloodPolAr {
+		cids[strings.TrimSpace(p.CID)] = true
+	}
+
+	panel := models.MostRecentPanel(result.FloodFirmPan)
+	var panelDated bool
+	if panel != nil {
+		_, err := panel.EffectiveDate()
+		panelDated = err == nil
+	}
+
+	for i, l := range *result.Loma {
+		prefix := fmt.Sprintf("result.loma[%d].", i)
+		if !isRemoval(l) || !cids[strings.TrimSpace(l.CID)] || l.Miles > radius {
+			continue
+		}
+
+		if _, err := l.EndedDate(); err != nil {
+			continue
+		}
+		sources := []Source{
+			{Field: prefix + "casenumber", Value: l.CaseNumber},
+			{Field: prefix + "projectcat", Value: l.ProjectCat},
+			{Field: prefix + "dateended", Value: l.DateEnded},
+		}
+		if panelDated && !l.IssuedAfterPanel(*panel) {
+			d.add(RuleLOMA, fmt.Sprintf("LOMA %s predates the current panel and is superseded", l.CaseNumber),
+				append(sources, panelSource(result.FloodFirmPan, panel))...)
+			continue
+		}
+
+		lc := l
+		d.LOMA = &lc
+		d.add(RuleLOMA, fmt.Sprintf("LOMA %s removes the building from the Special Flood Hazard Area", l.CaseNumber),
+			sources...)
+		return true
+	}
+	return false
+}
+
+// isRemoval reports whether l is a completed letter that can remove a
+// building from the SFHA.
+func isRemoval(l models.Loma) bool {
+	if !strings.EqualFold(strings.TrimSpace(l.Status), "Completed") {
+		return false
+	}
+	switch strings.ToUpper(strings.TrimSpace(l.ProjectCat)) {
+	case "LOMA", "LOMR-F", "LOMR-FW":
+	default:
+		return false
+	}
+	return !strings.Contains(strings.ToUpper(l.Determinat), "DENIAL")
+}
+
+// panelSource cites the effective date of panel, one of panels.
+func panelSource(panels []models.FloodFirmPan, panel *models.FloodFirmPan) Source {
+	for i := range panels {
+		if &panels[i] == panel {
+			return Source{Field: fmt.Sprintf("result.flood.s_firm_pan[%d].eff_date", i), Value: panel.EffDate}
+		}
+	}
+	return Source{Field: "result.flood.s_firm_pan", Value: panel.EffDate}
+}
+
+// community decides the outcome for a building in an SFHA from the
+// community's NFIP status.
+func (d *Decision) community(c *models.Community) Outcome {
+	status := communityStatus(c)
+	if c == nil {
+		d.add(RuleCommunity, "NFIP participation is unknown; no community was returned",
+			Source{Field: "result.community", Value: "null"})
+		return Undetermined
+	}
+
+	part := Source{Field: "result.community.comm_part", Value: fmt.Sprint(c.CommPart)}
+	curreff := Source{Field: "result.community.curreff", Value: strings.TrimSpace(c.Curreff)}
+	switch status {
+	case statusNotParticipating:
+		d.add(RuleCommunity, "the community does not participate in the NFIP, so federal flood insurance is not available", part)
+		return NotAvailable
+	case statusSuspended:
+		d.add(RuleCommunity, "the community is suspended from the NFIP, so federal flood insurance is not available", part, curreff)
+		return NotAvailable
+	case statusSanctioned:
+		d.add(RuleCommunity, "the community is sanctioned, so federal flood insurance is not available",
+			part, Source{Field: "result.community.notes", Value: *c.Notes})
+		return NotAvailable
+	case statusEmergency:
+		d.add(RuleCommunity, "the community participates in the NFIP Emergency Program, with limited coverage", part, curreff)
+	default:
+		d.add(RuleCommunity, "the community participates in the NFIP", part,
+			Source{Field: "result.community.comm_name", Value: strings.TrimSpace(c.CommName)})
+	}
+	return Required
+}
+
+// nfipStatus is a community's standing in the NFIP.
+type nfipStatus int
+
+const (
+	statusUnknown nfipStatus = iota
+	statusNotParticipating
+	statusSuspended
+	statusSanctioned
+	statusEmergency
+	statusRegular
+)
+
+// available reports whether federal flood insurance can be bought in a
+// community with status s, and whether that is known.
+func (s nfipStatus) available() (available, known bool) {
+	switch s {
+	case statusUnknown:
+		return false, false
+	case statusEmergency, statusRegular:
+		return true, true
+	default:
+		return false, true
+	}
+}
+
+// communityStatus reads a community's NFIP status from the Community
+// Status Book fields: participation, a (S) or (E) marker on the current
+// effective date, and suspension or sanction notes.
+func communityStatus(c *models.Community) nfipStatus {
+	if c == nil {
+		return statusUnknown
+	}
+	if !c.CommPart {
+		return statusNotParticipating
+	}
+
+	curreff := strings.TrimSpace(c.Curreff)
+	var notes string
+	if c.Notes != nil {
+		notes = strings.ToUpper(*c.Notes)
+	}
+	switch {
+	case strings.Contains(curreff, "(S)"):
+		return statusSuspended
+	case strings.Contains(notes, "SUSPEND") || strings.Contains(notes, "SANCTION"):
+		return statusSanctioned
+	case strings.Contains(curreff, "(E)"):
+		return statusEmergency
+	default:
+		return statusRegular
+	}
+}
@@ -0,0 +1,120 @@
+package determination_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata/determination"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+)
+
+func rules(d determination.Decision) []string {
+	var rules []string
+	for _, r := range d.Reasons {
+		rules = append(rules, r.Rule)
+	}
+	return rules
+}
+
+func TestDetermine(t *testing.T) {
+	d := determination.Determine(nfdtest.FloodData(t), determination.Options{})
+
+	assert.Equal(t, determination.Required, d.Outcome)
+	assert.True(t, d.Required())
+	assert.Nil(t, d.LOMA)
+	assert.Equal(t, []string{"sfha", "cbrs", "loma", "community"}, rules(d))
+
+	assert.Contains(t, d.Reasons[0].Sources, determination.Source{
+		Field: "result.flood.s_fld_haz_ar[0].sfha_tf", Value: "T",
+	})
+	assert.Contains(t, d.Reasons[2].Finding, "00-04-3936A predates the current panel")
+	assert.Contains(t, d.Reasons[2].Sources, determination.Source{
+		Field: "result.flood.s_firm_pan[0].eff_date", Value: "2024-12-20",
+	})
+	assert.Contains(t, d.Reasons[3].Sources, determination.Source{
+		Field: "result.community.comm_part", Value: "true",
+	})
+}
+
+func TestDetermine_ShouldNotRequireOutsideSFHA(t *testing.T) {
+	resp := nfdtest.FloodData(t)
+	resp.Result.FloodFldHazAr[0].FldZone = "X"
+	resp.Result.FloodFldHazAr[0].SfhaTf = "F"
+
+	d := determination.Determine(resp, determination.Options{})
+	assert.Equal(t, determination.NotRequired, d.Outcome)
+	assert.Equal(t, []string{"sfha"}, rules(d))
+}
+
+func TestDetermine_ShouldFallBackToZoneWithoutSfhaTf(t *testing.T) {
+	resp := nfdtest.FloodData(t)
+	resp.Result.FloodFldHazAr[0].SfhaTf = ""
+
+	d := determination.Determine(resp, determination.Options{})
+	assert.Equal(t, determination.Required, d.Outcome)
+}
+
+func TestDetermine_ShouldBeUndeterminedWithoutData(t *testing.T) {
+	resp := nfdtest.FloodData(t)
+	resp.Result.FloodFldHazAr = nil
+	assert.Equal(t, determination.Undetermined, determination.Determine(resp, determination.Options{}).Outcome)
+
+	resp = nfdtest.FloodData(t)
+	resp.Result.Community = nil
+	assert.Equal(t, determination.Undetermined, determination.Determine(resp, determination.Options{}).Outcome)
+}
+
+func TestDetermine_ShouldReportCBRS(t *testing.T) {
+	cbrs := true
+	d := determination.Determine(nfdtest.FloodData(t), determination.Options{CBRS: &cbrs})
+	assert.Equal(t, determination.NotAvailable, d.Outcome)
+	assert.Equal(t, []string{"sfha", "cbrs"}, rules(d))
+}
+
+func TestDetermine_ShouldApplyCurrentLOMA(t *testing.T) {
+	resp := nfdtest.FloodData(t)
+	(*resp.Result.Loma)[2].DateEnded = "2025-03-01"
+
+	d := determination.Determine(resp, determination.Options{})
+	assert.Equal(t, determination.NotRequired, d.Outcome)
+	require.NotNil(t, d.LOMA)
+	assert.Equal(t, "00-04-3936A", d.LOMA.CaseNumber)
+
+	// Too far away.
+	d = determination.Determine(resp, determination.Options{LOMARadiusMiles: 0.001})
+	assert.Equal(t, determination.Required, d.Outcome)
+
+	// Denied.
+	(*resp.Result.Loma)[2].Determinat = "Denial"
+	d = determination.Determine(resp, determination.Options{})
+	assert.Equal(t, determination.Required, d.Outcome)
+}
+
+func TestDetermine_ShouldReportCommunityStatus(t *testing.T) {
+	resp := nfdtest.FloodData(t)
+	resp.Result.Community.CommPart = false
+	assert.Equal(t, determination.NotAvailable, determination.Determine(resp, determination.Options{}).Outcome)
+
+	resp = nfdtest.FloodData(t)
+	resp.Result.Community.Curreff = "100517(S)"
+	assert.Equal(t, determination.NotAvailable, determination.Determine(resp, determination.Options{}).Outcome)
+
+	resp = nfdtest.FloodData(t)
+	notes := "Sanctioned 01/01/20"
+	resp.Result.Community.Notes = &notes
+	assert.Equal(t, determination.NotAvailable, determination.Determine(resp, determination.Options{}).Outcome)
+
+	resp = nfdtest.FloodData(t)
+	resp.Result.Community.Curreff = "100517(E)"
+	d := determination.Determine(resp, determination.Options{})
+	assert.Equal(t, determination.Required, d.Outcome)
+	assert.Contains(t, d.Reasons[len(d.Reasons)-1].Finding, "Emergency Program")
+}
+
+func TestDecision_Required(t *testing.T) {
+	assert.True(t, determination.Decision{Outcome: determination.Required}.Required())
+	assert.False(t, determination.Decision{Outcome: determination.NotAvailable}.Required())
+	assert.False(t, determination.Decision{Outcome: determination.Undetermined}.Required())
+}
@@ -0,0 +1,303 @@
+package determination
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/models"
+)
+
+// Form is a Standard Flood Hazard Determination Form, FEMA Form 086-0-32.
+// NewForm fills the NFIP fields of Section II from a flood data response;
+// the loan and preparer fields come from the lender.
+type Form struct {
+	// Loan is Section I, Loan Information.
+	Loan Loan `json:"loan"`
+
+	// Community is Section II.A, NFIP Community Jurisdiction.
+	Community FormCommunity `json:"community"`
+
+	// Map is Section II.B, NFIP Data Affecting Building/Mobile Home.
+	Map FormMap `json:"map"`
+
+	// Availability is Section II.C, Federal Flood Insurance
+	// Availability.
+	Availability FormAvailability `json:"availability"`
+
+	// Determination is Section II.D. SFHA is nil when the response did not
+	// say.
+	Determination FormDetermination `json:"determination"`
+
+	// Comments is Section II.E.
+	Comments string `json:"comments,omitempty"`
+
+	// Preparer is Section II.F, Preparer's Information.
+	Preparer Preparer `json:"preparer"`
+}
+
+// Loan is the lender-supplied Section I of a Form.
+type Loan struct {
+	LenderName      string `json:"lender_name"`
+	LenderAddress   string `json:"lender_address"`
+	PropertyAddress string `json:"property_address"`
+	LenderID        string `json:"lender_id,omitempty"`
+	LoanIdentifier  string `json:"loan_identifier"`
+
+	// InsuranceAmount is the amount of flood insurance required, as the
+	// lender writes it on the form.
+	InsuranceAmount string `json:"insurance_amount,omitempty"`
+}
+
+// Preparer is Section II.F of a Form: who made the determination, and
+// when.
+type Preparer struct {
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	Telephone string    `json:"telephone"`
+	Date      time.Time `json:"date"`
+}
+
+// FormCommunity is Section II.A of a Form.
+type FormCommunity struct {
+	Name   string `json:"name"`
+	County string `json:"county"`
+	State  string `json:"state"`
+	Number string `json:"number"`
+}
+
+// FormMap is Section II.B of a Form.
+type FormMap struct {
+	// NoMap is set when no NFIP map covers the building.
+	NoMap bool `json:"no_map"`
+
+	PanelNumber   string `json:"panel_number"`
+	EffectiveDate string `json:"effective_date"`
+
+	// LOMA is set when a LOMA or LOMR-F removed the building from the
+	// SFHA; LOMADate and LOMACaseNumber describe it.
+	LOMA           bool   `json:"loma"`
+	LOMADate       string `json:"loma_date,omitempty"`
+	LOMACaseNumber string `json:"loma_case_number,omitempty"`
+
+	FloodZone string `json:"flood_zone"`
+}
+
+// Programs a community can participate in.
+const (
+	ProgramRegular   = "regular"
+	ProgramEmergency = "emergency"
+)
+
+// FormAvailability is Section II.C of a Form.
+type FormAvailability struct {
+	// Available is set when federal flood insurance is available, under
+	// Program. It is false when the building is in a CBRS area, and nil
+	// when the community's status is unknown.
+	Available *bool  `json:"available"`
+	Program   string `json:"program,omitempty"`
+
+	// NotParticipating is set when insurance is unavailable because the
+	// community does not participate in the NFIP, or is suspended or
+	// sanctioned.
+	NotParticipating bool `json:"not_participating"`
+
+	// CBRS is set when the building is in a CBRS area or Otherwise
+	// Protected Area.
+	CBRS bool `json:"cbrs"`
+}
+
+// FormDetermination is Section II.D of a Form.
+type FormDetermination struct {
+	// SFHA is whether the building is in a Special Flood Hazard Area,
+	// after any LOMA. Nil means undetermined.
+	SFHA *bool `json:"sfha"`
+
+	// Decision is the determination the form was filled from.
+	Decision Decision `json:"decision"`
+}
+
+// NewForm fills a Form from resp, with Section I and the preparer's
+// information from loan and preparer. An empty loan.PropertyAddress is
+// filled from the response.
+func NewForm(resp *client.Response, loan Loan, preparer Preparer, opts Options) *Form {
+	d := Determine(resp, opts)
+	result := resp.Result
+
+	f := &Form{Loan: loan, Preparer: preparer}
+	if f.Loan.PropertyAddress == "" {
+		f.Loan.PropertyAddress = propertyAddress(resp)
+	}
+
+	// II.A: the community name and number from the policy area, preferring
+	// the Community Status Book name.
+	if len(result.FloodPolAr) > 0 {
+		f.Community.Name = strings.TrimSpace(result.FloodPolAr[0].PolName1)
+		f.Community.Number = strings.TrimSpace(result.FloodPolAr[0].CID)
+	}
+	if result.Community != nil && strings.TrimSpace(result.Community.CommName) != "" {
+		f.Community.Name = strings.TrimSpace(result.Community.CommName)
+	}
+	if p := resp.ParcelAddress; p != nil {
+		f.Community.County = strings.TrimSpace(p.CountyName)
+		f.Community.State = strings.TrimSpace(p.StateAbbr)
+	}
+	if f.Community.County == "" {
+		f.Community.County = requestString(resp.Request.County)
+	}
+	if f.Community.State == "" {
+		f.Community.State = requestString(resp.Request.State)
+	}
+
+	// II.B
+	if panel := models.MostRecentPanel(result.FloodFirmPan); panel != nil {
+		f.Map.PanelNumber = strings.TrimSpace(panel.FirmPan)
+		f.Map.EffectiveDate = strings.TrimSpace(panel.EffDate)
+	} else {
+		f.Map.NoMap = true
+	}
+	if d.LOMA != nil {
+		f.Map.LOMA = true
+		f.Map.LOMADate = d.LOMA.DateEnded
+		f.Map.LOMACaseNumber = d.LOMA.CaseNumber
+	}
+	f.Map.FloodZone = floodZones(result.FloodFldHazAr)
+
+	// II.C, from the same community status and CBRS finding Determine
+	// uses, so the form cannot offer insurance the determination says is
+	// unavailable.
+	status := communityStatus(result.Community)
+	available, known := status.available()
+	switch {
+	case opts.CBRS != nil && *opts.CBRS:
+		f.Availability.CBRS = true
+		f.Availability.Available = new(bool)
+	case !known:
+	case !available:
+		f.Availability.Available = new(bool)
+		f.Availability.NotParticipating = true
+	default:
+		f.Availability.Available = &available
+		f.Availability.Program = ProgramRegular
+		if status == statusEmergency {
+			f.Availability.Program = ProgramEmergency
+		}
+	}
+
+	// II.D
+	f.Determination.Decision = d
+	if len(result.FloodFldHazAr) > 0 {
+		sfha := d.SFHA && d.LOMA == nil
+		f.Determination.SFHA = &sfha
+	}
+	return f
+}
+
+// propertyAddress returns the address searched for, or the parcel's.
+func propertyAddress(resp *client.Response) string {
+	if a := strings.TrimSpace(resp.Request.Address); a != "" {
+		return a
+	}
+	p := resp.ParcelAddress
+	if p == nil {
+		return ""
+	}
+
+	var street []string
+	for _, s := range []*string{&p.AddrNumber, p.AddrStreetPrefix, &p.AddrStreetName, &p.AddrStreetType, p.AddrStreetSuffix} {
+		if s != nil && strings.TrimSpace(*s) != "" {
+			street = append(street, strings.TrimSpace(*s))
+		}
+	}
+	if len(street) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s, %s, %s %s", strings.Join(street, " "), p.Physcity, p.StateAbbr, p.Physzip))
+}
+
+// requestString returns an echoed request parameter, which the API
+// returns as a string or null.
+func requestString(v interface{}) string {
+	s, _ := v.(string)
+	return strings.TrimSpace(s)
+}
+
+// floodZones joins the distinct zones of hazards.
+func floodZones(hazards []models.FloodFieldHazard) string {
+	var zones []string
+	seen := make(map[models.FloodZone]bool)
+	for _, h := range hazards {
+		z := h.Zone()
+		if z != "" && !seen[z] {
+			seen[z] = true
+			zones = append(zones, string(z))
+		}
+	}
+	return strings.Join(zones, ", ")
+}
+
+// Missing returns the JSON paths of required fields that are empty, such
+// as "community.number". Section I and the preparer's information are
+// checked too, since the form is incomplete without them.
+func (f *Form) Missing() []string {
+	var missing []string
+	check := func(field, value string) {
+		if strings.TrimSpace(value) == "" {
+			missing = append(missing, field)
+		}
+	}
+
+	check("loan.lender_name", f.Loan.LenderName)
+	check("loan.lender_address", f.Loan.LenderAddress)
+	check("loan.property_address", f.Loan.PropertyAddress)
+	check("loan.loan_identifier", f.Loan.LoanIdentifier)
+
+	check("community.name", f.Community.Name)
+	check("community.county", f.Community.County)
+	check("community.state", f.Community.State)
+	check("community.number", f.Community.Number)
+
+	if !f.Map.NoMap {
+		check("map.panel_number", f.Map.PanelNumber)
+		check("map.effective_date", f.Map.EffectiveDate)
+	}
+	if f.Map.LOMA {
+		check("map.loma_date", f.Map.LOMADate)
+		check("map.loma_case_number", f.Map.LOMACaseNumber)
+	}
+	check("map.flood_zone", f.Map.FloodZone)
+
+	if f.Availability.Available == nil {
+		missing = append(missing, "availability.available")
+	}
+	if f.Determination.SFHA == nil {
+		missing = append(missing, "determination.sfha")
+	}
+
+	check("preparer.name", f.Preparer.Name)
+	check("preparer.address", f.Preparer.Address)
+	check("preparer.telephone", f.Preparer.Telephone)
+	if f.Preparer.Date.IsZero() {
+		missing = append(missing, "preparer.date")
+	}
+	return missing
+}
+
+// WriteJSON writes f as indented JSON, with a "missing" array listing the
+// fields Missing reports.
+func (f *Form) WriteJSON(w io.Writer) error {
+	out := struct {
+		*Form
+		Missing []string `json:"missing"`
+	}{f, f.Missing()}
+	if out.Missing == nil {
+		out.Missing = []string{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,143 @@
+package determination_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata/determination"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+)
+
+var (
+	loan = determination.Loan{
+		LenderName:     "First Coastal Bank",
+		LenderAddress:  "1 Main St, West Palm Beach, FL 33401",
+		LoanIdentifier: "LN-1001",
+	}
+	preparer = determination.Preparer{
+		Name:      "Flood Determinations Inc.",
+		Address:   "2 Side St, Tampa, FL 33602",
+		Telephone: "555-0100",
+		Date:      time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+	}
+)
+
+func TestNewForm(t *testing.T) {
+	f := determination.NewForm(nfdtest.FloodData(t), loan, preparer, determination.Options{})
+
+	assert.Equal(t, "430 Australian Ave Palm Beach, FL 33480", f.Loan.PropertyAddress)
+	assert.Equal(t, determination.FormCommunity{
+		Name:   "PALM BEACH, TOWN OF",
+		County: "Palm Beach",
+		State:  "FL",
+		Number: "120220",
+	}, f.Community)
+	assert.Equal(t, determination.FormMap{
+		PanelNumber:   "12099C0583G",
+		EffectiveDate: "2024-12-20",
+		FloodZone:     "AE",
+	}, f.Map)
+
+	require.NotNil(t, f.Availability.Available)
+	assert.True(t, *f.Availability.Available)
+	assert.Equal(t, determination.ProgramRegular, f.Availability.Program)
+	assert.False(t, f.Availability.NotParticipating)
+
+	require.NotNil(t, f.Determination.SFHA)
+	assert.True(t, *f.Determination.SFHA)
+	assert.Equal(t, determination.Required, f.Determination.Decision.Outcome)
+
+	assert.Empty(t, f.Missing())
+}
+
+func TestNewForm_ShouldRecordLOMA(t *testing.T) {
+	resp := nfdtest.FloodData(t)
+	(*resp.Result.Loma)[2].DateEnded = "2025-03-01"
+
+	f := determination.NewForm(resp, loan, preparer, determination.Options{})
+	assert.True(t, f.Map.LOMA)
+	assert.Equal(t, "2025-03-01", f.Map.LOMADate)
+	assert.Equal(t, "00-04-3936A", f.Map.LOMACaseNumber)
+	require.NotNil(t, f.Determination.SFHA)
+	assert.False(t, *f.Determination.SFHA)
+}
+
+func TestNewForm_ShouldAgreeWithDeterminationOnAvailability(t *testing.T) {
+	cbrs := true
+	f := determination.NewForm(nfdtest.FloodData(t), loan, preparer, determination.Options{CBRS: &cbrs})
+	require.NotNil(t, f.Availability.Available)
+	assert.False(t, *f.Availability.Available)
+	assert.True(t, f.Availability.CBRS)
+	assert.False(t, f.Availability.NotParticipating)
+	assert.Empty(t, f.Availability.Program)
+	assert.Equal(t, determination.NotAvailable, f.Determination.Decision.Outcome)
+
+	resp := nfdtest.FloodData(t)
+	notes := "Sanctioned for failure to adopt floodplain management regulations"
+	resp.Result.Community.Notes = &notes
+	f = determination.NewForm(resp, loan, preparer, determination.Options{})
+	require.NotNil(t, f.Availability.Available)
+	assert.False(t, *f.Availability.Available)
+	assert.True(t, f.Availability.NotParticipating)
+	assert.Equal(t, determination.NotAvailable, f.Determination.Decision.Outcome)
+}
+
+func TestForm_Missing(t *testing.T) {
+	resp := nfdtest.FloodData(t)
+	resp.Result.FloodPolAr = nil
+	resp.Result.FloodFirmPan[0].EffDate = ""
+	resp.Result.Community = nil
+
+	f := determination.NewForm(resp, determination.Loan{}, determination.Preparer{}, determination.Options{})
+	assert.Equal(t, []string{
+		"loan.lender_name",
+		"loan.lender_address",
+		"loan.loan_identifier",
+		"community.name",
+		"community.number",
+		"map.effective_date",
+		"availability.available",
+		"preparer.name",
+		"preparer.address",
+		"preparer.telephone",
+		"preparer.date",
+	}, f.Missing())
+
+	resp.Result.FloodFirmPan = nil
+	resp.Result.FloodFldHazAr = nil
+	f = determination.NewForm(resp, loan, preparer, determination.Options{})
+	assert.True(t, f.Map.NoMap)
+	assert.Contains(t, f.Missing(), "map.flood_zone")
+	assert.Contains(t, f.Missing(), "determination.sfha")
+	assert.NotContains(t, f.Missing(), "map.panel_number")
+}
+
+func TestForm_WriteJSON(t *testing.T) {
+	f := determination.NewForm(nfdtest.FloodData(t), determination.Loan{}, preparer, determination.Options{})
+
+	var buf bytes.Buffer
+	require.NoError(t, f.WriteJSON(&buf))
+
+	var out struct {
+		Community struct {
+			Number string `json:"number"`
+		} `json:"community"`
+		Determination struct {
+			SFHA     bool `json:"sfha"`
+			Decision struct {
+				Outcome string `json:"outcome"`
+			} `json:"decision"`
+		} `json:"determination"`
+		Missing []string `json:"missing"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	assert.Equal(t, "120220", out.Community.Number)
+	assert.True(t, out.Determination.SFHA)
+	assert.Equal(t, "required", out.Determination.Decision.Outcome)
+	assert.Equal(t, []string{"loan.lender_name", "loan.lender_address", "loan.loan_identifier"}, out.Missing)
+}
@@ -0,0 +1,210 @@
+// Package sfhdfpdf renders a determination.Form, the Standard Flood Hazard
+// Determination Form, as a PDF. It is kept apart from the determination
+// package so that only callers who need PDFs depend on a PDF library.
+package sfhdfpdf
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+
+	"github.com/kmesiab/go-nationalflooddata/determination"
+)
+
+// missingText is what Write prints for missing fields, in red.
+const missingText = "MISSING"
+
+// Write renders f as a one page, Letter size PDF laid out after the FEMA
+// form. Missing fields are printed as "MISSING" in red.
+func Write(w io.Writer, f *determination.Form) error {
+	pdf := fpdf.New("P", "mm", "Letter", "")
+	pdf.SetTitle("Standard Flood Hazard Determination Form", true)
+	pdf.SetCreator("go-nationalflooddata", true)
+	if !f.Preparer.Date.IsZero() {
+		pdf.SetCreationDate(f.Preparer.Date)
+		pdf.SetModificationDate(f.Preparer.Date)
+	}
+	pdf.SetMargins(12, 12, 12)
+	pdf.SetAutoPageBreak(true, 12)
+	pdf.AddPage()
+
+	r := &pdfRenderer{pdf: pdf, missing: make(map[string]bool)}
+	for _, m := range f.Missing() {
+		r.missing[m] = true
+	}
+	r.render(f)
+	return pdf.Output(w)
+}
+
+// pdfRenderer draws the sections of a Form.
+type pdfRenderer struct {
+	pdf     *fpdf.Fpdf
+	missing map[string]bool
+}
+
+func (r *pdfRenderer) render(f *determination.Form) {
+	pdf := r.pdf
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFont("Helvetica", "", 7)
+	pdf.CellFormat(0, 3, "U.S. DEPARTMENT OF HOMELAND SECURITY - Federal Emergency Management Agency", "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 3, "FEMA Form 086-0-32", "", 1, "R", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 13)
+	pdf.CellFormat(0, 8, "STANDARD FLOOD HAZARD DETERMINATION FORM (SFHDF)", "", 1, "C", false, 0, "")
+	pdf.Ln(2)
+
+	r.section("SECTION I - LOAN INFORMATION")
+	r.field(tr, "1. LENDER/SERVICER NAME AND ADDRESS",
+		join(f.Loan.LenderName, f.Loan.LenderAddress), "loan.lender_name", "loan.lender_address")
+	r.field(tr, "2. COLLATERAL (Building/Mobile Home) PROPERTY ADDRESS", f.Loan.PropertyAddress, "loan.property_address")
+	r.field(tr, "3. LENDER/SERVICER ID #", f.Loan.LenderID, "loan.lender_id")
+	r.field(tr, "4. LOAN IDENTIFIER", f.Loan.LoanIdentifier, "loan.loan_identifier")
+	r.field(tr, "5. AMOUNT OF FLOOD INSURANCE REQUIRED", f.Loan.InsuranceAmount, "loan.insurance_amount")
+
+	r.section("SECTION II")
+	r.subsection("A. NATIONAL FLOOD INSURANCE PROGRAM (NFIP) COMMUNITY JURISDICTION")
+	r.field(tr, "1. NFIP Community Name", f.Community.Name, "community.name")
+	r.field(tr, "2. County(ies)", f.Community.County, "community.county")
+	r.field(tr, "3. State", f.Community.State, "community.state")
+	r.field(tr, "4. NFIP Community Number", f.Community.Number, "community.number")
+
+	r.subsection("B. NATIONAL FLOOD INSURANCE PROGRAM (NFIP) DATA AFFECTING BUILDING/MOBILE HOME")
+	r.field(tr, "1. NFIP Map Number or Community-Panel Number", f.Map.PanelNumber, "map.panel_number")
+	r.field(tr, "2. NFIP Map Panel Effective/Revised Date", f.Map.EffectiveDate, "map.effective_date")
+	r.field(tr, "3. LOMA/LOMR", loma(f.Map), "map.loma_date", "map.loma_case_number")
+	r.field(tr, "4. Flood Zone", f.Map.FloodZone, "map.flood_zone")
+	r.field(tr, "5. No NFIP Map", check(f.Map.NoMap), "map.no_map")
+
+	r.subsection("C. FEDERAL FLOOD INSURANCE AVAILABILITY")
+	r.field(tr, "Federal flood insurance is available (community participates in the NFIP)",
+		availability(f.Availability), "availability.available")
+	r.field(tr, "Federal flood insurance is not available because community is not participating in the NFIP",
+		check(f.Availability.NotParticipating), "availability.not_participating")
+	r.field(tr, "Building/Mobile Home is in a Coastal Barrier Resources Area (CBRA) or Otherwise Protected Area (OPA)",
+		check(f.Availability.CBRS), "availability.cbrs")
+
+	r.subsection("D. DETERMINATION")
+	r.field(tr, "IS BUILDING/MOBILE HOME IN SPECIAL FLOOD HAZARD AREA (ZONES CONTAINING THE LETTERS \"A\" OR \"V\")?",
+		yesNo(f.Determination.SFHA), "determination.sfha")
+	if sfha := f.Determination.SFHA; sfha != nil {
+		pdf.SetFont("Helvetica", "", 8)
+		if *sfha && f.Availability.Available != nil && *f.Availability.Available {
+			pdf.MultiCell(0, 4, "Flood insurance is required by the Flood Disaster Protection Act of 1973.", "", "L", false)
+		} else if !*sfha {
+			pdf.MultiCell(0, 4, "Flood insurance is not required by the Flood Disaster Protection Act of 1973; it may still be required by the lender.", "", "L", false)
+		}
+	}
+
+	r.subsection("E. COMMENTS (Optional)")
+	pdf.SetFont("Helvetica", "", 8)
+	pdf.MultiCell(0, 4, tr(comments(f)), "", "L", false)
+
+	r.subsection("F. PREPARER'S INFORMATION")
+	r.field(tr, "NAME, ADDRESS, TELEPHONE NUMBER (If other than Lender)",
+		join(f.Preparer.Name, f.Preparer.Address, f.Preparer.Telephone), "preparer.name", "preparer.address", "preparer.telephone")
+	var date string
+	if !f.Preparer.Date.IsZero() {
+		date = f.Preparer.Date.Format("01/02/2006")
+	}
+	r.field(tr, "DATE OF DETERMINATION", date, "preparer.date")
+}
+
+func (r *pdfRenderer) section(title string) {
+	r.pdf.Ln(1)
+	r.pdf.SetFont("Helvetica", "B", 9)
+	r.pdf.SetFillColor(220, 220, 220)
+	r.pdf.CellFormat(0, 5, title, "1", 1, "C", true, 0, "")
+}
+
+func (r *pdfRenderer) subsection(title string) {
+	r.pdf.Ln(1)
+	r.pdf.SetFont("Helvetica", "B", 8)
+	r.pdf.CellFormat(0, 5, title, "B", 1, "L", false, 0, "")
+}
+
+// field draws a label and its value, followed by missingText in red when
+// any of the fields at paths is missing.
+func (r *pdfRenderer) field(tr func(string) string, label, value string, paths ...string) {
+	pdf := r.pdf
+	pdf.SetFont("Helvetica", "", 7)
+	pdf.MultiCell(0, 3.5, tr(label), "", "L", false)
+
+	pdf.SetFont("Helvetica", "B", 9)
+	for _, path := range paths {
+		if r.missing[path] {
+			pdf.SetTextColor(200, 0, 0)
+			value = strings.TrimSpace(value + " " + missingText)
+			break
+		}
+	}
+	pdf.MultiCell(0, 4.5, tr(value), "", "L", false)
+	pdf.SetTextColor(0, 0, 0)
+}
+
+func join(parts ...string) string {
+	var out []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, ", ")
+}
+
+func check(b bool) string {
+	if b {
+		return "[X]"
+	}
+	return "[ ]"
+}
+
+func yesNo(b *bool) string {
+	switch {
+	case b == nil:
+		return ""
+	case *b:
+		return "[X] YES  [ ] NO"
+	default:
+		return "[ ] YES  [X] NO"
+	}
+}
+
+func loma(m determination.FormMap) string {
+	if !m.LOMA {
+		return "[ ] LOMA/LOMR"
+	}
+	return fmt.Sprintf("[X] LOMA/LOMR  Date: %s  Case No.: %s", m.LOMADate, m.LOMACaseNumber)
+}
+
+func availability(a determination.FormAvailability) string {
+	if a.Available == nil {
+		return ""
+	}
+	if !*a.Available {
+		return check(false)
+	}
+	return fmt.Sprintf("[X]  [%s] Regular Program  [%s] Emergency Program",
+		mark(a.Program == determination.ProgramRegular), mark(a.Program == determination.ProgramEmergency))
+}
+
+func mark(b bool) string {
+	if b {
+		return "X"
+	}
+	return " "
+}
+
+// comments returns f.Comments, or the determination's findings when it
+// has none.
+func comments(f *determination.Form) string {
+	if f.Comments != "" {
+		return f.Comments
+	}
+	var lines []string
+	for _, reason := range f.Determination.Decision.Reasons {
+		lines = append(lines, "- "+reason.Finding)
+	}
+	return strings.Join(lines, "\n")
+}
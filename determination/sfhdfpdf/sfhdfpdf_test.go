@@ -0,0 +1,38 @@
+package sfhdfpdf_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata/determination"
+	"github.com/kmesiab/go-nationalflooddata/determination/sfhdfpdf"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+)
+
+func TestWrite(t *testing.T) {
+	loan := determination.Loan{
+		LenderName:     "First Coastal Bank",
+		LenderAddress:  "1 Main St, West Palm Beach, FL 33401",
+		LoanIdentifier: "LN-1001",
+	}
+	preparer := determination.Preparer{
+		Name:      "Flood Determinations Inc.",
+		Address:   "2 Side St, Tampa, FL 33602",
+		Telephone: "555-0100",
+		Date:      time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+	}
+
+	for _, l := range []determination.Loan{loan, {}} {
+		f := determination.NewForm(nfdtest.FloodData(t), l, preparer, determination.Options{})
+
+		var buf bytes.Buffer
+		require.NoError(t, sfhdfpdf.Write(&buf, f))
+		assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-1.")))
+		assert.Contains(t, buf.String(), "/Count 1")
+		assert.True(t, bytes.HasSuffix(bytes.TrimSpace(buf.Bytes()), []byte("%%EOF")))
+	}
+}
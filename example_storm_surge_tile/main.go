@@ -9,7 +9,11 @@ import (
 )
 
 func main() {
-	svc := nfd.NewService("redacted")
+	svc, err := nfd.NewService("redacted")
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	ctx := context.Background()
 
 	// Retrieve storm surge tile
@@ -0,0 +1,49 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/export"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+)
+
+func TestWriteCSV(t *testing.T) {
+	rows := []export.Row{
+		export.Flatten(nfdtest.FloodData(t)),
+		export.Flatten(&client.Response{Status: "OK"}),
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteCSV(&buf, rows))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	get := func(record []string, name string) string {
+		for i, c := range export.Columns {
+			if c.Name == name {
+				return record[i]
+			}
+		}
+		t.Fatalf("no column %q", name)
+		return ""
+	}
+
+	assert.Equal(t, "id", records[0][0])
+	assert.Equal(t, "AE", get(records[1], "flood_zone"))
+	assert.Equal(t, "true", get(records[1], "sfha"))
+	assert.Equal(t, "6", get(records[1], "bfe"))
+	assert.Equal(t, "0.22", get(records[1], "waterbody_distance_km"))
+	assert.Equal(t, "", get(records[1], "storm_surge_cat1"))
+	assert.Equal(t, "3", get(records[1], "loma_count"))
+
+	assert.Equal(t, "OK", get(records[2], "status"))
+	assert.Equal(t, "", get(records[2], "sfha"))
+}
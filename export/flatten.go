@@ -0,0 +1,263 @@
+// Package export turns flood data responses into formats other tools can
+// load. Flatten reduces a client.Response to one Row per property with a
+// stable set of columns, which WriteCSV and WriteParquet write as tables.
+package export
+
+import (
+	"strconv"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+)
+
+// ColumnType is the type of a column's values.
+type ColumnType int
+
+// Column types. Their values in Row.Values are string, float64, bool and
+// int64 respectively, or nil when missing.
+const (
+	String ColumnType = iota
+	Float
+	Bool
+	Int
+)
+
+// Column is one column of the flattened schema.
+type Column struct {
+	Name string
+	Type ColumnType
+
+	value func(r *Row) interface{}
+}
+
+// Row is a property's flood data, flattened. Pointer and string fields
+// are nil or empty when the response did not include them.
+type Row struct {
+	// ID identifies the row within a batch. It is empty for single lookups.
+	ID string
+
+	RequestID  string
+	Status     string
+	SearchType string
+	Address    string
+	MatchType  string
+	Lat        *float64
+	Lng        *float64
+
+	// The primary flood hazard area is the first the API lists, which is
+	// the one the location falls in.
+	FloodZone   string
+	ZoneSubtype string
+	SFHA        *bool
+	DfirmID     string
+	FloodAreaID string
+
+	FIRMPanel          string
+	PanelEffectiveDate string
+	PanelType          string
+
+	CommunityName             string
+	CommunityParticipating    *bool
+	CommunityCurrentEffective string
+
+	// The BFE is the nearest base flood elevation.
+	BFE           *float64
+	BFEUnit       string
+	BFEDatum      string
+	BFEDistanceKm *float64
+
+	// PropertyElevation is in feet.
+	PropertyElevation   *float64
+	CoastlineDistanceKm *float64
+	WaterbodyName       string
+	WaterbodyDistanceKm *float64
+
+	// StormSurge holds the surge for hurricane categories 1 through 5.
+	StormSurge [5]*float64
+
+	// LOMACount is nil unless the request asked for LOMA data.
+	LOMACount *int64
+}
+
+// Columns is the flattened schema, in output order. Columns are only ever
+// appended, so existing positions and names stay stable.
+var Columns = []Column{
+	{"id", String, func(r *Row) interface{} { return str(r.ID) }},
+	{"request_id", String, func(r *Row) interface{} { return str(r.RequestID) }},
+	{"status", String, func(r *Row) interface{} { return str(r.Status) }},
+	{"search_type", String, func(r *Row) interface{} { return str(r.SearchType) }},
+	{"address", String, func(r *Row) interface{} { return str(r.Address) }},
+	{"match_type", String, func(r *Row) interface{} { return str(r.MatchType) }},
+	{"lat", Float, func(r *Row) interface{} { return float(r.Lat) }},
+	{"lng", Float, func(r *Row) interface{} { return float(r.Lng) }},
+	{"flood_zone", String, func(r *Row) interface{} { return str(r.FloodZone) }},
+	{"zone_subtype", String, func(r *Row) interface{} { return str(r.ZoneSubtype) }},
+	{"sfha", Bool, func(r *Row) interface{} { return boolean(r.SFHA) }},
+	{"dfirm_id", String, func(r *Row) interface{} { return str(r.DfirmID) }},
+	{"fld_ar_id", String, func(r *Row) interface{} { return str(r.FloodAreaID) }},
+	{"firm_panel", String, func(r *Row) interface{} { return str(r.FIRMPanel) }},
+	{"panel_effective_date", String, func(r *Row) interface{} { return str(r.PanelEffectiveDate) }},
+	{"panel_type", String, func(r *Row) interface{} { return str(r.PanelType) }},
+	{"community_name", String, func(r *Row) interface{} { return str(r.CommunityName) }},
+	{"community_participating", Bool, func(r *Row) interface{} { return boolean(r.CommunityParticipating) }},
+	{"community_current_effective", String, func(r *Row) interface{} { return str(r.CommunityCurrentEffective) }},
+	{"bfe", Float, func(r *Row) interface{} { return float(r.BFE) }},
+	{"bfe_unit", String, func(r *Row) interface{} { return str(r.BFEUnit) }},
+	{"bfe_datum", String, func(r *Row) interface{} { return str(r.BFEDatum) }},
+	{"bfe_distance_km", Float, func(r *Row) interface{} { return float(r.BFEDistanceKm) }},
+	{"property_elevation_ft", Float, func(r *Row) interface{} { return float(r.PropertyElevation) }},
+	{"coastline_distance_km", Float, func(r *Row) interface{} { return float(r.CoastlineDistanceKm) }},
+	{"waterbody_name", String, func(r *Row) interface{} { return str(r.WaterbodyName) }},
+	{"waterbody_distance_km", Float, func(r *Row) interface{} { return float(r.WaterbodyDistanceKm) }},
+	{"storm_surge_cat1", Float, func(r *Row) interface{} { return float(r.StormSurge[0]) }},
+	{"storm_surge_cat2", Float, func(r *Row) interface{} { return float(r.StormSurge[1]) }},
+	{"storm_surge_cat3", Float, func(r *Row) interface{} { return float(r.StormSurge[2]) }},
+	{"storm_surge_cat4", Float, func(r *Row) interface{} { return float(r.StormSurge[3]) }},
+	{"storm_surge_cat5", Float, func(r *Row) interface{} { return float(r.StormSurge[4]) }},
+	{"loma_count", Int, func(r *Row) interface{} { return integer(r.LOMACount) }},
+}
+
+// Values returns the row's values in Columns order.
+func (r Row) Values() []interface{} {
+	values := make([]interface{}, len(Columns))
+	for i, c := range Columns {
+		values[i] = c.value(&r)
+	}
+	return values
+}
+
+func str(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func float(f *float64) interface{} {
+	if f == nil {
+		return nil
+	}
+	return *f
+}
+
+func boolean(b *bool) interface{} {
+	if b == nil {
+		return nil
+	}
+	return *b
+}
+
+func integer(n *int64) interface{} {
+	if n == nil {
+		return nil
+	}
+	return *n
+}
+
+// Flatten reduces resp to a Row.
+func Flatten(resp *client.Response) Row {
+	row := Row{
+		RequestID:  resp.RequestID,
+		Status:     resp.Status,
+		SearchType: resp.Request.Searchtype,
+		Address:    resp.Request.Address,
+		Lat:        parseFloat(resp.Coords.Lat),
+		Lng:        parseFloat(resp.Coords.Lng),
+	}
+	if resp.MatchType != nil {
+		row.MatchType = *resp.MatchType
+	}
+
+	result := resp.Result
+
+	if len(result.FloodFldHazAr) > 0 {
+		h := result.FloodFldHazAr[0]
+		sfha := h.SfhaTf == "T"
+		row.FloodZone = h.FldZone
+		row.SFHA = &sfha
+		row.DfirmID = h.DfirmID
+		row.FloodAreaID = h.FldArID
+		if h.ZoneSubty != nil {
+			row.ZoneSubtype = *h.ZoneSubty
+		}
+	}
+
+	if len(result.FloodFirmPan) > 0 {
+		p := result.FloodFirmPan[0]
+		row.FIRMPanel = p.FirmPan
+		row.PanelEffectiveDate = p.EffDate
+		row.PanelType = p.PanelTyp
+	}
+
+	if c := result.Community; c != nil && c.CommName != "" {
+		participating := c.CommPart
+		row.CommunityName = c.CommName
+		row.CommunityParticipating = &participating
+		row.CommunityCurrentEffective = c.Curreff
+	}
+
+	if e := result.Elevation; e != nil {
+		if _, ok := e.PropertyHeight(); ok {
+			elevation := e.PropertyElevation
+			row.PropertyElevation = &elevation
+		}
+
+		if len(e.FloodBaseFloodElevation) > 0 {
+			nearest := e.FloodBaseFloodElevation[0]
+			for _, b := range e.FloodBaseFloodElevation[1:] {
+				if b.DistKm < nearest.DistKm {
+					nearest = b
+				}
+			}
+			dist := nearest.DistKm
+			row.BFE = parseFloat(nearest.Elevation)
+			row.BFEUnit = nearest.LenUnit
+			row.BFEDatum = nearest.VDatum
+			row.BFEDistanceKm = &dist
+		}
+
+		for i, c := range e.Coastline {
+			if i == 0 || c.DistKm < *row.CoastlineDistanceKm {
+				dist := c.DistKm
+				row.CoastlineDistanceKm = &dist
+			}
+		}
+
+		for i, w := range e.Waterbody {
+			if i == 0 || w.DistKm < *row.WaterbodyDistanceKm {
+				dist := w.DistKm
+				row.WaterbodyName = w.Name
+				row.WaterbodyDistanceKm = &dist
+			}
+		}
+
+		s := e.StormSurge
+		row.StormSurge = [5]*float64{s.Category1, s.Category2, s.Category3, s.Category4, s.Category5}
+	}
+
+	if result.Loma != nil {
+		n := int64(len(*result.Loma))
+		row.LOMACount = &n
+	}
+
+	return row
+}
+
+// FlattenBatch flattens batch results, setting each row's ID.
+func FlattenBatch(results []client.BatchResult) []Row {
+	rows := make([]Row, len(results))
+	for i := range results {
+		rows[i] = Flatten(&results[i].Response)
+		rows[i].ID = results[i].ID
+	}
+	return rows
+}
+
+// parseFloat parses the numeric strings the API uses for coordinates and
+// elevations, returning nil for anything else, such as "None".
+func parseFloat(s string) *float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
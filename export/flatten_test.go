@@ -0,0 +1,103 @@
+package export_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/export"
+	"github.com/kmesiab/go-nationalflooddata/models"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+)
+
+func TestFlatten(t *testing.T) {
+	row := export.Flatten(nfdtest.FloodData(t))
+
+	assert.Equal(t, "cac6b9f8-84c9-4ba2-afdb-481d3038f29e", row.RequestID)
+	assert.Equal(t, "addressparcel", row.SearchType)
+	assert.InDelta(t, 26.7032, *row.Lat, 1e-4)
+	assert.InDelta(t, -80.0424, *row.Lng, 1e-4)
+
+	assert.Equal(t, "AE", row.FloodZone)
+	assert.Equal(t, "COASTAL FLOODPLAIN", row.ZoneSubtype)
+	assert.True(t, *row.SFHA)
+	assert.Equal(t, "12099C", row.DfirmID)
+	assert.Equal(t, "12099C_31710", row.FloodAreaID)
+
+	assert.Equal(t, "12099C0583G", row.FIRMPanel)
+	assert.Equal(t, "2024-12-20", row.PanelEffectiveDate)
+
+	assert.Equal(t, "PALM BEACH, TOWN OF", row.CommunityName)
+	assert.True(t, *row.CommunityParticipating)
+	assert.Equal(t, "100517", row.CommunityCurrentEffective)
+
+	assert.Equal(t, 6.0, *row.BFE)
+	assert.Equal(t, "Feet", row.BFEUnit)
+	assert.Equal(t, "NAVD88", row.BFEDatum)
+	assert.Equal(t, 0.0, *row.BFEDistanceKm)
+
+	assert.Equal(t, 6.1, *row.PropertyElevation)
+	assert.Equal(t, 0.48, *row.CoastlineDistanceKm)
+	assert.Equal(t, "Lake Worth Lagoon", row.WaterbodyName)
+	assert.Equal(t, 0.22, *row.WaterbodyDistanceKm)
+
+	assert.Nil(t, row.StormSurge[0])
+	assert.Equal(t, 3.9, *row.StormSurge[4])
+	assert.EqualValues(t, 3, *row.LOMACount)
+}
+
+func TestFlatten_MissingPropertyElevationIsNull(t *testing.T) {
+	resp := nfdtest.FloodData(t)
+	resp.Result.Elevation.PropertyElevation = models.MissingElevation
+
+	row := export.Flatten(resp)
+	assert.Nil(t, row.PropertyElevation)
+	assert.Equal(t, 0.48, *row.CoastlineDistanceKm)
+}
+
+func TestFlatten_MissingSectionsAreNull(t *testing.T) {
+	row := export.Flatten(&client.Response{Status: "OK"})
+
+	values := row.Values()
+	require.Len(t, values, len(export.Columns))
+	for i, c := range export.Columns {
+		if c.Name == "status" {
+			assert.Equal(t, "OK", values[i])
+			continue
+		}
+		assert.Nil(t, values[i], c.Name)
+	}
+}
+
+func TestFlatten_ValuesMatchColumnTypes(t *testing.T) {
+	for i, v := range export.Flatten(nfdtest.FloodData(t)).Values() {
+		c := export.Columns[i]
+		if v == nil {
+			continue
+		}
+		switch c.Type {
+		case export.String:
+			assert.IsType(t, "", v, c.Name)
+		case export.Float:
+			assert.IsType(t, 0.0, v, c.Name)
+		case export.Bool:
+			assert.IsType(t, false, v, c.Name)
+		case export.Int:
+			assert.IsType(t, int64(0), v, c.Name)
+		}
+	}
+}
+
+func TestFlattenBatch(t *testing.T) {
+	rows := export.FlattenBatch([]client.BatchResult{
+		{ID: "a", Response: client.Response{Status: "OK"}},
+		{ID: "b", Response: *nfdtest.FloodData(t)},
+	})
+
+	require.Len(t, rows, 2)
+	assert.Equal(t, "a", rows[0].ID)
+	assert.Equal(t, "b", rows[1].ID)
+	assert.Equal(t, "AE", rows[1].FloodZone)
+}
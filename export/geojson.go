@@ -0,0 +1,167 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+)
+
+// FeatureCollection is a GeoJSON FeatureCollection (RFC 7946).
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Feature is a GeoJSON Feature. Geometry is null for features without a
+// location.
+type Feature struct {
+	Type       string                 `json:"type"`
+	Geometry   json.RawMessage        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// Feature layers, recorded in each feature's "layer" property.
+const (
+	LayerFloodRegion = "flood_region"
+	LayerBFE         = "bfe"
+	LayerLookup      = "lookup"
+)
+
+// FloodMapFeatures converts the flood regions and BFE lines of content
+// into features. Every attribute becomes a property, alongside a "layer"
+// property and simplestyle hints ("fill", "stroke", ...) keyed on the
+// flood zone, which geojson.io, Mapbox and QGIS plugins understand.
+func FloodMapFeatures(content *client.FloodMapContent) (*FeatureCollection, error) {
+	fc := newFeatureCollection()
+
+	for _, r := range content.Result.FloodRegions {
+		geometry, err := parseGeometry(r.GeoJSON)
+		if err != nil {
+			return nil, fmt.Errorf("flood region %s: %w", r.FldArID, err)
+		}
+
+		s := zoneStyle(r.FldZone, r.ZoneSubty)
+		fc.Features = append(fc.Features, Feature{
+			Type:     "Feature",
+			Geometry: geometry,
+			Properties: map[string]interface{}{
+				"layer":        LayerFloodRegion,
+				"fld_ar_id":    r.FldArID,
+				"fld_zone":     r.FldZone,
+				"zone_subty":   r.ZoneSubty,
+				"dfirm_id":     r.DfirmID,
+				"distkm":       r.DistKm,
+				"ogc_fid":      r.OgcFID,
+				"fill":         s.Fill,
+				"fill-opacity": s.Opacity,
+				"stroke":       s.Stroke,
+				"stroke-width": 1,
+			},
+		})
+	}
+
+	for _, b := range content.Result.BFEList {
+		geometry, err := parseGeometry(b.GeoJSON)
+		if err != nil {
+			return nil, fmt.Errorf("BFE line %s: %w", b.BfeLnID, err)
+		}
+
+		fc.Features = append(fc.Features, Feature{
+			Type:     "Feature",
+			Geometry: geometry,
+			Properties: map[string]interface{}{
+				"layer":        LayerBFE,
+				"bfe_ln_id":    b.BfeLnID,
+				"elev":         b.Elev,
+				"len_unit":     b.LenUnit,
+				"v_datum":      b.VDatum,
+				"dfirm_id":     b.DfirmID,
+				"distkm":       b.DistKm,
+				"version_id":   b.VersionID,
+				"source_cit":   b.SourceCit,
+				"ogc_fid":      b.OgcFID,
+				"stroke":       bfeStroke,
+				"stroke-width": 2,
+			},
+		})
+	}
+
+	return fc, nil
+}
+
+// LookupFeatures converts flood data lookups into point features at their
+// coordinates. Properties are the flattened columns of each lookup, so
+// every feature has the same attributes.
+func LookupFeatures(resps []*client.Response) *FeatureCollection {
+	rows := make([]Row, len(resps))
+	for i, resp := range resps {
+		rows[i] = Flatten(resp)
+	}
+	return pointFeatures(rows)
+}
+
+// BatchFeatures is LookupFeatures for batch results, with each feature's
+// "id" property set to its request ID.
+func BatchFeatures(results []client.BatchResult) *FeatureCollection {
+	return pointFeatures(FlattenBatch(results))
+}
+
+func pointFeatures(rows []Row) *FeatureCollection {
+	fc := newFeatureCollection()
+
+	for _, row := range rows {
+		properties := map[string]interface{}{"layer": LayerLookup}
+		for i, v := range row.Values() {
+			properties[Columns[i].Name] = v
+		}
+		if row.FloodZone != "" {
+			properties["marker-color"] = zoneStyle(row.FloodZone, row.ZoneSubtype).Fill
+		}
+
+		var geometry json.RawMessage
+		if row.Lat != nil && row.Lng != nil {
+			geometry, _ = json.Marshal(map[string]interface{}{
+				"type":        "Point",
+				"coordinates": []float64{*row.Lng, *row.Lat},
+			})
+		}
+
+		fc.Features = append(fc.Features, Feature{
+			Type:       "Feature",
+			Geometry:   geometry,
+			Properties: properties,
+		})
+	}
+
+	return fc
+}
+
+func newFeatureCollection() *FeatureCollection {
+	return &FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+}
+
+// parseGeometry checks that s is a GeoJSON geometry. An empty s is a null
+// geometry.
+func parseGeometry(s string) (json.RawMessage, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var g struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal([]byte(s), &g); err != nil {
+		return nil, fmt.Errorf("parsing geometry: %w", err)
+	}
+	if g.Type == "" {
+		return nil, fmt.Errorf("parsing geometry: missing type")
+	}
+	return json.RawMessage(s), nil
+}
+
+// WriteGeoJSON writes fc as GeoJSON.
+func WriteGeoJSON(w io.Writer, fc *FeatureCollection) error {
+	return json.NewEncoder(w).Encode(fc)
+}
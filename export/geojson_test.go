@@ -0,0 +1,143 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/export"
+	"github.com/kmesiab/go-nationalflooddata/models"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+)
+
+// decoded is a FeatureCollection as a GeoJSON consumer sees it.
+type decoded struct {
+	Type     string `json:"type"`
+	Features []struct {
+		Type     string `json:"type"`
+		Geometry *struct {
+			Type        string          `json:"type"`
+			Coordinates json.RawMessage `json:"coordinates"`
+		} `json:"geometry"`
+		Properties map[string]interface{} `json:"properties"`
+	} `json:"features"`
+}
+
+func writeAndDecode(t *testing.T, fc *export.FeatureCollection) decoded {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteGeoJSON(&buf, fc))
+
+	var d decoded
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &d))
+	assert.Equal(t, "FeatureCollection", d.Type)
+	return d
+}
+
+func TestFloodMapFeatures(t *testing.T) {
+	fc, err := export.FloodMapFeatures(nfdtest.FloodMapRaw(t))
+	require.NoError(t, err)
+
+	d := writeAndDecode(t, fc)
+	require.Len(t, d.Features, 5)
+
+	region := d.Features[0]
+	assert.Equal(t, "Feature", region.Type)
+	assert.Equal(t, "Polygon", region.Geometry.Type)
+	assert.Equal(t, map[string]interface{}{
+		"layer":        export.LayerFloodRegion,
+		"fld_ar_id":    "12099C_31710",
+		"fld_zone":     "AE",
+		"zone_subty":   "COASTAL FLOODPLAIN",
+		"dfirm_id":     "12099C",
+		"distkm":       0.0,
+		"ogc_fid":      31710.0,
+		"fill":         "#2171b5",
+		"fill-opacity": 0.4,
+		"stroke":       "#08519c",
+		"stroke-width": 1.0,
+	}, region.Properties)
+
+	// Zones are styled by hazard.
+	assert.NotEqual(t, region.Properties["fill"], d.Features[1].Properties["fill"], "VE")
+	assert.NotEqual(t, region.Properties["fill"], d.Features[2].Properties["fill"], "X")
+
+	bfe := d.Features[3]
+	assert.Equal(t, "LineString", bfe.Geometry.Type)
+	assert.Equal(t, export.LayerBFE, bfe.Properties["layer"])
+	assert.Equal(t, "12099C_4402", bfe.Properties["bfe_ln_id"])
+	assert.Equal(t, 6.0, bfe.Properties["elev"])
+	assert.Equal(t, "NAVD88", bfe.Properties["v_datum"])
+}
+
+func TestFloodMapFeatures_InvalidGeometry(t *testing.T) {
+	content := &client.FloodMapContent{Result: client.FloodMapContentResult{
+		FloodRegions: []models.FloodRegion{{FldArID: "12099C_1", GeoJSON: "{"}},
+	}}
+
+	_, err := export.FloodMapFeatures(content)
+	assert.ErrorContains(t, err, "flood region 12099C_1")
+}
+
+func TestFloodMapFeatures_EmptyGeometryIsNull(t *testing.T) {
+	content := &client.FloodMapContent{Result: client.FloodMapContentResult{
+		BFEList: []models.BFEListItem{{BfeLnID: "12099C_1"}},
+	}}
+
+	fc, err := export.FloodMapFeatures(content)
+	require.NoError(t, err)
+
+	d := writeAndDecode(t, fc)
+	require.Len(t, d.Features, 1)
+	assert.Nil(t, d.Features[0].Geometry)
+}
+
+func TestFloodMapFeatures_NoContentIsEmptyCollection(t *testing.T) {
+	fc, err := export.FloodMapFeatures(&client.FloodMapContent{})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteGeoJSON(&buf, fc))
+	assert.JSONEq(t, `{"type":"FeatureCollection","features":[]}`, buf.String())
+}
+
+func TestLookupFeatures(t *testing.T) {
+	d := writeAndDecode(t, export.LookupFeatures([]*client.Response{
+		nfdtest.FloodData(t),
+		{Status: "OK"},
+	}))
+	require.Len(t, d.Features, 2)
+
+	point := d.Features[0]
+	require.NotNil(t, point.Geometry)
+	assert.Equal(t, "Point", point.Geometry.Type)
+	var coords []float64
+	require.NoError(t, json.Unmarshal(point.Geometry.Coordinates, &coords))
+	require.Len(t, coords, 2)
+	assert.InDelta(t, -80.0424, coords[0], 1e-4, "longitude first")
+	assert.InDelta(t, 26.7032, coords[1], 1e-4)
+
+	assert.Equal(t, export.LayerLookup, point.Properties["layer"])
+	assert.Equal(t, "AE", point.Properties["flood_zone"])
+	assert.Equal(t, "#2171b5", point.Properties["marker-color"])
+	for _, c := range export.Columns {
+		assert.Contains(t, point.Properties, c.Name)
+	}
+
+	assert.Nil(t, d.Features[1].Geometry, "no coordinates")
+	assert.Nil(t, d.Features[1].Properties["flood_zone"])
+}
+
+func TestBatchFeatures(t *testing.T) {
+	d := writeAndDecode(t, export.BatchFeatures([]client.BatchResult{
+		{ID: "loan-1", Response: *nfdtest.FloodData(t)},
+	}))
+
+	require.Len(t, d.Features, 1)
+	assert.Equal(t, "loan-1", d.Features[0].Properties["id"])
+}
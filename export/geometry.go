@@ -0,0 +1,211 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// geoJSONGeometry is a GeoJSON geometry with undecoded coordinates.
+type geoJSONGeometry struct {
+	Type        string            `json:"type"`
+	Coordinates json.RawMessage   `json:"coordinates"`
+	Geometries  []geoJSONGeometry `json:"geometries"`
+}
+
+// point is an x (longitude), y (latitude) position.
+type point [2]float64
+
+// Flood regions are exported as multipolygons and BFE lines as
+// multilinestrings, so every feature of a layer has the same type.
+type (
+	ring            []point
+	polygon         []ring
+	multiPolygon    []polygon
+	lineString      []point
+	multiLineString []lineString
+)
+
+// parseMultiPolygon parses a Polygon or MultiPolygon. An empty s is nil.
+func parseMultiPolygon(s string) (multiPolygon, error) {
+	g, err := parseGeoJSON(s)
+	if g == nil || err != nil {
+		return nil, err
+	}
+
+	var coords [][][][]float64
+	switch g.Type {
+	case "Polygon":
+		var p [][][]float64
+		err = json.Unmarshal(g.Coordinates, &p)
+		coords = [][][][]float64{p}
+	case "MultiPolygon":
+		err = json.Unmarshal(g.Coordinates, &coords)
+	default:
+		return nil, fmt.Errorf("geometry type %q is not polygonal", g.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s coordinates: %w", g.Type, err)
+	}
+
+	mp := make(multiPolygon, len(coords))
+	for i, p := range coords {
+		mp[i] = make(polygon, len(p))
+		for j, r := range p {
+			if mp[i][j], err = points(r); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return mp, nil
+}
+
+// parseMultiLineString parses a LineString or MultiLineString. An empty s
+// is nil.
+func parseMultiLineString(s string) (multiLineString, error) {
+	g, err := parseGeoJSON(s)
+	if g == nil || err != nil {
+		return nil, err
+	}
+
+	var coords [][][]float64
+	switch g.Type {
+	case "LineString":
+		var l [][]float64
+		err = json.Unmarshal(g.Coordinates, &l)
+		coords = [][][]float64{l}
+	case "MultiLineString":
+		err = json.Unmarshal(g.Coordinates, &coords)
+	default:
+		return nil, fmt.Errorf("geometry type %q is not linear", g.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s coordinates: %w", g.Type, err)
+	}
+
+	ml := make(multiLineString, len(coords))
+	for i, l := range coords {
+		if ml[i], err = points(l); err != nil {
+			return nil, err
+		}
+	}
+	return ml, nil
+}
+
+func parseGeoJSON(s string) (*geoJSONGeometry, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var g geoJSONGeometry
+	if err := json.Unmarshal([]byte(s), &g); err != nil {
+		return nil, fmt.Errorf("parsing geometry: %w", err)
+	}
+	return &g, nil
+}
+
+func points(positions [][]float64) ([]point, error) {
+	out := make([]point, len(positions))
+	for i, p := range positions {
+		if len(p) < 2 {
+			return nil, fmt.Errorf("position has %d coordinates, want at least 2", len(p))
+		}
+		out[i] = point{p[0], p[1]}
+	}
+	return out, nil
+}
+
+// envelope is a bounding box.
+type envelope struct {
+	MinX, MinY, MaxX, MaxY float64
+}
+
+func emptyEnvelope() envelope {
+	return envelope{MinX: math.Inf(1), MinY: math.Inf(1), MaxX: math.Inf(-1), MaxY: math.Inf(-1)}
+}
+
+func (e envelope) empty() bool { return e.MinX > e.MaxX }
+
+func (e *envelope) add(ps ...point) {
+	for _, p := range ps {
+		e.MinX = math.Min(e.MinX, p[0])
+		e.MinY = math.Min(e.MinY, p[1])
+		e.MaxX = math.Max(e.MaxX, p[0])
+		e.MaxY = math.Max(e.MaxY, p[1])
+	}
+}
+
+func (e *envelope) extend(o envelope) {
+	if !o.empty() {
+		e.add(point{o.MinX, o.MinY}, point{o.MaxX, o.MaxY})
+	}
+}
+
+func (mp multiPolygon) envelope() envelope {
+	e := emptyEnvelope()
+	for _, p := range mp {
+		for _, r := range p {
+			e.add(r...)
+		}
+	}
+	return e
+}
+
+func (ml multiLineString) envelope() envelope {
+	e := emptyEnvelope()
+	for _, l := range ml {
+		e.add(l...)
+	}
+	return e
+}
+
+// WKB geometry types.
+const (
+	wkbLineString      = 2
+	wkbPolygon         = 3
+	wkbMultiLineString = 5
+	wkbMultiPolygon    = 6
+)
+
+// wkb encodes mp as little-endian Well-Known Binary.
+func (mp multiPolygon) wkb() []byte {
+	var b bytes.Buffer
+	wkbHeader(&b, wkbMultiPolygon, len(mp))
+	for _, p := range mp {
+		wkbHeader(&b, wkbPolygon, len(p))
+		for _, r := range p {
+			wkbPoints(&b, r)
+		}
+	}
+	return b.Bytes()
+}
+
+// wkb encodes ml as little-endian Well-Known Binary.
+func (ml multiLineString) wkb() []byte {
+	var b bytes.Buffer
+	wkbHeader(&b, wkbMultiLineString, len(ml))
+	for _, l := range ml {
+		wkbHeader(&b, wkbLineString, 0)
+		wkbPoints(&b, l)
+	}
+	return b.Bytes()
+}
+
+// wkbHeader writes a geometry's byte order and type, followed by n if it
+// is a count of parts. Linestrings pass 0 and write their points next.
+func wkbHeader(b *bytes.Buffer, typ uint32, n int) {
+	b.WriteByte(1)
+	_ = binary.Write(b, binary.LittleEndian, typ)
+	if typ != wkbLineString {
+		_ = binary.Write(b, binary.LittleEndian, uint32(n))
+	}
+}
+
+func wkbPoints(b *bytes.Buffer, ps []point) {
+	_ = binary.Write(b, binary.LittleEndian, uint32(len(ps)))
+	for _, p := range ps {
+		_ = binary.Write(b, binary.LittleEndian, p[0])
+		_ = binary.Write(b, binary.LittleEndian, p[1])
+	}
+}
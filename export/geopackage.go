@@ -0,0 +1,363 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/internal/sqlite"
+)
+
+// GeoPackage header values: the "GPKG" application id and version 1.3.
+const (
+	gpkgApplicationID = 0x47504b47
+	gpkgUserVersion   = 10300
+)
+
+// srsWGS84 is the spatial reference system of exported geometries.
+const srsWGS84 = 4326
+
+const wgs84WKT = `GEOGCS["WGS 84",DATUM["WGS_1984",SPHEROID["WGS 84",6378137,298.257223563,AUTHORITY["EPSG","7030"]],AUTHORITY["EPSG","6326"]],PRIMEM["Greenwich",0,AUTHORITY["EPSG","8901"]],UNIT["degree",0.0174532925199433,AUTHORITY["EPSG","9122"]],AXIS["Latitude",NORTH],AXIS["Longitude",EAST],AUTHORITY["EPSG","4326"]]`
+
+// WriteGeoPackage writes the flood regions and BFE lines of content as an
+// OGC GeoPackage with two feature tables, flood_regions and bfe_lines,
+// each with an R-tree spatial index. Geometries are in WGS 84.
+func WriteGeoPackage(w io.Writer, content *client.FloodMapContent) error {
+	layers, err := gisLayers(content)
+	if err != nil {
+		return err
+	}
+
+	db := &sqlite.Database{
+		ApplicationID: gpkgApplicationID,
+		UserVersion:   gpkgUserVersion,
+		Tables:        gpkgMetadata(layers, time.Now()),
+	}
+
+	sequence := sqlite.Table{Name: "sqlite_sequence", SQL: "CREATE TABLE sqlite_sequence(name,seq)"}
+	for _, l := range layers {
+		db.Tables = append(db.Tables, gpkgFeatureTable(l))
+
+		if n := len(l.features); n > 0 {
+			sequence.Rows = append(sequence.Rows, sqlite.Row{
+				ID:     int64(len(sequence.Rows) + 1),
+				Values: []interface{}{l.name, int64(n)},
+			})
+		}
+	}
+	db.Tables = append(db.Tables, sequence)
+
+	for _, l := range layers {
+		rtree := "rtree_" + l.name + "_geom"
+		db.Tables = append(db.Tables, rtreeTables(rtree, rtreeEntries(l))...)
+		db.Objects = append(db.Objects, sqlite.Object{
+			Type: "table",
+			Name: rtree,
+			SQL:  "CREATE VIRTUAL TABLE " + rtree + " USING rtree(id, minx, maxx, miny, maxy)",
+		})
+		db.Objects = append(db.Objects, rtreeTriggers(l.name, "geom", "fid")...)
+	}
+
+	return sqlite.Write(w, db)
+}
+
+// gpkgMetadata returns the GeoPackage metadata tables describing layers.
+func gpkgMetadata(layers []*layer, now time.Time) []sqlite.Table {
+	srs := sqlite.Table{
+		Name: "gpkg_spatial_ref_sys",
+		SQL: `CREATE TABLE gpkg_spatial_ref_sys (srs_name TEXT NOT NULL, srs_id INTEGER PRIMARY KEY, ` +
+			`organization TEXT NOT NULL, organization_coordsys_id INTEGER NOT NULL, definition TEXT NOT NULL, description TEXT)`,
+		Rows: []sqlite.Row{
+			{ID: -1, Values: []interface{}{"Undefined cartesian SRS", nil, "NONE", -1, "undefined", "undefined cartesian coordinate reference system"}},
+			{ID: 0, Values: []interface{}{"Undefined geographic SRS", nil, "NONE", 0, "undefined", "undefined geographic coordinate reference system"}},
+			{ID: srsWGS84, Values: []interface{}{"WGS 84 geodetic", nil, "EPSG", srsWGS84, wgs84WKT, "longitude/latitude coordinates in decimal degrees on the WGS 84 spheroid"}},
+		},
+	}
+
+	contents := sqlite.Table{
+		Name: "gpkg_contents",
+		SQL: `CREATE TABLE gpkg_contents (table_name TEXT NOT NULL PRIMARY KEY, data_type TEXT NOT NULL, ` +
+			`identifier TEXT UNIQUE, description TEXT DEFAULT '', ` +
+			`last_change DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ','now')), ` +
+			`min_x DOUBLE, min_y DOUBLE, max_x DOUBLE, max_y DOUBLE, srs_id INTEGER, ` +
+			`CONSTRAINT fk_gc_r_srs_id FOREIGN KEY (srs_id) REFERENCES gpkg_spatial_ref_sys(srs_id))`,
+		Unique: [][]int{{0}, {2}},
+	}
+
+	geometryColumns := sqlite.Table{
+		Name: "gpkg_geometry_columns",
+		SQL: `CREATE TABLE gpkg_geometry_columns (table_name TEXT NOT NULL, column_name TEXT NOT NULL, ` +
+			`geometry_type_name TEXT NOT NULL, srs_id INTEGER NOT NULL, z TINYINT NOT NULL, m TINYINT NOT NULL, ` +
+			`CONSTRAINT pk_geom_cols PRIMARY KEY (table_name, column_name), ` +
+			`CONSTRAINT uk_gc_table_name UNIQUE (table_name), ` +
+			`CONSTRAINT fk_gc_tn FOREIGN KEY (table_name) REFERENCES gpkg_contents(table_name), ` +
+			`CONSTRAINT fk_gc_srs FOREIGN KEY (srs_id) REFERENCES gpkg_spatial_ref_sys (srs_id))`,
+		Unique: [][]int{{0, 1}, {0}},
+	}
+
+	extensions := sqlite.Table{
+		Name: "gpkg_extensions",
+		SQL: `CREATE TABLE gpkg_extensions (table_name TEXT, column_name TEXT, extension_name TEXT NOT NULL, ` +
+			`definition TEXT NOT NULL, scope TEXT NOT NULL, ` +
+			`CONSTRAINT ge_tce UNIQUE (table_name, column_name, extension_name))`,
+		Unique: [][]int{{0, 1, 2}},
+	}
+
+	lastChange := now.UTC().Format("2006-01-02T15:04:05.000Z")
+	for i, l := range layers {
+		id := int64(i + 1)
+
+		var minX, minY, maxX, maxY interface{}
+		if e := l.envelope(); !e.empty() {
+			minX, minY, maxX, maxY = e.MinX, e.MinY, e.MaxX, e.MaxY
+		}
+		contents.Rows = append(contents.Rows, sqlite.Row{ID: id, Values: []interface{}{
+			l.name, "features", l.name, "", lastChange, minX, minY, maxX, maxY, srsWGS84,
+		}})
+
+		geometryColumns.Rows = append(geometryColumns.Rows, sqlite.Row{ID: id, Values: []interface{}{
+			l.name, "geom", l.geometryType, srsWGS84, 0, 0,
+		}})
+
+		extensions.Rows = append(extensions.Rows, sqlite.Row{ID: id, Values: []interface{}{
+			l.name, "geom", "gpkg_rtree_index", "http://www.geopackage.org/spec120/#extension_rtree", "write-only",
+		}})
+	}
+
+	return []sqlite.Table{srs, contents, geometryColumns, extensions}
+}
+
+var gpkgColumnTypes = map[ColumnType]string{
+	String: "TEXT",
+	Float:  "DOUBLE",
+	Bool:   "BOOLEAN",
+	Int:    "INTEGER",
+}
+
+func gpkgFeatureTable(l *layer) sqlite.Table {
+	columns := []string{`"fid" INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL`, `"geom" ` + l.geometryType}
+	for _, f := range l.fields {
+		columns = append(columns, fmt.Sprintf("%q %s", f.Name, gpkgColumnTypes[f.Type]))
+	}
+
+	t := sqlite.Table{
+		Name: l.name,
+		SQL:  fmt.Sprintf("CREATE TABLE %q (%s)", l.name, strings.Join(columns, ", ")),
+	}
+	for i, f := range l.features {
+		var geom interface{}
+		if f.geometry != nil {
+			geom = gpkgGeometry(f.geometry)
+		}
+		t.Rows = append(t.Rows, sqlite.Row{
+			ID:     int64(i + 1),
+			Values: append([]interface{}{nil, geom}, f.values...),
+		})
+	}
+	return t
+}
+
+// gpkgGeometry encodes g as a GeoPackage geometry blob: a header with the
+// SRS and envelope followed by WKB.
+func gpkgGeometry(g geometry) []byte {
+	var b bytes.Buffer
+	b.WriteString("GP")
+	b.WriteByte(0) // version 1
+
+	// Little-endian, with a [minx, maxx, miny, maxy] envelope.
+	b.WriteByte(0x01 | 1<<1)
+	_ = binary.Write(&b, binary.LittleEndian, int32(srsWGS84))
+
+	e := g.envelope()
+	for _, v := range []float64{e.MinX, e.MaxX, e.MinY, e.MaxY} {
+		_ = binary.Write(&b, binary.LittleEndian, v)
+	}
+
+	b.Write(g.wkb())
+	return b.Bytes()
+}
+
+// rtreeTriggers are the triggers the GeoPackage R-tree extension requires
+// to keep the index in step with table t's geometry column c, keyed by i.
+func rtreeTriggers(t, c, i string) []sqlite.Object {
+	r := "rtree_" + t + "_" + c
+	values := fmt.Sprintf("VALUES (NEW.%[1]s, ST_MinX(NEW.%[2]s), ST_MaxX(NEW.%[2]s), ST_MinY(NEW.%[2]s), ST_MaxY(NEW.%[2]s))", i, c)
+
+	triggers := []struct{ suffix, body string }{
+		{"insert", fmt.Sprintf("AFTER INSERT ON %[1]s WHEN (new.%[2]s NOT NULL AND NOT ST_IsEmpty(NEW.%[2]s)) "+
+			"BEGIN INSERT OR REPLACE INTO %[3]s %[4]s; END", t, c, r, values)},
+		{"update1", fmt.Sprintf("AFTER UPDATE OF %[2]s ON %[1]s WHEN OLD.%[5]s = NEW.%[5]s AND (NEW.%[2]s NOTNULL AND NOT ST_IsEmpty(NEW.%[2]s)) "+
+			"BEGIN INSERT OR REPLACE INTO %[3]s %[4]s; END", t, c, r, values, i)},
+		{"update2", fmt.Sprintf("AFTER UPDATE OF %[2]s ON %[1]s WHEN OLD.%[4]s = NEW.%[4]s AND (NEW.%[2]s ISNULL OR ST_IsEmpty(NEW.%[2]s)) "+
+			"BEGIN DELETE FROM %[3]s WHERE id = OLD.%[4]s; END", t, c, r, i)},
+		{"update3", fmt.Sprintf("AFTER UPDATE ON %[1]s WHEN OLD.%[5]s != NEW.%[5]s AND (NEW.%[2]s NOTNULL AND NOT ST_IsEmpty(NEW.%[2]s)) "+
+			"BEGIN DELETE FROM %[3]s WHERE id = OLD.%[5]s; INSERT OR REPLACE INTO %[3]s %[4]s; END", t, c, r, values, i)},
+		{"update4", fmt.Sprintf("AFTER UPDATE ON %[1]s WHEN OLD.%[4]s != NEW.%[4]s AND (NEW.%[2]s ISNULL OR ST_IsEmpty(NEW.%[2]s)) "+
+			"BEGIN DELETE FROM %[3]s WHERE id IN (OLD.%[4]s, NEW.%[4]s); END", t, c, r, i)},
+		{"delete", fmt.Sprintf("AFTER DELETE ON %[1]s WHEN old.%[2]s NOT NULL "+
+			"BEGIN DELETE FROM %[3]s WHERE id = OLD.%[4]s; END", t, c, r, i)},
+	}
+
+	objects := make([]sqlite.Object, len(triggers))
+	for n, tr := range triggers {
+		name := r + "_" + tr.suffix
+		objects[n] = sqlite.Object{
+			Type:  "trigger",
+			Name:  name,
+			Table: t,
+			SQL:   "CREATE TRIGGER " + name + " " + tr.body,
+		}
+	}
+	return objects
+}
+
+// R-tree node layout, matching what SQLite's rtree module creates for a
+// two-dimensional index on 4096-byte pages.
+const (
+	rtreeNodeSize = 1228
+	rtreeMaxCells = 51
+	rtreeCellSize = 24
+)
+
+// rtreeEntry is an indexed rowid and its bounding box, stored as 32-bit
+// floats rounded outwards like SQLite does.
+type rtreeEntry struct {
+	id  int64
+	box [4]float32 // minx, maxx, miny, maxy
+}
+
+func rtreeEntries(l *layer) []rtreeEntry {
+	var entries []rtreeEntry
+	for i, f := range l.features {
+		if f.geometry == nil {
+			continue
+		}
+		e := f.geometry.envelope()
+		entries = append(entries, rtreeEntry{
+			id:  int64(i + 1),
+			box: [4]float32{roundDown(e.MinX), roundUp(e.MaxX), roundDown(e.MinY), roundUp(e.MaxY)},
+		})
+	}
+	return entries
+}
+
+func roundDown(v float64) float32 {
+	f := float32(v)
+	if float64(f) > v {
+		f = math.Nextafter32(f, float32(math.Inf(-1)))
+	}
+	return f
+}
+
+func roundUp(v float64) float32 {
+	f := float32(v)
+	if float64(f) < v {
+		f = math.Nextafter32(f, float32(math.Inf(1)))
+	}
+	return f
+}
+
+type rtreeNode struct {
+	no       int64
+	children []*rtreeNode
+	entries  []rtreeEntry // for leaves
+}
+
+func (n *rtreeNode) cells() []rtreeEntry {
+	if n.children == nil {
+		return n.entries
+	}
+	cells := make([]rtreeEntry, len(n.children))
+	for i, c := range n.children {
+		box := [4]float32{float32(math.Inf(1)), float32(math.Inf(-1)), float32(math.Inf(1)), float32(math.Inf(-1))}
+		for _, e := range c.cells() {
+			box[0] = min(box[0], e.box[0])
+			box[1] = max(box[1], e.box[1])
+			box[2] = min(box[2], e.box[2])
+			box[3] = max(box[3], e.box[3])
+		}
+		cells[i] = rtreeEntry{id: c.no, box: box}
+	}
+	return cells
+}
+
+// rtreeTables bulk loads entries into the shadow tables of the R-tree
+// virtual table name. Leaves are packed with sort-tile-recursive order.
+func rtreeTables(name string, entries []rtreeEntry) []sqlite.Table {
+	center := func(e rtreeEntry, axis int) float32 { return e.box[2*axis] + e.box[2*axis+1] }
+
+	sorted := append([]rtreeEntry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool { return center(sorted[i], 0) < center(sorted[j], 0) })
+	leaves := (len(sorted) + rtreeMaxCells - 1) / rtreeMaxCells
+	strip := int(math.Ceil(math.Sqrt(float64(leaves)))) * rtreeMaxCells
+	for start := 0; start < len(sorted); start += strip {
+		s := sorted[start:min(start+strip, len(sorted))]
+		sort.SliceStable(s, func(i, j int) bool { return center(s[i], 1) < center(s[j], 1) })
+	}
+
+	var level []*rtreeNode
+	for start := 0; start < len(sorted); start += rtreeMaxCells {
+		level = append(level, &rtreeNode{entries: sorted[start:min(start+rtreeMaxCells, len(sorted))]})
+	}
+	depth := 0
+	for len(level) > 1 {
+		var parents []*rtreeNode
+		for start := 0; start < len(level); start += rtreeMaxCells {
+			parents = append(parents, &rtreeNode{children: level[start:min(start+rtreeMaxCells, len(level))]})
+		}
+		level = parents
+		depth++
+	}
+
+	root := &rtreeNode{}
+	if len(level) == 1 {
+		root = level[0]
+	}
+
+	// Number nodes breadth first; the root is always node 1.
+	nodes := []*rtreeNode{root}
+	for i := 0; i < len(nodes); i++ {
+		nodes[i].no = int64(i + 1)
+		nodes = append(nodes, nodes[i].children...)
+	}
+
+	nodeTable := sqlite.Table{Name: name + "_node", SQL: fmt.Sprintf("CREATE TABLE %q(nodeno INTEGER PRIMARY KEY,data)", name+"_node")}
+	parentTable := sqlite.Table{Name: name + "_parent", SQL: fmt.Sprintf("CREATE TABLE %q(nodeno INTEGER PRIMARY KEY,parentnode)", name+"_parent")}
+	rowidTable := sqlite.Table{Name: name + "_rowid", SQL: fmt.Sprintf("CREATE TABLE %q(rowid INTEGER PRIMARY KEY,nodeno)", name+"_rowid")}
+
+	var rowids []sqlite.Row
+	for _, n := range nodes {
+		data := make([]byte, rtreeNodeSize)
+		if n == root {
+			binary.BigEndian.PutUint16(data, uint16(depth))
+		}
+		cells := n.cells()
+		binary.BigEndian.PutUint16(data[2:], uint16(len(cells)))
+		for i, c := range cells {
+			cell := data[4+i*rtreeCellSize:]
+			binary.BigEndian.PutUint64(cell, uint64(c.id))
+			for j, v := range c.box {
+				binary.BigEndian.PutUint32(cell[8+4*j:], math.Float32bits(v))
+			}
+		}
+		nodeTable.Rows = append(nodeTable.Rows, sqlite.Row{ID: n.no, Values: []interface{}{nil, data}})
+
+		for _, c := range n.children {
+			parentTable.Rows = append(parentTable.Rows, sqlite.Row{ID: c.no, Values: []interface{}{nil, n.no}})
+		}
+		for _, e := range n.entries {
+			rowids = append(rowids, sqlite.Row{ID: e.id, Values: []interface{}{nil, n.no}})
+		}
+	}
+	sort.Slice(rowids, func(i, j int) bool { return rowids[i].ID < rowids[j].ID })
+	rowidTable.Rows = rowids
+
+	// SQLite creates the shadow tables in this order.
+	return []sqlite.Table{rowidTable, nodeTable, parentTable}
+}
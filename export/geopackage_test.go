@@ -0,0 +1,121 @@
+package export_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/export"
+	"github.com/kmesiab/go-nationalflooddata/models"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+)
+
+// queryGeoPackage writes content as a GeoPackage and runs sql on it with
+// the sqlite3 shell.
+func queryGeoPackage(t *testing.T, content *client.FloodMapContent, sql string) string {
+	t.Helper()
+
+	bin, err := exec.LookPath("sqlite3")
+	if err != nil {
+		t.Skip("sqlite3 not installed")
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteGeoPackage(&buf, content))
+
+	path := filepath.Join(t.TempDir(), "flood.gpkg")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+
+	out, err := exec.Command(bin, "-bail", path, sql).CombinedOutput()
+	require.NoError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+func TestWriteGeoPackage(t *testing.T) {
+	out := queryGeoPackage(t, nfdtest.FloodMapRaw(t), `PRAGMA integrity_check;
+PRAGMA foreign_key_check;
+PRAGMA application_id;
+PRAGMA user_version;
+SELECT table_name, data_type, min_x, min_y, max_x, max_y, srs_id FROM gpkg_contents;
+SELECT * FROM gpkg_geometry_columns;
+SELECT table_name, extension_name FROM gpkg_extensions ORDER BY rowid;
+SELECT fid, fld_zone, zone_subty, dfirm_id, distkm FROM flood_regions;
+SELECT fid, bfe_ln_id, elev, len_unit, v_datum, distkm FROM bfe_lines;
+SELECT rtreecheck('rtree_flood_regions_geom'), rtreecheck('rtree_bfe_lines_geom');
+SELECT id FROM rtree_flood_regions_geom WHERE minx <= -80.04 AND maxx >= -80.04 AND miny <= 26.703 AND maxy >= 26.703;
+SELECT hex(substr(geom, 1, 8)) FROM flood_regions WHERE fid = 1;`)
+
+	assert.Equal(t, strings.Join([]string{
+		"ok",
+		"1196444487", // "GPKG"
+		"10300",
+		"flood_regions|features|-80.052|26.7|-80.035|26.706|4326",
+		"bfe_lines|features|-80.043|26.7|-80.039|26.706|4326",
+		"flood_regions|geom|MULTIPOLYGON|4326|0|0",
+		"bfe_lines|geom|MULTILINESTRING|4326|0|0",
+		"flood_regions|gpkg_rtree_index",
+		"bfe_lines|gpkg_rtree_index",
+		"1|AE|COASTAL FLOODPLAIN|12099C|0.0",
+		"2|VE||12099C|0.21",
+		"3|X|AREA OF MINIMAL FLOOD HAZARD|12099C|0.35",
+		"1|12099C_4402|6.0|Feet|NAVD88|0.05",
+		"2|12099C_4410|7.0|Feet|NAVD88|0.21",
+		"ok|ok",
+		"1",
+		"47500003E6100000", // "GP", version 0, little-endian with envelope, SRS 4326
+	}, "\n"), out)
+}
+
+func TestWriteGeoPackage_GeometryIsWKB(t *testing.T) {
+	content := &client.FloodMapContent{Result: client.FloodMapContentResult{
+		BFEList: []models.BFEListItem{{BfeLnID: "a", GeoJSON: `{"type":"LineString","coordinates":[[1,2],[3,4]]}`}},
+	}}
+
+	// Skip the 8-byte header and 32-byte envelope.
+	out := queryGeoPackage(t, content, "SELECT hex(substr(geom, 41)) FROM bfe_lines;")
+	assert.Equal(t, "01"+"05000000"+"01000000"+ // multilinestring of 1
+		"01"+"02000000"+"02000000"+ // linestring of 2 points
+		"000000000000F03F"+"0000000000000040"+
+		"0000000000000840"+"0000000000001040", out)
+}
+
+func TestWriteGeoPackage_ManyFeatures(t *testing.T) {
+	// Enough regions for a multi-level R-tree and interior table pages,
+	// plus features without geometry.
+	content := &client.FloodMapContent{}
+	for i := 0; i < 5000; i++ {
+		x, y := -80+float64(i%70)*0.01, 26+float64(i/70)*0.01
+		content.Result.FloodRegions = append(content.Result.FloodRegions, models.FloodRegion{
+			FldArID: fmt.Sprint(i),
+			FldZone: "AE",
+			GeoJSON: fmt.Sprintf(`{"type":"Polygon","coordinates":[[[%[1]v,%[2]v],[%[3]v,%[2]v],[%[3]v,%[4]v],[%[1]v,%[2]v]]]}`, x, y, x+0.01, y+0.01),
+		})
+	}
+	content.Result.BFEList = []models.BFEListItem{{BfeLnID: "no geometry"}}
+
+	out := queryGeoPackage(t, content, `PRAGMA integrity_check;
+SELECT rtreecheck('rtree_flood_regions_geom'), rtreecheck('rtree_bfe_lines_geom');
+SELECT count(*) FROM rtree_flood_regions_geom;
+SELECT count(*), count(geom) FROM bfe_lines;
+SELECT group_concat(f.fld_ar_id) FROM flood_regions f JOIN rtree_flood_regions_geom r ON f.fid = r.id
+  WHERE r.minx <= -79.995 AND r.maxx >= -79.995 AND r.miny <= 26.105 AND r.maxy >= 26.105;`)
+
+	assert.Equal(t, "ok\nok|ok\n5000\n1|0\n700", out)
+}
+
+func TestWriteGeoPackage_InvalidGeometry(t *testing.T) {
+	content := &client.FloodMapContent{Result: client.FloodMapContentResult{
+		FloodRegions: []models.FloodRegion{{FldArID: "12099C_1", GeoJSON: `{"type":"LineString","coordinates":[[0,0],[1,1]]}`}},
+	}}
+
+	err := export.WriteGeoPackage(&bytes.Buffer{}, content)
+	assert.EqualError(t, err, `flood region 12099C_1: geometry type "LineString" is not polygonal`)
+}
@@ -0,0 +1,464 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/models"
+)
+
+// KMLDocument is the content of a KML or KMZ file for Google Earth.
+type KMLDocument struct {
+	// Name is shown as the document's title.
+	Name string
+
+	// FloodMap holds flood regions and BFE lines, from GetFloodMapRaw.
+	FloodMap *client.FloodMapContent
+
+	// Lookups are drawn as placemarks at their coordinates.
+	Lookups []*client.Response
+
+	// StaticMaps are images from GetStaticFloodMap shown in lookup
+	// balloons, keyed by index into Lookups.
+	StaticMaps map[int][]byte
+}
+
+// kmlNamespace is the KML 2.2 namespace.
+const kmlNamespace = "http://www.opengis.net/kml/2.2"
+
+// WriteKML writes doc as a KML file. Static map images are inlined as data
+// URIs, which not every viewer shows; prefer WriteKMZ when doc has them.
+func WriteKML(w io.Writer, doc *KMLDocument) error {
+	root, err := buildKML(doc, func(i int, png []byte) string {
+		return "data:image/png;base64," + base64.StdEncoding.EncodeToString(png)
+	})
+	if err != nil {
+		return err
+	}
+	return encodeKML(w, root)
+}
+
+// WriteKMZ writes doc as a KMZ archive: the KML document as doc.kml and
+// static map images under files/.
+func WriteKMZ(w io.Writer, doc *KMLDocument) error {
+	root, err := buildKML(doc, staticMapPath)
+	if err != nil {
+		return err
+	}
+
+	var kml bytes.Buffer
+	if err := encodeKML(&kml, root); err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+
+	// Viewers read the first .kml entry in the archive.
+	f, err := zw.Create("doc.kml")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(kml.Bytes()); err != nil {
+		return err
+	}
+
+	indexes := make([]int, 0, len(doc.StaticMaps))
+	for i := range doc.StaticMaps {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	for _, i := range indexes {
+		if i < 0 || i >= len(doc.Lookups) {
+			continue
+		}
+		f, err := zw.Create(staticMapPath(i, nil))
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(doc.StaticMaps[i]); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func staticMapPath(i int, _ []byte) string {
+	return fmt.Sprintf("files/lookup-%d.png", i)
+}
+
+func encodeKML(w io.Writer, root *kmlRoot) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// buildKML builds the document tree. imageSrc returns the balloon image
+// source for lookup i's static map.
+func buildKML(doc *KMLDocument, imageSrc func(i int, png []byte) string) (*kmlRoot, error) {
+	root := &kmlRoot{
+		Xmlns:    kmlNamespace,
+		Document: kmlDocument{Name: doc.Name},
+	}
+
+	for _, s := range styles {
+		root.Document.Styles = append(root.Document.Styles, kmlStyle{
+			ID:        "zone-" + s.Name,
+			LineStyle: &kmlLineStyle{Color: kmlColor(s.Stroke, 1), Width: 1},
+			PolyStyle: &kmlPolyStyle{Color: kmlColor(s.Fill, s.Opacity)},
+			IconStyle: &kmlIconStyle{Color: kmlColor(s.Fill, 1)},
+		})
+	}
+	root.Document.Styles = append(root.Document.Styles, kmlStyle{
+		ID:        "bfe",
+		LineStyle: &kmlLineStyle{Color: kmlColor(bfeStroke, 1), Width: 2},
+	})
+
+	if doc.FloodMap != nil {
+		regions := kmlFolder{Name: "Flood Regions"}
+		for _, r := range doc.FloodMap.Result.FloodRegions {
+			geometry, err := kmlGeometry(r.GeoJSON)
+			if err != nil {
+				return nil, fmt.Errorf("flood region %s: %w", r.FldArID, err)
+			}
+
+			data := [][2]string{
+				{"fld_zone", r.FldZone},
+				{"zone_subty", r.ZoneSubty},
+				{"fld_ar_id", r.FldArID},
+				{"dfirm_id", r.DfirmID},
+				{"distkm", strconv.FormatFloat(r.DistKm, 'f', -1, 64)},
+			}
+			regions.Placemarks = append(regions.Placemarks, kmlPlacemark{
+				Name:         "Zone " + r.FldZone,
+				Description:  balloon(r.FldZone, data, ""),
+				StyleURL:     "#zone-" + zoneStyle(r.FldZone, r.ZoneSubty).Name,
+				ExtendedData: extendedData(data),
+				Geometry:     geometry,
+			})
+		}
+
+		bfes := kmlFolder{Name: "Base Flood Elevations"}
+		for _, b := range doc.FloodMap.Result.BFEList {
+			geometry, err := kmlGeometry(b.GeoJSON)
+			if err != nil {
+				return nil, fmt.Errorf("BFE line %s: %w", b.BfeLnID, err)
+			}
+
+			elev := strconv.FormatFloat(b.Elev, 'f', -1, 64)
+			data := [][2]string{
+				{"elev", elev},
+				{"len_unit", b.LenUnit},
+				{"v_datum", b.VDatum},
+				{"bfe_ln_id", b.BfeLnID},
+				{"dfirm_id", b.DfirmID},
+				{"distkm", strconv.FormatFloat(b.DistKm, 'f', -1, 64)},
+			}
+			bfes.Placemarks = append(bfes.Placemarks, kmlPlacemark{
+				Name:         strings.TrimSpace("BFE " + elev + " " + b.LenUnit),
+				Description:  balloon("", data, ""),
+				StyleURL:     "#bfe",
+				ExtendedData: extendedData(data),
+				Geometry:     geometry,
+			})
+		}
+
+		root.Document.Folders = append(root.Document.Folders, regions, bfes)
+	}
+
+	if len(doc.Lookups) > 0 {
+		lookups := kmlFolder{Name: "Lookups"}
+		for i, resp := range doc.Lookups {
+			row := Flatten(resp)
+
+			var data [][2]string
+			for j, v := range row.Values() {
+				if v != nil {
+					data = append(data, [2]string{Columns[j].Name, formatValue(v)})
+				}
+			}
+
+			var image string
+			if png, ok := doc.StaticMaps[i]; ok {
+				image = imageSrc(i, png)
+			}
+
+			p := kmlPlacemark{
+				Name:         lookupName(row),
+				Description:  balloon(row.FloodZone, data, image),
+				ExtendedData: extendedData(data),
+			}
+			if row.FloodZone != "" {
+				p.StyleURL = "#zone-" + zoneStyle(row.FloodZone, row.ZoneSubtype).Name
+			}
+			if row.Lat != nil && row.Lng != nil {
+				p.Geometry = kmlPoint{Coordinates: kmlCoordinates([][]float64{{*row.Lng, *row.Lat}})}
+			}
+			lookups.Placemarks = append(lookups.Placemarks, p)
+		}
+		root.Document.Folders = append(root.Document.Folders, lookups)
+	}
+
+	return root, nil
+}
+
+func lookupName(row Row) string {
+	switch {
+	case row.Address != "":
+		return row.Address
+	case row.Lat != nil && row.Lng != nil:
+		return strconv.FormatFloat(*row.Lat, 'f', -1, 64) + ", " + strconv.FormatFloat(*row.Lng, 'f', -1, 64)
+	default:
+		return row.RequestID
+	}
+}
+
+// balloon renders a placemark's description: the zone's explanation, an
+// optional image and a table of attributes.
+func balloon(zone string, data [][2]string, image string) *kmlCDATA {
+	var b strings.Builder
+	z, _ := models.ParseFloodZone(zone)
+	if explanation := z.Explanation(); explanation != "" {
+		fmt.Fprintf(&b, "<p><b>Zone %s:</b> %s</p>", html.EscapeString(zone), html.EscapeString(explanation))
+	}
+	if image != "" {
+		fmt.Fprintf(&b, `<p><img src="%s"/></p>`, html.EscapeString(image))
+	}
+	b.WriteString("<table>")
+	for _, d := range data {
+		if d[1] != "" {
+			fmt.Fprintf(&b, "<tr><td><b>%s</b></td><td>%s</td></tr>", html.EscapeString(d[0]), html.EscapeString(d[1]))
+		}
+	}
+	b.WriteString("</table>")
+	return &kmlCDATA{Text: b.String()}
+}
+
+func extendedData(data [][2]string) *kmlExtendedData {
+	ed := &kmlExtendedData{}
+	for _, d := range data {
+		ed.Data = append(ed.Data, kmlData{Name: d[0], Value: d[1]})
+	}
+	return ed
+}
+
+// kmlColor converts a "#rrggbb" color and opacity to KML's aabbggrr.
+func kmlColor(hex string, opacity float64) string {
+	hex = strings.TrimPrefix(hex, "#")
+	alpha := int(opacity*255 + 0.5)
+	return fmt.Sprintf("%02x%s%s%s", alpha, hex[4:6], hex[2:4], hex[0:2])
+}
+
+// kmlGeometry converts a GeoJSON geometry to KML. An empty s has no
+// geometry.
+func kmlGeometry(s string) (interface{}, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var g geoJSONGeometry
+	if err := json.Unmarshal([]byte(s), &g); err != nil {
+		return nil, fmt.Errorf("parsing geometry: %w", err)
+	}
+	return g.kml()
+}
+
+func (g geoJSONGeometry) kml() (interface{}, error) {
+	var err error
+	decode := func(v interface{}) {
+		if err == nil {
+			err = json.Unmarshal(g.Coordinates, v)
+		}
+	}
+
+	var out interface{}
+	switch g.Type {
+	case "Point":
+		var c []float64
+		decode(&c)
+		out = kmlPoint{Coordinates: kmlCoordinates([][]float64{c})}
+	case "LineString":
+		var c [][]float64
+		decode(&c)
+		out = kmlLineString{Coordinates: kmlCoordinates(c)}
+	case "Polygon":
+		var c [][][]float64
+		decode(&c)
+		out = kmlPolygonOf(c)
+	case "MultiPoint":
+		var c [][]float64
+		decode(&c)
+		var m kmlMultiGeometry
+		for _, p := range c {
+			m.Geometries = append(m.Geometries, kmlPoint{Coordinates: kmlCoordinates([][]float64{p})})
+		}
+		out = m
+	case "MultiLineString":
+		var c [][][]float64
+		decode(&c)
+		var m kmlMultiGeometry
+		for _, l := range c {
+			m.Geometries = append(m.Geometries, kmlLineString{Coordinates: kmlCoordinates(l)})
+		}
+		out = m
+	case "MultiPolygon":
+		var c [][][][]float64
+		decode(&c)
+		var m kmlMultiGeometry
+		for _, p := range c {
+			m.Geometries = append(m.Geometries, kmlPolygonOf(p))
+		}
+		out = m
+	case "GeometryCollection":
+		var m kmlMultiGeometry
+		for _, child := range g.Geometries {
+			k, childErr := child.kml()
+			if childErr != nil {
+				return nil, childErr
+			}
+			m.Geometries = append(m.Geometries, k)
+		}
+		out = m
+	default:
+		return nil, fmt.Errorf("unsupported geometry type %q", g.Type)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s coordinates: %w", g.Type, err)
+	}
+	return out, nil
+}
+
+func kmlPolygonOf(rings [][][]float64) kmlPolygon {
+	var p kmlPolygon
+	for i, ring := range rings {
+		b := kmlBoundary{LinearRing: kmlLinearRing{Coordinates: kmlCoordinates(ring)}}
+		if i == 0 {
+			p.Outer = b
+		} else {
+			p.Inner = append(p.Inner, b)
+		}
+	}
+	return p
+}
+
+// kmlCoordinates formats positions as KML "lng,lat[,alt]" tuples.
+func kmlCoordinates(positions [][]float64) string {
+	tuples := make([]string, len(positions))
+	for i, p := range positions {
+		parts := make([]string, len(p))
+		for j, v := range p {
+			parts[j] = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		tuples[i] = strings.Join(parts, ",")
+	}
+	return strings.Join(tuples, " ")
+}
+
+type kmlRoot struct {
+	XMLName  xml.Name    `xml:"kml"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	Document kmlDocument `xml:"Document"`
+}
+
+type kmlDocument struct {
+	Name    string      `xml:"name,omitempty"`
+	Styles  []kmlStyle  `xml:"Style"`
+	Folders []kmlFolder `xml:"Folder"`
+}
+
+type kmlStyle struct {
+	ID        string        `xml:"id,attr"`
+	IconStyle *kmlIconStyle `xml:"IconStyle"`
+	LineStyle *kmlLineStyle `xml:"LineStyle"`
+	PolyStyle *kmlPolyStyle `xml:"PolyStyle"`
+}
+
+type kmlIconStyle struct {
+	Color string `xml:"color"`
+}
+
+type kmlLineStyle struct {
+	Color string  `xml:"color"`
+	Width float64 `xml:"width"`
+}
+
+type kmlPolyStyle struct {
+	Color string `xml:"color"`
+}
+
+type kmlFolder struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name         string           `xml:"name,omitempty"`
+	Description  *kmlCDATA        `xml:"description"`
+	StyleURL     string           `xml:"styleUrl,omitempty"`
+	ExtendedData *kmlExtendedData `xml:"ExtendedData"`
+
+	// Geometry is a kmlPoint, kmlLineString, kmlPolygon or
+	// kmlMultiGeometry, each of which names its own element.
+	Geometry interface{}
+}
+
+type kmlCDATA struct {
+	Text string `xml:",cdata"`
+}
+
+type kmlExtendedData struct {
+	Data []kmlData `xml:"Data"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlPoint struct {
+	XMLName     xml.Name `xml:"Point"`
+	Coordinates string   `xml:"coordinates"`
+}
+
+type kmlLineString struct {
+	XMLName     xml.Name `xml:"LineString"`
+	Coordinates string   `xml:"coordinates"`
+}
+
+type kmlPolygon struct {
+	XMLName xml.Name      `xml:"Polygon"`
+	Outer   kmlBoundary   `xml:"outerBoundaryIs"`
+	Inner   []kmlBoundary `xml:"innerBoundaryIs"`
+}
+
+type kmlBoundary struct {
+	LinearRing kmlLinearRing `xml:"LinearRing"`
+}
+
+type kmlLinearRing struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlMultiGeometry struct {
+	XMLName    xml.Name `xml:"MultiGeometry"`
+	Geometries []interface{}
+}
@@ -0,0 +1,191 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/export"
+	"github.com/kmesiab/go-nationalflooddata/models"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+)
+
+// kml is the part of a KML document the tests inspect.
+type kml struct {
+	XMLName  xml.Name `xml:"http://www.opengis.net/kml/2.2 kml"`
+	Document struct {
+		Name   string `xml:"name"`
+		Styles []struct {
+			ID string `xml:"id,attr"`
+		} `xml:"Style"`
+		Folders []struct {
+			Name       string `xml:"name"`
+			Placemarks []struct {
+				Name        string `xml:"name"`
+				Description string `xml:"description"`
+				StyleURL    string `xml:"styleUrl"`
+				Data        []struct {
+					Name  string `xml:"name,attr"`
+					Value string `xml:"value"`
+				} `xml:"ExtendedData>Data"`
+				Point *struct {
+					Coordinates string `xml:"coordinates"`
+				} `xml:"Point"`
+				LineString *struct {
+					Coordinates string `xml:"coordinates"`
+				} `xml:"LineString"`
+				Polygon *struct {
+					Outer string `xml:"outerBoundaryIs>LinearRing>coordinates"`
+				} `xml:"Polygon"`
+				MultiGeometry *struct {
+					Polygons []struct {
+						Outer string   `xml:"outerBoundaryIs>LinearRing>coordinates"`
+						Inner []string `xml:"innerBoundaryIs>LinearRing>coordinates"`
+					} `xml:"Polygon"`
+				} `xml:"MultiGeometry"`
+			} `xml:"Placemark"`
+		} `xml:"Folder"`
+	} `xml:"Document"`
+}
+
+func decodeKML(t *testing.T, data []byte) kml {
+	t.Helper()
+
+	var k kml
+	require.NoError(t, xml.Unmarshal(data, &k))
+	return k
+}
+
+func TestWriteKML(t *testing.T) {
+	doc := &export.KMLDocument{
+		Name:     "430 Australian Ave",
+		FloodMap: nfdtest.FloodMapRaw(t),
+		Lookups:  []*client.Response{nfdtest.FloodData(t)},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteKML(&buf, doc))
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte(xml.Header)))
+
+	k := decodeKML(t, buf.Bytes())
+	assert.Equal(t, "430 Australian Ave", k.Document.Name)
+
+	styles := map[string]bool{}
+	for _, s := range k.Document.Styles {
+		styles["#"+s.ID] = true
+	}
+
+	require.Len(t, k.Document.Folders, 3)
+	regions, bfes, lookups := k.Document.Folders[0], k.Document.Folders[1], k.Document.Folders[2]
+
+	require.Len(t, regions.Placemarks, 3)
+	ae := regions.Placemarks[0]
+	assert.Equal(t, "Zone AE", ae.Name)
+	assert.True(t, styles[ae.StyleURL], ae.StyleURL)
+	assert.NotEqual(t, ae.StyleURL, regions.Placemarks[1].StyleURL, "VE is styled apart from AE")
+	assert.Contains(t, ae.Description, models.FloodZoneExplanations["AE"])
+	assert.Contains(t, ae.Description, "COASTAL FLOODPLAIN")
+	require.NotNil(t, ae.Polygon)
+	assert.Equal(t, "-80.046,26.7 -80.039,26.7 -80.039,26.706 -80.046,26.706 -80.046,26.7", ae.Polygon.Outer)
+	assert.Contains(t, regions.Placemarks[1].Description, models.FloodZoneExplanations["VE, V1-V30"])
+
+	require.Len(t, bfes.Placemarks, 2)
+	assert.Equal(t, "BFE 6 Feet", bfes.Placemarks[0].Name)
+	assert.Equal(t, "#bfe", bfes.Placemarks[0].StyleURL)
+	require.NotNil(t, bfes.Placemarks[0].LineString)
+	assert.Equal(t, "-80.043,26.7 -80.043,26.706", bfes.Placemarks[0].LineString.Coordinates)
+
+	require.Len(t, lookups.Placemarks, 1)
+	lookup := lookups.Placemarks[0]
+	assert.Equal(t, "430 Australian Ave Palm Beach, FL 33480", lookup.Name)
+	assert.Equal(t, ae.StyleURL, lookup.StyleURL)
+	require.NotNil(t, lookup.Point)
+	assert.Equal(t, "-80.0423758242694,26.7032278122669", lookup.Point.Coordinates)
+	assert.Contains(t, lookup.Description, models.FloodZoneExplanations["AE"])
+	assert.Contains(t, lookup.Data, struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:"value"`
+	}{"flood_zone", "AE"})
+	assert.NotContains(t, lookup.Description, "<img")
+}
+
+func TestWriteKML_NumberedZonesAndMultiPolygons(t *testing.T) {
+	doc := &export.KMLDocument{FloodMap: &client.FloodMapContent{Result: client.FloodMapContentResult{
+		FloodRegions: []models.FloodRegion{{
+			FldZone: "A12",
+			GeoJSON: `{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]],[[0.1,0.1],[0.2,0.1],[0.2,0.2],[0.1,0.1]]]]}`,
+		}},
+	}}}
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteKML(&buf, doc))
+
+	p := decodeKML(t, buf.Bytes()).Document.Folders[0].Placemarks[0]
+	assert.Contains(t, p.Description, models.FloodZoneExplanations["A1-A30"])
+	require.NotNil(t, p.MultiGeometry)
+	require.Len(t, p.MultiGeometry.Polygons, 1)
+	assert.Equal(t, "0,0 1,0 1,1 0,0", p.MultiGeometry.Polygons[0].Outer)
+	assert.Equal(t, []string{"0.1,0.1 0.2,0.1 0.2,0.2 0.1,0.1"}, p.MultiGeometry.Polygons[0].Inner)
+}
+
+func TestWriteKML_InvalidGeometry(t *testing.T) {
+	doc := &export.KMLDocument{FloodMap: &client.FloodMapContent{Result: client.FloodMapContentResult{
+		BFEList: []models.BFEListItem{{BfeLnID: "12099C_1", GeoJSON: `{"type":"Curve"}`}},
+	}}}
+
+	err := export.WriteKML(io.Discard, doc)
+	assert.ErrorContains(t, err, `BFE line 12099C_1: unsupported geometry type "Curve"`)
+}
+
+func TestWriteKML_InlinesStaticMaps(t *testing.T) {
+	doc := &export.KMLDocument{
+		Lookups:    []*client.Response{nfdtest.FloodData(t)},
+		StaticMaps: map[int][]byte{0: []byte("png")},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteKML(&buf, doc))
+
+	p := decodeKML(t, buf.Bytes()).Document.Folders[0].Placemarks[0]
+	assert.Contains(t, p.Description, `<img src="data:image/png;base64,cG5n"/>`)
+}
+
+func TestWriteKMZ(t *testing.T) {
+	doc := &export.KMLDocument{
+		FloodMap:   nfdtest.FloodMapRaw(t),
+		Lookups:    []*client.Response{nfdtest.FloodData(t), nfdtest.FloodData(t)},
+		StaticMaps: map[int][]byte{1: []byte("png")},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteKMZ(&buf, doc))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 2)
+	assert.Equal(t, "doc.kml", zr.File[0].Name)
+	assert.Equal(t, "files/lookup-1.png", zr.File[1].Name)
+
+	read := func(f *zip.File) []byte {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		return data
+	}
+
+	assert.Equal(t, []byte("png"), read(zr.File[1]))
+
+	k := decodeKML(t, read(zr.File[0]))
+	lookups := k.Document.Folders[2].Placemarks
+	require.Len(t, lookups, 2)
+	assert.NotContains(t, lookups[0].Description, "<img")
+	assert.Contains(t, lookups[1].Description, `<img src="files/lookup-1.png"/>`)
+}
@@ -0,0 +1,100 @@
+package export
+
+import (
+	"fmt"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+)
+
+// layer is a feature table of the GeoPackage and Shapefile exports.
+type layer struct {
+	name string
+
+	// geometryType is the OGC geometry type name of every feature.
+	geometryType string
+
+	fields   []Column
+	features []feature
+}
+
+// geometry is a multiPolygon or multiLineString.
+type geometry interface {
+	wkb() []byte
+	envelope() envelope
+}
+
+// feature is a row of a layer. geometry is nil for features the API
+// returned without one.
+type feature struct {
+	geometry geometry
+	values   []interface{}
+}
+
+func (l *layer) envelope() envelope {
+	e := emptyEnvelope()
+	for _, f := range l.features {
+		if f.geometry != nil {
+			e.extend(f.geometry.envelope())
+		}
+	}
+	return e
+}
+
+// gisLayers splits content into a flood_regions layer of multipolygons
+// and a bfe_lines layer of multilinestrings.
+func gisLayers(content *client.FloodMapContent) ([]*layer, error) {
+	regions := &layer{
+		name:         "flood_regions",
+		geometryType: "MULTIPOLYGON",
+		fields: []Column{
+			{Name: "fld_ar_id", Type: String},
+			{Name: "fld_zone", Type: String},
+			{Name: "zone_subty", Type: String},
+			{Name: "dfirm_id", Type: String},
+			{Name: "distkm", Type: Float},
+			{Name: "ogc_fid", Type: Int},
+		},
+	}
+	for _, r := range content.Result.FloodRegions {
+		mp, err := parseMultiPolygon(r.GeoJSON)
+		if err != nil {
+			return nil, fmt.Errorf("flood region %s: %w", r.FldArID, err)
+		}
+
+		f := feature{values: []interface{}{r.FldArID, r.FldZone, r.ZoneSubty, r.DfirmID, r.DistKm, r.OgcFID}}
+		if len(mp) > 0 {
+			f.geometry = mp
+		}
+		regions.features = append(regions.features, f)
+	}
+
+	bfes := &layer{
+		name:         "bfe_lines",
+		geometryType: "MULTILINESTRING",
+		fields: []Column{
+			{Name: "bfe_ln_id", Type: String},
+			{Name: "elev", Type: Float},
+			{Name: "len_unit", Type: String},
+			{Name: "v_datum", Type: String},
+			{Name: "dfirm_id", Type: String},
+			{Name: "distkm", Type: Float},
+			{Name: "version_id", Type: String},
+			{Name: "source_cit", Type: String},
+			{Name: "ogc_fid", Type: Int},
+		},
+	}
+	for _, b := range content.Result.BFEList {
+		ml, err := parseMultiLineString(b.GeoJSON)
+		if err != nil {
+			return nil, fmt.Errorf("BFE line %s: %w", b.BfeLnID, err)
+		}
+
+		f := feature{values: []interface{}{b.BfeLnID, b.Elev, b.LenUnit, b.VDatum, b.DfirmID, b.DistKm, b.VersionID, b.SourceCit, b.OgcFID}}
+		if len(ml) > 0 {
+			f.geometry = ml
+		}
+		bfes.features = append(bfes.features, f)
+	}
+
+	return []*layer{regions, bfes}, nil
+}
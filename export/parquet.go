@@ -0,0 +1,29 @@
+package export
+
+import (
+	"io"
+
+	"github.com/kmesiab/go-nationalflooddata/internal/parquet"
+)
+
+// WriteParquet writes rows as a Parquet file with one optional column per
+// entry in Columns.
+func WriteParquet(w io.Writer, rows []Row) error {
+	columns := make([]parquet.Column, len(Columns))
+	for i, c := range Columns {
+		columns[i] = parquet.Column{Name: c.Name, Type: parquetTypes[c.Type]}
+	}
+
+	values := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		values[i] = row.Values()
+	}
+	return parquet.Write(w, columns, values)
+}
+
+var parquetTypes = map[ColumnType]parquet.Type{
+	String: parquet.String,
+	Float:  parquet.Double,
+	Bool:   parquet.Boolean,
+	Int:    parquet.Int64,
+}
@@ -0,0 +1,35 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/export"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+)
+
+func TestWriteParquet(t *testing.T) {
+	rows := []export.Row{
+		export.Flatten(nfdtest.FloodData(t)),
+		export.Flatten(&client.Response{Status: "OK"}),
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteParquet(&buf, rows))
+
+	file := buf.Bytes()
+	require.True(t, bytes.HasPrefix(file, []byte("PAR1")))
+	require.True(t, bytes.HasSuffix(file, []byte("PAR1")))
+
+	footerLen := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footer := file[len(file)-8-footerLen : len(file)-8]
+	for _, c := range export.Columns {
+		assert.Contains(t, string(footer), c.Name)
+	}
+	assert.Contains(t, string(file), "Lake Worth Lagoon")
+}
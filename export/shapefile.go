@@ -0,0 +1,273 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+)
+
+// Shapefile shape types.
+const (
+	shapeNull     = 0
+	shapePolyLine = 3
+	shapePolygon  = 5
+)
+
+// wgs84PRJ is the .prj of exported shapefiles, in ESRI's WKT dialect.
+const wgs84PRJ = `GEOGCS["GCS_WGS_1984",DATUM["D_WGS_1984",SPHEROID["WGS_1984",6378137.0,298.257223563]],PRIMEM["Greenwich",0.0],UNIT["Degree",0.0174532925199433]]`
+
+// WriteShapefiles writes the flood regions and BFE lines of content as a
+// zip archive of two ESRI Shapefile sets, flood_regions (polygons) and
+// bfe_lines (polylines), each with .shp, .shx, .dbf, .prj and .cpg files.
+// Attribute names are the API's, which fit the 10-character limit.
+func WriteShapefiles(w io.Writer, content *client.FloodMapContent) error {
+	layers, err := gisLayers(content)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	for _, l := range layers {
+		shp, shx := shapes(l)
+		dbf := dbase(l, time.Now())
+
+		files := []struct {
+			ext  string
+			data []byte
+		}{
+			{"shp", shp},
+			{"shx", shx},
+			{"dbf", dbf},
+			{"prj", []byte(wgs84PRJ)},
+			{"cpg", []byte("UTF-8")},
+		}
+		for _, f := range files {
+			fw, err := zw.Create(l.name + "." + f.ext)
+			if err != nil {
+				return err
+			}
+			if _, err := fw.Write(f.data); err != nil {
+				return err
+			}
+		}
+	}
+	return zw.Close()
+}
+
+// shapes encodes the geometries of l as .shp and .shx files.
+func shapes(l *layer) (shp, shx []byte) {
+	shapeType := int32(shapePolyLine)
+	if l.geometryType == "MULTIPOLYGON" {
+		shapeType = shapePolygon
+	}
+
+	var records, index bytes.Buffer
+	for i, f := range l.features {
+		content := shapeContent(shapeType, f.geometry)
+
+		// Offsets and lengths are in 16-bit words.
+		_ = binary.Write(&index, binary.BigEndian, int32((100+records.Len())/2))
+		_ = binary.Write(&index, binary.BigEndian, int32(len(content)/2))
+
+		_ = binary.Write(&records, binary.BigEndian, int32(i+1))
+		_ = binary.Write(&records, binary.BigEndian, int32(len(content)/2))
+		records.Write(content)
+	}
+
+	e := l.envelope()
+	if e.empty() {
+		e = envelope{}
+	}
+	shp = append(shapeHeader(shapeType, 100+records.Len(), e), records.Bytes()...)
+	shx = append(shapeHeader(shapeType, 100+index.Len(), e), index.Bytes()...)
+	return shp, shx
+}
+
+// shapeHeader is the 100-byte header shared by .shp and .shx files.
+func shapeHeader(shapeType int32, size int, e envelope) []byte {
+	h := make([]byte, 100)
+	binary.BigEndian.PutUint32(h, 9994)
+	binary.BigEndian.PutUint32(h[24:], uint32(size/2))
+	binary.LittleEndian.PutUint32(h[28:], 1000)
+	binary.LittleEndian.PutUint32(h[32:], uint32(shapeType))
+	for i, v := range []float64{e.MinX, e.MinY, e.MaxX, e.MaxY} {
+		binary.LittleEndian.PutUint64(h[36+8*i:], math.Float64bits(v))
+	}
+	return h
+}
+
+// shapeContent encodes g as a record of shapeType, or a null shape.
+func shapeContent(shapeType int32, g geometry) []byte {
+	var b bytes.Buffer
+	if g == nil {
+		_ = binary.Write(&b, binary.LittleEndian, int32(shapeNull))
+		return b.Bytes()
+	}
+
+	// Polygon parts are rings, outer rings clockwise and holes
+	// counterclockwise, the reverse of GeoJSON's winding.
+	var parts [][]point
+	switch g := g.(type) {
+	case multiPolygon:
+		for _, p := range g {
+			for i, r := range p {
+				parts = append(parts, wind(r, i == 0))
+			}
+		}
+	case multiLineString:
+		for _, l := range g {
+			parts = append(parts, l)
+		}
+	}
+
+	var n int
+	for _, p := range parts {
+		n += len(p)
+	}
+
+	e := g.envelope()
+	_ = binary.Write(&b, binary.LittleEndian, shapeType)
+	_ = binary.Write(&b, binary.LittleEndian, []float64{e.MinX, e.MinY, e.MaxX, e.MaxY})
+	_ = binary.Write(&b, binary.LittleEndian, int32(len(parts)))
+	_ = binary.Write(&b, binary.LittleEndian, int32(n))
+
+	start := 0
+	for _, p := range parts {
+		_ = binary.Write(&b, binary.LittleEndian, int32(start))
+		start += len(p)
+	}
+	for _, p := range parts {
+		for _, pt := range p {
+			_ = binary.Write(&b, binary.LittleEndian, pt[0])
+			_ = binary.Write(&b, binary.LittleEndian, pt[1])
+		}
+	}
+	return b.Bytes()
+}
+
+// wind returns r wound clockwise if clockwise is set, and
+// counterclockwise otherwise.
+func wind(r ring, clockwise bool) []point {
+	var area float64
+	for i := 0; i+1 < len(r); i++ {
+		area += r[i][0]*r[i+1][1] - r[i+1][0]*r[i][1]
+	}
+	if (area < 0) == clockwise {
+		return r
+	}
+
+	out := make([]point, len(r))
+	for i, p := range r {
+		out[len(r)-1-i] = p
+	}
+	return out
+}
+
+// dbfField is a dBase field descriptor.
+type dbfField struct {
+	typ      byte
+	width    int
+	decimals int
+}
+
+// dbase encodes the attributes of l as a dBase III .dbf file.
+func dbase(l *layer, now time.Time) []byte {
+	fields := make([]dbfField, len(l.fields))
+	for i, c := range l.fields {
+		switch c.Type {
+		case Float:
+			fields[i] = dbfField{typ: 'N', width: 24, decimals: 15}
+		case Int:
+			fields[i] = dbfField{typ: 'N', width: 18}
+		case Bool:
+			fields[i] = dbfField{typ: 'L', width: 1}
+		default:
+			width := 1
+			for _, f := range l.features {
+				if s, ok := f.values[i].(string); ok {
+					width = max(width, len(s))
+				}
+			}
+			fields[i] = dbfField{typ: 'C', width: min(width, 254)}
+		}
+	}
+
+	recordSize := 1
+	for _, f := range fields {
+		recordSize += f.width
+	}
+	headerSize := 32 + 32*len(fields) + 1
+
+	var b bytes.Buffer
+	b.WriteByte(0x03)
+	b.Write([]byte{byte(now.Year() - 1900), byte(now.Month()), byte(now.Day())})
+	_ = binary.Write(&b, binary.LittleEndian, uint32(len(l.features)))
+	_ = binary.Write(&b, binary.LittleEndian, uint16(headerSize))
+	_ = binary.Write(&b, binary.LittleEndian, uint16(recordSize))
+	b.Write(make([]byte, 20))
+
+	for i, f := range fields {
+		name := make([]byte, 11)
+		copy(name, l.fields[i].Name)
+		b.Write(name)
+		b.WriteByte(f.typ)
+		b.Write(make([]byte, 4))
+		b.WriteByte(byte(f.width))
+		b.WriteByte(byte(f.decimals))
+		b.Write(make([]byte, 14))
+	}
+	b.WriteByte(0x0d)
+
+	for _, feat := range l.features {
+		b.WriteByte(' ')
+		for i, f := range fields {
+			b.WriteString(dbfValue(f, feat.values[i]))
+		}
+	}
+	b.WriteByte(0x1a)
+
+	return b.Bytes()
+}
+
+// dbfValue formats v to exactly f.width bytes: text left-aligned and
+// numbers right-aligned.
+func dbfValue(f dbfField, v interface{}) string {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = truncate(v, f.width)
+		return s + strings.Repeat(" ", f.width-len(s))
+	case float64:
+		s = strconv.FormatFloat(v, 'f', f.decimals, 64)
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case bool:
+		s = "F"
+		if v {
+			s = "T"
+		}
+	}
+	if len(s) > f.width {
+		s = strings.TrimSuffix(s[:f.width], ".")
+	}
+	return strings.Repeat(" ", f.width-len(s)) + s
+}
+
+// truncate shortens s to at most n bytes without splitting a rune.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,213 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/export"
+	"github.com/kmesiab/go-nationalflooddata/models"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+)
+
+func unzipShapefiles(t *testing.T, content *client.FloodMapContent) (names []string, files map[string][]byte) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteShapefiles(&buf, content))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	files = make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+
+		names = append(names, f.Name)
+		files[f.Name] = data
+	}
+	return names, files
+}
+
+// shape is a decoded .shp record.
+type shape struct {
+	typ    int32
+	parts  [][][2]float64
+	offset int // in 16-bit words, as the .shx records it
+}
+
+func readShp(t *testing.T, shp []byte) (int32, []shape) {
+	t.Helper()
+
+	require.GreaterOrEqual(t, len(shp), 100)
+	require.EqualValues(t, 9994, binary.BigEndian.Uint32(shp))
+	require.EqualValues(t, len(shp)/2, binary.BigEndian.Uint32(shp[24:]))
+	require.EqualValues(t, 1000, binary.LittleEndian.Uint32(shp[28:]))
+	shapeType := int32(binary.LittleEndian.Uint32(shp[32:]))
+
+	var shapes []shape
+	for off := 100; off < len(shp); {
+		require.EqualValues(t, len(shapes)+1, binary.BigEndian.Uint32(shp[off:]))
+		size := int(binary.BigEndian.Uint32(shp[off+4:])) * 2
+		c := shp[off+8 : off+8+size]
+
+		s := shape{typ: int32(binary.LittleEndian.Uint32(c)), offset: off / 2}
+		if s.typ != 0 {
+			numParts := int(binary.LittleEndian.Uint32(c[36:]))
+			numPoints := int(binary.LittleEndian.Uint32(c[40:]))
+			starts := make([]int, numParts+1)
+			for i := 0; i < numParts; i++ {
+				starts[i] = int(binary.LittleEndian.Uint32(c[44+4*i:]))
+			}
+			starts[numParts] = numPoints
+
+			pts := c[44+4*numParts:]
+			for i := 0; i < numParts; i++ {
+				var part [][2]float64
+				for j := starts[i]; j < starts[i+1]; j++ {
+					part = append(part, [2]float64{
+						math.Float64frombits(binary.LittleEndian.Uint64(pts[16*j:])),
+						math.Float64frombits(binary.LittleEndian.Uint64(pts[16*j+8:])),
+					})
+				}
+				s.parts = append(s.parts, part)
+			}
+		}
+
+		shapes = append(shapes, s)
+		off += 8 + size
+	}
+	return shapeType, shapes
+}
+
+// readDbf decodes a .dbf into field names and trimmed records.
+func readDbf(t *testing.T, dbf []byte) ([]string, [][]string) {
+	t.Helper()
+
+	require.EqualValues(t, 0x03, dbf[0])
+	n := int(binary.LittleEndian.Uint32(dbf[4:]))
+	headerSize := int(binary.LittleEndian.Uint16(dbf[8:]))
+	recordSize := int(binary.LittleEndian.Uint16(dbf[10:]))
+	require.EqualValues(t, 0x0d, dbf[headerSize-1])
+	require.Len(t, dbf, headerSize+n*recordSize+1)
+	require.EqualValues(t, 0x1a, dbf[len(dbf)-1])
+
+	var names []string
+	var widths []int
+	for off := 32; off < headerSize-1; off += 32 {
+		names = append(names, strings.TrimRight(string(dbf[off:off+11]), "\x00"))
+		widths = append(widths, int(dbf[off+16]))
+	}
+
+	var records [][]string
+	for i := 0; i < n; i++ {
+		rec := dbf[headerSize+i*recordSize:]
+		require.EqualValues(t, ' ', rec[0])
+		pos := 1
+		var values []string
+		for _, w := range widths {
+			values = append(values, strings.TrimSpace(string(rec[pos:pos+w])))
+			pos += w
+		}
+		records = append(records, values)
+	}
+	return names, records
+}
+
+// signedArea is positive for counterclockwise rings.
+func signedArea(r [][2]float64) float64 {
+	var a float64
+	for i := 0; i+1 < len(r); i++ {
+		a += r[i][0]*r[i+1][1] - r[i+1][0]*r[i][1]
+	}
+	return a / 2
+}
+
+func TestWriteShapefiles(t *testing.T) {
+	names, files := unzipShapefiles(t, nfdtest.FloodMapRaw(t))
+	assert.Equal(t, []string{
+		"flood_regions.shp", "flood_regions.shx", "flood_regions.dbf", "flood_regions.prj", "flood_regions.cpg",
+		"bfe_lines.shp", "bfe_lines.shx", "bfe_lines.dbf", "bfe_lines.prj", "bfe_lines.cpg",
+	}, names)
+	assert.Contains(t, string(files["flood_regions.prj"]), "WGS_1984")
+	assert.Equal(t, "UTF-8", string(files["flood_regions.cpg"]))
+
+	shapeType, regions := readShp(t, files["flood_regions.shp"])
+	assert.EqualValues(t, 5, shapeType)
+	require.Len(t, regions, 3)
+	assert.Len(t, regions[0].parts, 1)
+	assert.Negative(t, signedArea(regions[0].parts[0]), "outer rings are clockwise")
+	assert.Equal(t, [2]float64{-80.046, 26.7}, regions[0].parts[0][0])
+
+	// The index points at every record.
+	shx := files["flood_regions.shx"]
+	require.Len(t, shx, 100+8*len(regions))
+	for i, r := range regions {
+		assert.EqualValues(t, r.offset, binary.BigEndian.Uint32(shx[100+8*i:]))
+	}
+
+	fields, records := readDbf(t, files["flood_regions.dbf"])
+	assert.Equal(t, []string{"fld_ar_id", "fld_zone", "zone_subty", "dfirm_id", "distkm", "ogc_fid"}, fields)
+	require.Len(t, records, 3)
+	assert.Equal(t, []string{"12099C_31710", "AE", "COASTAL FLOODPLAIN", "12099C", "0.000000000000000", "31710"}, records[0])
+	assert.Equal(t, "0.210000000000000", records[1][4])
+
+	shapeType, lines := readShp(t, files["bfe_lines.shp"])
+	assert.EqualValues(t, 3, shapeType)
+	require.Len(t, lines, 2)
+	assert.Equal(t, [][][2]float64{{{-80.043, 26.7}, {-80.043, 26.706}}}, lines[0].parts)
+
+	fields, records = readDbf(t, files["bfe_lines.dbf"])
+	assert.Equal(t, []string{"bfe_ln_id", "elev", "len_unit", "v_datum", "dfirm_id", "distkm", "version_id", "source_cit", "ogc_fid"}, fields)
+	assert.Equal(t, "12099C_4402", records[0][0])
+	assert.Equal(t, "6.000000000000000", records[0][1])
+}
+
+func TestWriteShapefiles_HolesAndMissingGeometry(t *testing.T) {
+	content := &client.FloodMapContent{Result: client.FloodMapContentResult{
+		FloodRegions: []models.FloodRegion{
+			{FldZone: "AE", GeoJSON: `{"type":"MultiPolygon","coordinates":[
+				[[[0,0],[4,0],[4,4],[0,4],[0,0]],[[1,1],[1,2],[2,2],[2,1],[1,1]]],
+				[[[5,5],[6,5],[6,6],[5,5]]]]}`},
+			{FldZone: "X"},
+		},
+	}}
+
+	_, files := unzipShapefiles(t, content)
+	_, regions := readShp(t, files["flood_regions.shp"])
+	require.Len(t, regions, 2)
+
+	parts := regions[0].parts
+	require.Len(t, parts, 3)
+	assert.Negative(t, signedArea(parts[0]), "outer ring clockwise")
+	assert.Positive(t, signedArea(parts[1]), "hole counterclockwise")
+	assert.Negative(t, signedArea(parts[2]), "second outer ring clockwise")
+
+	assert.EqualValues(t, 0, regions[1].typ, "null shape")
+
+	_, records := readDbf(t, files["flood_regions.dbf"])
+	assert.Equal(t, "X", records[1][1])
+}
+
+func TestWriteShapefiles_Empty(t *testing.T) {
+	_, files := unzipShapefiles(t, &client.FloodMapContent{})
+
+	shapeType, shapes := readShp(t, files["bfe_lines.shp"])
+	assert.EqualValues(t, 3, shapeType)
+	assert.Empty(t, shapes)
+
+	_, records := readDbf(t, files["bfe_lines.dbf"])
+	assert.Empty(t, records)
+}
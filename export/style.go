@@ -0,0 +1,48 @@
+package export
+
+import "github.com/kmesiab/go-nationalflooddata/models"
+
+// style is how a flood zone is drawn, loosely following the FEMA National
+// Flood Hazard Layer: blue for the 1% annual chance floodplain, a darker
+// blue for coastal high hazard areas, orange for the 0.2% floodplain and
+// grey for everything else.
+type style struct {
+	// Name identifies the style, e.g. in KML style ids.
+	Name string
+
+	// Fill and Stroke are "#rrggbb" colors.
+	Fill    string
+	Stroke  string
+	Opacity float64
+}
+
+// bfeStroke is the color BFE lines are drawn in.
+const bfeStroke = "#e31a1c"
+
+func zoneStyle(zone, subtype string) style {
+	z, _ := models.ParseFloodZoneWithSubtype(zone, subtype)
+	switch {
+	case z.IsCoastalHighHazard():
+		return style{Name: "coastal", Fill: "#08306b", Stroke: "#08306b", Opacity: 0.5}
+	case z.IsSFHA():
+		return style{Name: "sfha", Fill: "#2171b5", Stroke: "#08519c", Opacity: 0.4}
+	}
+
+	switch z.AnnualChance() {
+	case models.AnnualChancePointTwoPercent:
+		return style{Name: "moderate", Fill: "#fd8d3c", Stroke: "#e6550d", Opacity: 0.4}
+	case models.AnnualChanceUndetermined:
+		return style{Name: "undetermined", Fill: "#969696", Stroke: "#636363", Opacity: 0.3}
+	default:
+		return style{Name: "minimal", Fill: "#d9d9d9", Stroke: "#969696", Opacity: 0.2}
+	}
+}
+
+// styles lists every zone style, in the order KML documents declare them.
+var styles = []style{
+	zoneStyle("VE", ""),
+	zoneStyle("AE", ""),
+	zoneStyle("X500", ""),
+	zoneStyle("D", ""),
+	zoneStyle("X", ""),
+}
@@ -0,0 +1,4 @@
+package go_nationalflooddata
+
+// SanitizeResponse exposes sanitizeResponse to the external test package.
+var SanitizeResponse = sanitizeResponse
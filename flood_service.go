@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -11,10 +12,14 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/kmesiab/go-nationalflooddata/client"
 )
 
+// DefaultBaseURL is the base endpoint for the v3 API used when no other is configured.
+const DefaultBaseURL = "https://api.nationalflooddata.com/v3"
+
 // Service is the main client for interacting with the National Flood Data API.
 type Service struct {
 	// BaseURL is the base endpoint for the v3 API.
@@ -26,17 +31,84 @@ type Service struct {
 	// HTTPClient is the underlying HTTP client used to make requests.
 	// You can override this with a custom client (e.g., with custom timeouts).
 	HTTPClient *http.Client
+
+	// UserAgent, when set, is sent as the User-Agent header on every request.
+	UserAgent string
+
+	// Retry, when set, retries transient failures according to the policy.
+	Retry *RetryPolicy
+
+	// Cache, when set, stores successful GET responses and serves repeats from it.
+	Cache Cache
+
+	// Logger, when set, receives one line per HTTP attempt.
+	Logger Logger
+
+	// Limiter, when set, is waited on before every HTTP attempt.
+	Limiter Limiter
+
+	// Keys, when set, chooses the API key for every call instead of APIKey.
+	Keys KeyProvider
+
+	// Usage, when set, records every lookup for usage and cost accounting.
+	Usage UsageTracker
+
+	// Middleware is the chain of interceptors wrapped around every API call.
+	// See Use for ordering.
+	Middleware []Middleware
 }
 
 // NewService returns a new NFD service client initialized with the given API key.
 // By default, it uses https://api.nationalflooddata.com/v3 as the BaseURL and
-// http.DefaultClient for the HTTP client.
-func NewService(apiKey string) *Service {
+// http.DefaultClient for the HTTP client. Options are applied in order and the
+// resulting configuration is validated, so a bad base URL or retry policy is
+// reported here rather than on the first request.
+func NewService(apiKey string, opts ...Option) (*Service, error) {
+	cfg := &config{
+		baseURL:    DefaultBaseURL,
+		httpClient: http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	httpClient := cfg.httpClient
+	if cfg.timeout > 0 {
+		// Copy the client so a shared one, such as http.DefaultClient, is never mutated.
+		withTimeout := *httpClient
+		withTimeout.Timeout = cfg.timeout
+		httpClient = &withTimeout
+	}
+
 	return &Service{
-		BaseURL:    "https://api.nationalflooddata.com/v3",
+		BaseURL:    cfg.baseURL,
 		APIKey:     apiKey,
-		HTTPClient: http.DefaultClient,
+		HTTPClient: httpClient,
+		UserAgent:  cfg.userAgent,
+		Retry:      cfg.retry,
+		Cache:      cfg.cache,
+		Logger:     cfg.logger,
+		Limiter:    cfg.limiter,
+		Keys:       cfg.keys,
+		Usage:      cfg.usage,
+	}, nil
+}
+
+// MustNewService is like NewService but panics if the configuration is
+// invalid. It suits callers with a fixed configuration, and code written
+// before NewService returned an error: MustNewService(apiKey) is the old
+// NewService(apiKey).
+func MustNewService(apiKey string, opts ...Option) *Service {
+	s, err := NewService(apiKey, opts...)
+	if err != nil {
+		panic(err)
 	}
+	return s
 }
 
 // DoRequest is a helper to build and execute an HTTP request, returning the raw response body
@@ -47,9 +119,52 @@ func (s *Service) DoRequest(
 	queryParams url.Values,
 	body []byte,
 ) ([]byte, *http.Response, error) {
+	return s.doRequest(ctx, EndpointDoRequest, nil, method, path, queryParams, body)
+}
+
+// doRequest selects the API key for an endpoint and sends the request.
+func (s *Service) doRequest(
+	ctx context.Context,
+	endpoint string,
+	opts interface{},
+	method, path string,
+	queryParams url.Values,
+	body []byte,
+) ([]byte, *http.Response, error) {
+	apiKey, err := s.apiKey(ctx, endpoint, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	raw, resp, err := s.sendRequest(ctx, apiKey, endpoint, opts, method, path, queryParams, body)
+	s.reportKey(apiKey, err)
+	return raw, resp, err
+}
+
+// reportKey reports the outcome of a call made with a key from the
+// KeyProvider, if any.
+func (s *Service) reportKey(apiKey string, err error) {
+	if s.Keys != nil {
+		s.Keys.Report(apiKey, err)
+	}
+}
+
+// sendRequest builds the HTTP request for an endpoint and runs it through the
+// middleware chain, passing along the typed options the caller supplied. The
+// outcome is reported to the UsageTracker, if any.
+func (s *Service) sendRequest(
+	ctx context.Context,
+	apiKey string,
+	endpoint string,
+	opts interface{},
+	method, path string,
+	queryParams url.Values,
+	body []byte,
+) (raw []byte, resp *http.Response, err error) {
+	defer func() { s.recordUsage(ctx, endpoint, opts, resp, err) }()
+
 	// Build the full URL
-	endpoint := fmt.Sprintf("%s%s", strings.TrimSuffix(s.BaseURL, "/"), path)
-	u, err := url.Parse(endpoint)
+	u, err := url.Parse(fmt.Sprintf("%s%s", strings.TrimSuffix(s.BaseURL, "/"), path))
 	if err != nil {
 		return nil, nil, fmt.Errorf("invalid endpoint URL: %w", err)
 	}
@@ -71,17 +186,20 @@ func (s *Service) DoRequest(
 	}
 
 	// Always attach the x-api-key header
-	req.Header.Set("x-api-key", s.APIKey)
+	req.Header.Set("x-api-key", apiKey)
 	req.Header.Set("Content-Type", "application/json")
+	if s.UserAgent != "" {
+		req.Header.Set("User-Agent", s.UserAgent)
+	}
 
 	// Execute
-	resp, err := s.HTTPClient.Do(req)
+	resp, err = s.handler()(&Invocation{Endpoint: endpoint, Options: opts, Request: req})
 	if err != nil {
 		return nil, nil, fmt.Errorf("request error: %w", err)
 	}
 
 	defer resp.Body.Close()
-	raw, err := io.ReadAll(resp.Body)
+	raw, err = io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, resp, fmt.Errorf("reading response body: %w", err)
 	}
@@ -132,37 +250,7 @@ func ParseError(e *client.ErrorResponse) error {
 
 // GetFloodData queries the /data endpoint for FEMA Flood Data. It returns a FloodData struct.
 func (s *Service) GetFloodData(ctx context.Context, opts client.FloodDataOptions) (*client.Response, error) {
-	// Build query parameters from FloodDataOptions
-	q := url.Values{}
-	q.Set("searchtype", string(opts.SearchType))
-
-	// Conditionally add parameters
-	if opts.Address != "" {
-		q.Set("address", opts.Address)
-	}
-	if opts.Lat != 0 {
-		q.Set("lat", strconv.FormatFloat(opts.Lat, 'f', -1, 64))
-	}
-	if opts.Lng != 0 {
-		q.Set("lng", strconv.FormatFloat(opts.Lng, 'f', -1, 64))
-	}
-	if opts.Polygon != "" {
-		q.Set("polygon", opts.Polygon)
-	}
-	if opts.LOMA {
-		q.Set("loma", "true")
-	}
-	if opts.Elevation {
-		q.Set("elevation", "true")
-	}
-	if opts.Property {
-		q.Set("property", "true")
-	}
-	if opts.Parcel {
-		q.Set("parcel", "true")
-	}
-
-	raw, _, err := s.DoRequest(ctx, http.MethodGet, "/data", q, nil)
+	raw, _, err := s.doRequest(ctx, EndpointGetFloodData, opts, http.MethodGet, "/data", opts.Values(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -268,19 +356,7 @@ func sanitizeSlice(data []interface{}) {
 // GetFloodMapRaw queries the /floodmapraw endpoint for the raw FEMA Flood Map polygons.
 // This often returns large geojson content. The structure is defined by FloodMapContent.
 func (s *Service) GetFloodMapRaw(ctx context.Context, opts client.FloodMapRawOptions) (*client.FloodMapContent, error) {
-	q := url.Values{}
-	q.Set("lat", strconv.FormatFloat(opts.Lat, 'f', -1, 64))
-	q.Set("lng", strconv.FormatFloat(opts.Lng, 'f', -1, 64))
-
-	// The size parameter can be 0.04, 0.06, or 0.08. Default 0.08 if omitted.
-	if opts.Size != 0.0 {
-		q.Set("size", strconv.FormatFloat(opts.Size, 'f', 2, 64))
-	}
-	q.Set("geojson", strconv.FormatBool(opts.GeoJSON))
-	q.Set("excludex", strconv.FormatBool(opts.ExcludeX))
-	q.Set("elevation", strconv.FormatBool(opts.Elevation))
-
-	raw, _, err := s.DoRequest(ctx, http.MethodGet, "/floodmapraw", q, nil)
+	raw, _, err := s.doRequest(ctx, EndpointGetFloodMapRaw, opts, http.MethodGet, "/floodmapraw", opts.Values(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -301,9 +377,15 @@ func (s *Service) GetFloodMapRaw(ctx context.Context, opts client.FloodMapRawOpt
 // FloodDataBatch that contains a batch_id and a URL in `Result` which you can poll.
 func (s *Service) GetFloodDataBatch(ctx context.Context, batch client.BatchDataRequest) (*client.FloodDataBatch, error) {
 	// The batch JSON must contain "apiKey" at the top level as the spec indicates,
-	// but we also set the X-API-KEY header. Usually, these match.
-	if batch.APIKey == "" {
-		batch.APIKey = s.APIKey
+	// and we also set the X-API-KEY header to the same key. A key set by the
+	// caller is used as is, bypassing the KeyProvider.
+	provided := batch.APIKey == ""
+	if provided {
+		apiKey, err := s.apiKey(ctx, EndpointGetFloodDataBatch, batch)
+		if err != nil {
+			return nil, err
+		}
+		batch.APIKey = apiKey
 	}
 
 	body, err := json.Marshal(batch)
@@ -311,7 +393,10 @@ func (s *Service) GetFloodDataBatch(ctx context.Context, batch client.BatchDataR
 		return nil, fmt.Errorf("json marshal batch request: %w", err)
 	}
 
-	raw, _, reqErr := s.DoRequest(ctx, http.MethodPost, "/databatch", nil, body)
+	raw, _, reqErr := s.sendRequest(ctx, batch.APIKey, EndpointGetFloodDataBatch, batch, http.MethodPost, "/databatch", nil, body)
+	if provided {
+		s.reportKey(batch.APIKey, reqErr)
+	}
 	if reqErr != nil {
 		return nil, reqErr
 	}
@@ -323,6 +408,94 @@ func (s *Service) GetFloodDataBatch(ctx context.Context, batch client.BatchDataR
 	return &resp, nil
 }
 
+// -----------------------------------------------------------------------------
+//  GetFloodDataBatchResult
+// -----------------------------------------------------------------------------
+
+// ErrBatchPending is returned by GetFloodDataBatchResult while the batch is
+// still being processed.
+var ErrBatchPending = errors.New("batch result is not ready")
+
+// GetFloodDataBatchResult fetches the result of a batch from the presigned
+// URL in batch.Result. It returns ErrBatchPending until the batch completes.
+// The URL is not an API path, so no API key is sent. Retrieved results are
+// recorded to the UsageTracker, one record per lookup.
+func (s *Service) GetFloodDataBatchResult(ctx context.Context, batch *client.FloodDataBatch) ([]client.BatchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, batch.Result, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	if s.UserAgent != "" {
+		req.Header.Set("User-Agent", s.UserAgent)
+	}
+
+	resp, err := s.handler()(&Invocation{Endpoint: EndpointGetFloodDataBatchResult, Options: batch, Request: req})
+	if err != nil {
+		return nil, fmt.Errorf("request error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+
+	// The object is only written once the batch completes; until then S3
+	// answers NoSuchKey.
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrBatchPending
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("batch %s result: %s: %s", batch.BatchID, resp.Status, bytes.TrimSpace(raw))
+	}
+
+	var data []interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("json unmarshal batch result: %w", err)
+	}
+	sanitizeSlice(data)
+
+	sanitized, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("json marshal sanitized batch result: %w", err)
+	}
+
+	var results []client.BatchResult
+	if err := json.Unmarshal(sanitized, &results); err != nil {
+		return nil, fmt.Errorf("json unmarshal BatchResult: %w", err)
+	}
+	s.recordUsage(ctx, EndpointGetFloodDataBatchResult, results, resp, nil)
+	return results, nil
+}
+
+// DefaultBatchPollInterval is the interval WaitFloodDataBatch polls at when
+// given none.
+const DefaultBatchPollInterval = 10 * time.Second
+
+// WaitFloodDataBatch polls GetFloodDataBatchResult every interval until the
+// batch completes, an error other than ErrBatchPending occurs, or ctx is
+// done. An interval of zero or less uses DefaultBatchPollInterval.
+func (s *Service) WaitFloodDataBatch(ctx context.Context, batch *client.FloodDataBatch, interval time.Duration) ([]client.BatchResult, error) {
+	if interval <= 0 {
+		interval = DefaultBatchPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		results, err := s.GetFloodDataBatchResult(ctx, batch)
+		if !errors.Is(err, ErrBatchPending) {
+			return results, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for batch %s: %w", batch.BatchID, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
 // -----------------------------------------------------------------------------
 //  GetFloodVectorTile
 // -----------------------------------------------------------------------------
@@ -331,7 +504,7 @@ func (s *Service) GetFloodDataBatch(ctx context.Context, batch client.BatchDataR
 // It returns the raw tile data as a byte slice.
 func (s *Service) GetFloodVectorTile(ctx context.Context, z, x, y int) ([]byte, error) {
 	path := fmt.Sprintf("/tiles/flood-vector/%d/%d/%d.mvt", z, x, y)
-	raw, _, err := s.DoRequest(ctx, http.MethodGet, path, nil, nil)
+	raw, _, err := s.doRequest(ctx, EndpointGetFloodVectorTile, nil, http.MethodGet, path, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -346,7 +519,7 @@ func (s *Service) GetFloodVectorTile(ctx context.Context, z, x, y int) ([]byte,
 // It returns the raw tile data as a byte slice.
 func (s *Service) GetStormSurgeTile(ctx context.Context, category string, z, x, y int) ([]byte, error) {
 	path := fmt.Sprintf("/tiles/stormsurge/%s/%d/%d/%d.png", category, z, x, y)
-	raw, _, err := s.DoRequest(ctx, http.MethodGet, path, nil, nil)
+	raw, _, err := s.doRequest(ctx, EndpointGetStormSurgeTile, nil, http.MethodGet, path, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -367,7 +540,7 @@ func (s *Service) GetDynamicFloodMap(ctx context.Context, key string, lat, lng f
 	q.Set("zoom", strconv.Itoa(zoom))
 	q.Set("showLegend", strconv.FormatBool(showLegend))
 
-	raw, _, err := s.DoRequest(ctx, http.MethodGet, "/dynamic.html", q, nil)
+	raw, _, err := s.doRequest(ctx, EndpointGetDynamicFloodMap, nil, http.MethodGet, "/dynamic.html", q, nil)
 	if err != nil {
 		return "", err
 	}
@@ -381,16 +554,7 @@ func (s *Service) GetDynamicFloodMap(ctx context.Context, key string, lat, lng f
 // GetStaticFloodMap queries the /staticmap endpoint for the static flood map.
 // It returns the image data as a byte slice.
 func (s *Service) GetStaticFloodMap(ctx context.Context, opts client.StaticMapOptions) ([]byte, error) {
-	q := url.Values{}
-	q.Set("lat", strconv.FormatFloat(opts.Lat, 'f', -1, 64))
-	q.Set("lng", strconv.FormatFloat(opts.Lng, 'f', -1, 64))
-	q.Set("height", strconv.Itoa(opts.Height))
-	q.Set("width", strconv.Itoa(opts.Width))
-	q.Set("showMarker", strconv.FormatBool(opts.ShowMarker))
-	q.Set("showLegend", strconv.FormatBool(opts.ShowLegend))
-	q.Set("zoom", strconv.Itoa(opts.Zoom))
-
-	raw, _, err := s.DoRequest(ctx, http.MethodGet, "/staticmap", q, nil)
+	raw, _, err := s.doRequest(ctx, EndpointGetStaticFloodMap, opts, http.MethodGet, "/staticmap", opts.Values(), nil)
 	if err != nil {
 		return nil, err
 	}
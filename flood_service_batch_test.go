@@ -0,0 +1,155 @@
+package go_nationalflooddata_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+)
+
+var batchRequest = client.BatchDataRequest{
+	Requests: []client.BatchRequest{
+		{ID: "a", SearchType: client.SearchTypeCoord, Lat: "26.7", Lng: "-80.04"},
+		{ID: "b", SearchType: client.SearchTypeAddressParcel, Address: "430 Australian Ave Palm Beach, FL 33480", Elevation: true},
+	},
+}
+
+func TestGetFloodDataBatchResult_ShouldReturnPendingUntilComplete(t *testing.T) {
+	srv := nfdtest.NewServer(nfdtest.WithBatchPending(1))
+	defer srv.Close()
+	svc, err := srv.NewService()
+	require.NoError(t, err)
+
+	batch, err := svc.GetFloodDataBatch(context.Background(), batchRequest)
+	require.NoError(t, err)
+
+	_, err = svc.GetFloodDataBatchResult(context.Background(), batch)
+	assert.ErrorIs(t, err, nfd.ErrBatchPending)
+
+	results, err := svc.GetFloodDataBatchResult(context.Background(), batch)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "a", results[0].ID)
+	assert.Equal(t, "b", results[1].ID)
+	assert.Equal(t, "AE", results[1].Result.FloodFldHazAr[0].FldZone)
+	assert.NotNil(t, results[1].Result.Elevation)
+}
+
+func TestGetFloodDataBatchResult_ShouldNotSendAPIKey(t *testing.T) {
+	srv := nfdtest.NewServer()
+	defer srv.Close()
+	svc, err := srv.NewService()
+	require.NoError(t, err)
+
+	var header http.Header
+	svc.Use(func(next nfd.Handler) nfd.Handler {
+		return func(inv *nfd.Invocation) (*http.Response, error) {
+			if inv.Endpoint == nfd.EndpointGetFloodDataBatchResult {
+				header = inv.Request.Header.Clone()
+			}
+			return next(inv)
+		}
+	})
+
+	batch, err := svc.GetFloodDataBatch(context.Background(), batchRequest)
+	require.NoError(t, err)
+	_, err = svc.GetFloodDataBatchResult(context.Background(), batch)
+	require.NoError(t, err)
+
+	require.NotNil(t, header)
+	assert.Empty(t, header.Get("x-api-key"))
+}
+
+func TestGetFloodDataBatchResult_ShouldSanitizeResults(t *testing.T) {
+	service := newTestService(t, "test-api-key")
+	service.HTTPClient = &http.Client{Transport: staticBody([]byte(
+		`[{"id":"a  ","status":"OK","result":{"elevation":"Access Denied","flood.s_fld_haz_ar":[{"fld_zone":"X   "}]}}]`,
+	))}
+
+	results, err := service.GetFloodDataBatchResult(context.Background(), &client.FloodDataBatch{
+		BatchID: "batch-1",
+		Result:  "https://example.com/batch-1.json",
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "a", results[0].ID)
+	assert.Nil(t, results[0].Result.Elevation)
+	assert.Equal(t, "X", results[0].Result.FloodFldHazAr[0].FldZone)
+}
+
+func TestGetFloodDataBatchResult_ShouldReturnErrorForExpiredURL(t *testing.T) {
+	service := newTestService(t, "test-api-key")
+	service.HTTPClient = &http.Client{Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusForbidden,
+			Status:     "403 Forbidden",
+			Body:       io.NopCloser(strings.NewReader("Request has expired")),
+			Header:     make(http.Header),
+		}
+	})}
+
+	_, err := service.GetFloodDataBatchResult(context.Background(), &client.FloodDataBatch{
+		BatchID: "batch-1",
+		Result:  "https://example.com/batch-1.json",
+	})
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, nfd.ErrBatchPending)
+	assert.Contains(t, err.Error(), "Request has expired")
+}
+
+func TestWaitFloodDataBatch_ShouldPollUntilComplete(t *testing.T) {
+	srv := nfdtest.NewServer(nfdtest.WithBatchPending(3))
+	defer srv.Close()
+	svc, err := srv.NewService()
+	require.NoError(t, err)
+
+	batch, err := svc.GetFloodDataBatch(context.Background(), batchRequest)
+	require.NoError(t, err)
+
+	results, err := svc.WaitFloodDataBatch(context.Background(), batch, time.Millisecond)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+}
+
+func TestWaitFloodDataBatch_ShouldStopWhenContextIsDone(t *testing.T) {
+	srv := nfdtest.NewServer(nfdtest.WithBatchPending(1000))
+	defer srv.Close()
+	svc, err := srv.NewService()
+	require.NoError(t, err)
+
+	batch, err := svc.GetFloodDataBatch(context.Background(), batchRequest)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = svc.WaitFloodDataBatch(ctx, batch, time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWaitFloodDataBatch_ShouldDefaultNonPositiveInterval(t *testing.T) {
+	srv := nfdtest.NewServer(nfdtest.WithBatchPending(1000))
+	defer srv.Close()
+	svc, err := srv.NewService()
+	require.NoError(t, err)
+
+	batch, err := svc.GetFloodDataBatch(context.Background(), batchRequest)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		_, err = svc.WaitFloodDataBatch(ctx, batch, interval)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+}
@@ -10,7 +10,10 @@ import (
 
 func TestNewService_ShouldCreateServiceWithProvidedAPIKey(t *testing.T) {
 	apiKey := "test-api-key"
-	service := go_nationalflooddata.NewService(apiKey)
+	service, err := go_nationalflooddata.NewService(apiKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	if service.APIKey != apiKey {
 		t.Errorf("expected APIKey to be %s, got %s", apiKey, service.APIKey)
@@ -25,7 +28,10 @@ func TestNewService_ShouldCreateServiceWithProvidedAPIKey(t *testing.T) {
 
 func TestNewService_ShouldSetBaseURLToDefaultAPIEndpoint(t *testing.T) {
 	apiKey := "dummy-api-key"
-	service := go_nationalflooddata.NewService(apiKey)
+	service, err := go_nationalflooddata.NewService(apiKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	expectedBaseURL := "https://api.nationalflooddata.com/v3"
 	if service.BaseURL != expectedBaseURL {
@@ -35,7 +41,10 @@ func TestNewService_ShouldSetBaseURLToDefaultAPIEndpoint(t *testing.T) {
 
 func TestNewService_ShouldUseDefaultHTTPClient(t *testing.T) {
 	apiKey := "another-test-api-key"
-	service := go_nationalflooddata.NewService(apiKey)
+	service, err := go_nationalflooddata.NewService(apiKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	if service.HTTPClient != http.DefaultClient {
 		t.Error("expected HTTPClient to be http.DefaultClient")
@@ -44,7 +53,10 @@ func TestNewService_ShouldUseDefaultHTTPClient(t *testing.T) {
 
 func TestNewService_ShouldHandleEmptyAPIKey(t *testing.T) {
 	apiKey := ""
-	service := go_nationalflooddata.NewService(apiKey)
+	service, err := go_nationalflooddata.NewService(apiKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	if service.APIKey != apiKey {
 		t.Errorf("expected APIKey to be an empty string, got %s", service.APIKey)
@@ -59,7 +71,10 @@ func TestNewService_ShouldHandleEmptyAPIKey(t *testing.T) {
 
 func TestNewService_ShouldHandleVeryLongAPIKey(t *testing.T) {
 	longAPIKey := "a" + strings.Repeat("b", 1000) + "c"
-	service := go_nationalflooddata.NewService(longAPIKey)
+	service, err := go_nationalflooddata.NewService(longAPIKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	if service.APIKey != longAPIKey {
 		t.Errorf("expected APIKey to be %s, got %s", longAPIKey, service.APIKey)
@@ -76,8 +91,14 @@ func TestNewService_ShouldCreateNewInstanceEachTime(t *testing.T) {
 	apiKey1 := "api-key-1"
 	apiKey2 := "api-key-2"
 
-	service1 := go_nationalflooddata.NewService(apiKey1)
-	service2 := go_nationalflooddata.NewService(apiKey2)
+	service1, err := go_nationalflooddata.NewService(apiKey1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	service2, err := go_nationalflooddata.NewService(apiKey2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	if service1 == service2 {
 		t.Error("expected NewService to create a new instance each time, but got the same instance")
@@ -91,7 +112,10 @@ func TestNewService_ShouldCreateNewInstanceEachTime(t *testing.T) {
 func TestNewService_ShouldNotModifyDefaultHTTPClient(t *testing.T) {
 	originalClient := http.DefaultClient
 	apiKey := "test-api-key"
-	service := go_nationalflooddata.NewService(apiKey)
+	service, err := go_nationalflooddata.NewService(apiKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	if service.HTTPClient != originalClient {
 		t.Error("expected HTTPClient to be the same as the original http.DefaultClient")
@@ -100,7 +124,10 @@ func TestNewService_ShouldNotModifyDefaultHTTPClient(t *testing.T) {
 
 func TestNewService_ShouldEnsureBaseURLIsNotNil(t *testing.T) {
 	apiKey := "test-api-key"
-	service := go_nationalflooddata.NewService(apiKey)
+	service, err := go_nationalflooddata.NewService(apiKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	if service.BaseURL == "" {
 		t.Error("expected BaseURL to be non-nil, got an empty string")
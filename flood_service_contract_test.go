@@ -0,0 +1,363 @@
+package go_nationalflooddata_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata"
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/internal/openapi"
+	"github.com/kmesiab/go-nationalflooddata/models"
+)
+
+const openAPIPath = "docs/openapi.json"
+
+// contractCall invokes the Service method covering an operation with every
+// option set, so every parameter the method can send appears in the request.
+type contractCall struct {
+	method string
+	call   func(ctx context.Context, svc *go_nationalflooddata.Service) error
+}
+
+// contractCalls maps each operationId in the spec to the Service method
+// that implements it.
+var contractCalls = map[string]contractCall{
+	"getFloodData": {"GetFloodData", func(ctx context.Context, svc *go_nationalflooddata.Service) error {
+		_, err := svc.GetFloodData(ctx, client.FloodDataOptions{
+			SearchType: client.SearchTypeAddressParcel,
+			Address:    "430 Australian Ave Palm Beach FL 33480",
+			Lat:        26.7032,
+			Lng:        -80.0424,
+			Polygon:    "POLYGON((-80.05 26.70,-80.04 26.70,-80.04 26.71,-80.05 26.70))",
+			LOMA:       true,
+			Elevation:  true,
+			Property:   true,
+			Parcel:     true,
+		})
+		return err
+	}},
+	"getFloodDataBatch": {"GetFloodDataBatch", func(ctx context.Context, svc *go_nationalflooddata.Service) error {
+		_, err := svc.GetFloodDataBatch(ctx, client.BatchDataRequest{
+			Requests: []client.BatchRequest{{
+				ID:         "r1",
+				SearchType: client.SearchTypeCoordParcel,
+				Address:    "430 Australian Ave Palm Beach FL 33480",
+				Lat:        "26.7032",
+				Lng:        "-80.0424",
+				Polygon:    "POLYGON((-80.05 26.70,-80.04 26.70,-80.04 26.71,-80.05 26.70))",
+				LOMA:       true,
+				Elevation:  true,
+				Property:   true,
+				Parcel:     true,
+			}},
+		})
+		return err
+	}},
+	"getFloodMapContent": {"GetFloodMapRaw", func(ctx context.Context, svc *go_nationalflooddata.Service) error {
+		_, err := svc.GetFloodMapRaw(ctx, client.FloodMapRawOptions{
+			Lat: 26.7032, Lng: -80.0424, Size: 0.04, GeoJSON: true, ExcludeX: true, Elevation: true,
+		})
+		return err
+	}},
+	"getFloodVectorTile": {"GetFloodVectorTile", func(ctx context.Context, svc *go_nationalflooddata.Service) error {
+		_, err := svc.GetFloodVectorTile(ctx, 13, 2043, 3140)
+		return err
+	}},
+	"getStormSurgeTile": {"GetStormSurgeTile", func(ctx context.Context, svc *go_nationalflooddata.Service) error {
+		_, err := svc.GetStormSurgeTile(ctx, "category3", 13, 2043, 3140)
+		return err
+	}},
+	"dynamicFloodMap": {"GetDynamicFloodMap", func(ctx context.Context, svc *go_nationalflooddata.Service) error {
+		_, err := svc.GetDynamicFloodMap(ctx, "test-api-key", 26.7032, -80.0424, 15, true)
+		return err
+	}},
+	"staticFloodMap": {"GetStaticFloodMap", func(ctx context.Context, svc *go_nationalflooddata.Service) error {
+		_, err := svc.GetStaticFloodMap(ctx, client.StaticMapOptions{
+			Lat: 26.7032, Lng: -80.0424, Height: 600, Width: 800, ShowMarker: true, ShowLegend: true, Zoom: 15,
+		})
+		return err
+	}},
+}
+
+// contractSchemas maps component schemas to the Go types that decode them.
+var contractSchemas = map[string]interface{}{
+	"FloodDataBatch":        client.FloodDataBatch{},
+	"FloodData":             client.Response{},
+	"FemaResult":            client.Result{},
+	"FloodMapContent":       client.FloodMapContent{},
+	"FloodMapContentResult": client.FloodMapContentResult{},
+	"Coords":                models.Coords{},
+	"CensusBureau":          models.CensusBureau{},
+	"Community":             models.Community{},
+	"StormSurgeResult":      models.StormSurge{},
+	"s_fld_haz_ar":          models.FloodFieldHazard{},
+	"loma":                  models.Loma{},
+	"s_firm_pan":            models.FloodFirmPan{},
+	"s_pol_ar":              models.FloodPolAr{},
+	"Geocode":               models.Geocode{},
+	"ParcelAddress":         models.ParcelAddress{},
+	"BFEListItem":           models.BFEListItem{},
+	"FloodRegion":           models.FloodRegion{},
+}
+
+// knownDrift lists schemas where the client deliberately follows the live
+// API rather than the spec. The test fails if one of them starts matching,
+// so the list cannot go stale.
+var knownDrift = map[string]string{
+	"ParcelAddress": "the live API returns parceladdress with the field names in docs/sample_flood_data.json",
+	"FloodData":     "embeds ParcelAddress",
+}
+
+// notOperations lists Get* methods that do not call an API path.
+var notOperations = map[string]string{
+	"GetFloodDataBatchResult": "fetches the presigned result URL returned by getFloodDataBatch",
+}
+
+func loadOpenAPI(t *testing.T) *openapi.Document {
+	t.Helper()
+
+	doc, err := openapi.Load(openAPIPath)
+	require.NoError(t, err)
+	return doc
+}
+
+// captureRequest runs call against a transport that records the outgoing
+// request and answers with body.
+func captureRequest(t *testing.T, call contractCall, body string) (*http.Request, []byte) {
+	t.Helper()
+
+	var captured *http.Request
+	var capturedBody []byte
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			captured = req
+			if req.Body != nil {
+				capturedBody, _ = io.ReadAll(req.Body)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Header:     make(http.Header),
+				Request:    req,
+			}
+		}),
+	}
+
+	service := newTestService(t, "test-api-key", go_nationalflooddata.WithHTTPClient(httpClient))
+	_ = call.call(context.Background(), service)
+
+	require.NotNil(t, captured, "%s sent no request", call.method)
+	return captured, capturedBody
+}
+
+func TestContract_EveryOperationShouldBeCoveredByAServiceMethod(t *testing.T) {
+	doc := loadOpenAPI(t)
+
+	covered := make(map[string]bool)
+	for _, endpoint := range doc.Endpoints() {
+		call, ok := contractCalls[endpoint.Operation.OperationID]
+		if !assert.True(t, ok, "no Service method covers %s %s (%s)", endpoint.Method, endpoint.Path, endpoint.Operation.OperationID) {
+			continue
+		}
+		covered[call.method] = true
+	}
+
+	// Every Get* method must implement an operation in the spec.
+	serviceType := reflect.TypeOf(&go_nationalflooddata.Service{})
+	for i := 0; i < serviceType.NumMethod(); i++ {
+		name := serviceType.Method(i).Name
+		if _, skip := notOperations[name]; strings.HasPrefix(name, "Get") && !skip {
+			assert.True(t, covered[name], "%s does not implement any operation in %s", name, openAPIPath)
+		}
+	}
+}
+
+func TestContract_RequestsShouldMatchDocumentedParameters(t *testing.T) {
+	doc := loadOpenAPI(t)
+
+	for _, endpoint := range doc.Endpoints() {
+		call, ok := contractCalls[endpoint.Operation.OperationID]
+		if !ok {
+			continue
+		}
+
+		t.Run(endpoint.Operation.OperationID, func(t *testing.T) {
+			req, body := captureRequest(t, call, `{"status": "OK", "request_id": "r"}`)
+
+			assert.Equal(t, endpoint.Method, req.Method)
+			path := strings.TrimPrefix(req.URL.Path, "/v3")
+			assert.True(t, endpoint.Match(path), "%s does not match %s", path, endpoint.Path)
+
+			for _, p := range endpoint.ParametersIn("header") {
+				assert.NotEmpty(t, req.Header.Get(p.Name), "header %s not sent", p.Name)
+			}
+
+			documented := make(map[string]openapi.Parameter)
+			for _, p := range endpoint.ParametersIn("query") {
+				documented[p.Name] = p
+				assert.True(t, req.URL.Query().Has(p.Name), "query parameter %s is never sent", p.Name)
+			}
+			for name, values := range req.URL.Query() {
+				p, ok := documented[name]
+				if !assert.True(t, ok, "query parameter %s is not documented", name) {
+					continue
+				}
+				assertValueMatchesSchema(t, doc, p.Schema, values[0], "query parameter "+name)
+			}
+
+			if endpoint.Operation.RequestBody != nil {
+				assertBodyMatchesSchema(t, doc, endpoint.Operation.RequestBody.Content["application/json"].Schema, body)
+			}
+		})
+	}
+}
+
+func TestContract_ResponseSchemasShouldDecodeIntoClientTypes(t *testing.T) {
+	doc := loadOpenAPI(t)
+
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			target, ok := contractSchemas[name]
+			require.True(t, ok, "schema %s has no client type", name)
+
+			example, err := doc.Example(doc.Components.Schemas[name])
+			require.NoError(t, err)
+
+			err = decodeStrict(example, target)
+			if reason, drift := knownDrift[name]; drift {
+				assert.Error(t, err, "%s now matches the spec; remove it from knownDrift (%s)", name, reason)
+				return
+			}
+			assert.NoError(t, err, "%s example does not decode into %T", name, target)
+		})
+	}
+}
+
+func TestContract_ErrorExamplesShouldParseIntoTypedErrors(t *testing.T) {
+	doc := loadOpenAPI(t)
+
+	for _, endpoint := range doc.Endpoints() {
+		for code, resp := range endpoint.Operation.Responses {
+			media, ok := resp.Content["application/json"]
+			if !ok || len(media.Example) == 0 {
+				continue
+			}
+
+			status, err := strconv.Atoi(code)
+			require.NoError(t, err)
+
+			apiErr := &client.ErrorResponse{}
+			require.NoError(t, json.Unmarshal(media.Example, apiErr), "%s %s", endpoint.Path, code)
+			assert.Equal(t, status, apiErr.Status)
+			assert.NotEmpty(t, apiErr.Message)
+			assert.NotEqual(t, reflect.TypeOf(apiErr), reflect.TypeOf(go_nationalflooddata.ParseError(apiErr)),
+				"%s %s has no typed error", endpoint.Path, code)
+		}
+	}
+}
+
+// decodeStrict round-trips v through JSON into a new value of target's
+// type, rejecting fields the type does not declare.
+func decodeStrict(v interface{}, target interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	return dec.Decode(reflect.New(reflect.TypeOf(target)).Interface())
+}
+
+func assertValueMatchesSchema(t *testing.T, doc *openapi.Document, s *openapi.Schema, value, what string) {
+	t.Helper()
+
+	s, err := doc.Resolve(s)
+	require.NoError(t, err)
+	if s == nil {
+		return
+	}
+
+	switch {
+	case s.Type.Is("number"):
+		_, err := strconv.ParseFloat(value, 64)
+		assert.NoError(t, err, "%s: %q is not a number", what, value)
+	case s.Type.Is("integer"):
+		_, err := strconv.Atoi(value)
+		assert.NoError(t, err, "%s: %q is not an integer", what, value)
+	case s.Type.Is("boolean"):
+		_, err := strconv.ParseBool(value)
+		assert.NoError(t, err, "%s: %q is not a boolean", what, value)
+	}
+
+	if len(s.Enum) == 0 {
+		return
+	}
+	for _, e := range s.Enum {
+		var allowed interface{}
+		require.NoError(t, json.Unmarshal(e, &allowed))
+		if f, ok := allowed.(float64); ok {
+			if v, err := strconv.ParseFloat(value, 64); err == nil && v == f {
+				return
+			}
+		}
+		if allowed == value {
+			return
+		}
+	}
+	t.Errorf("%s: %q is not one of the documented values", what, value)
+}
+
+// assertBodyMatchesSchema checks that the JSON body sends exactly the
+// documented properties, recursing into arrays of objects.
+func assertBodyMatchesSchema(t *testing.T, doc *openapi.Document, s *openapi.Schema, body []byte) {
+	t.Helper()
+
+	var v interface{}
+	require.NoError(t, json.Unmarshal(body, &v))
+	assertFieldsMatchSchema(t, doc, s, v, "body")
+}
+
+func assertFieldsMatchSchema(t *testing.T, doc *openapi.Document, s *openapi.Schema, v interface{}, path string) {
+	t.Helper()
+
+	s, err := doc.Resolve(s)
+	require.NoError(t, err)
+	if s == nil {
+		return
+	}
+
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for name := range s.Properties {
+			assert.Contains(t, value, name, "%s.%s is never sent", path, name)
+		}
+		for name, field := range value {
+			prop, ok := s.Properties[name]
+			if assert.True(t, ok, "%s.%s is not documented", path, name) {
+				assertFieldsMatchSchema(t, doc, prop, field, path+"."+name)
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			assertFieldsMatchSchema(t, doc, s.Items, item, path+"[]")
+		}
+	}
+}
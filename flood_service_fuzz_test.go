@@ -0,0 +1,207 @@
+package go_nationalflooddata_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+	"github.com/kmesiab/go-nationalflooddata/client"
+)
+
+const sampleFloodDataPath = "docs/sample_flood_data.json"
+
+// Run a target with a short minimization time; minimizing inputs derived
+// from the 20 KB sample otherwise stalls the fuzzer for minutes:
+//
+//	go test -run '^$' -fuzz FuzzSanitizeResponse -fuzzminimizetime 1s .
+
+// addFloodDataSeeds seeds f with the sample response and the shapes the
+// sanitizer has to survive: "Access Denied" at increasing depth, huge
+// strings and invalid UTF-8.
+func addFloodDataSeeds(f *testing.F) {
+	// sanitizeSlice logs every denied slice element, which would flood
+	// the fuzzing workers' output.
+	log.SetOutput(io.Discard)
+	f.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	sample, err := os.ReadFile(sampleFloodDataPath)
+	require.NoError(f, err)
+	f.Add(sample)
+
+	denied := `"  Access Denied  "`
+	for depth := 0; depth < 16; depth++ {
+		f.Add([]byte(`{"status":"OK","result":` + denied + `}`))
+		if depth%2 == 0 {
+			denied = `{"k` + strconv.Itoa(depth) + `":` + denied + `,"s":" x "}`
+		} else {
+			denied = `[` + denied + `,"Access Denied",1]`
+		}
+	}
+
+	f.Add([]byte(`{"status":"` + strings.Repeat("a", 1<<16) + `"}`))
+	f.Add([]byte(`{"status":"` + strings.Repeat(" ", 1<<12) + `Access Denied"}`))
+	f.Add([]byte("{\"status\":\"\xff\xfe Access Denied\",\"\xc3\x28\":[\"\xed\xa0\x80\"]}"))
+	f.Add([]byte(`{"result":{"elevation":"Access Denied","property":["Access Denied"]}}`))
+	f.Add([]byte(`null`))
+	f.Add([]byte(`[]`))
+	f.Add([]byte(`{"a":1e400}`))
+}
+
+// FuzzSanitizeResponse checks that sanitizing never panics, leaves no
+// padded strings or "Access Denied" markers behind, and is idempotent.
+func FuzzSanitizeResponse(f *testing.F) {
+	addFloodDataSeeds(f)
+
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		once, err := nfd.SanitizeResponse(string(raw))
+		if err != nil {
+			return
+		}
+
+		var tree interface{}
+		require.NoError(t, json.Unmarshal([]byte(once), &tree), "sanitized output is not JSON")
+		assertSanitized(t, tree, "$")
+
+		twice, err := nfd.SanitizeResponse(once)
+		require.NoError(t, err)
+		assert.Equal(t, once, twice, "sanitizing is not idempotent")
+	})
+}
+
+// FuzzGetFloodData checks that GetFloodData never panics on an arbitrary
+// 200 response body, and returns either a response or an error.
+func FuzzGetFloodData(f *testing.F) {
+	addFloodDataSeeds(f)
+
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		service := newTestService(t, "test-api-key")
+		service.HTTPClient = &http.Client{Transport: staticBody(raw)}
+
+		resp, err := service.GetFloodData(context.Background(), client.FloodDataOptions{
+			SearchType: client.SearchTypeCoord,
+			Lat:        26.7,
+			Lng:        -80.04,
+		})
+		if err == nil {
+			assert.NotNil(t, resp)
+		}
+	})
+}
+
+// TestGetFloodData_DecodesAccessDeniedAtEveryPath replaces each value of
+// the sample response, one at a time, with "Access Denied" and checks the
+// result still decodes.
+func TestGetFloodData_DecodesAccessDeniedAtEveryPath(t *testing.T) {
+	sample, err := os.ReadFile(sampleFloodDataPath)
+	require.NoError(t, err)
+
+	var tree interface{}
+	require.NoError(t, json.Unmarshal(sample, &tree))
+
+	paths := jsonPaths(tree, nil)
+	require.NotEmpty(t, paths)
+
+	for _, path := range paths {
+		var doc interface{}
+		require.NoError(t, json.Unmarshal(sample, &doc))
+		setPath(doc, path, "Access Denied")
+
+		body, err := json.Marshal(doc)
+		require.NoError(t, err)
+
+		service := newTestService(t, "test-api-key")
+		service.HTTPClient = &http.Client{Transport: staticBody(body)}
+
+		_, err = service.GetFloodData(context.Background(), client.FloodDataOptions{
+			SearchType: client.SearchTypeAddressParcel,
+			Address:    "430 Australian Ave Palm Beach, FL 33480",
+		})
+		assert.NoError(t, err, "Access Denied at %v", path)
+	}
+}
+
+func staticBody(body []byte) RoundTripFunc {
+	return func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(string(body))),
+			Header:     make(http.Header),
+		}
+	}
+}
+
+// assertSanitized walks a decoded JSON value and fails on any string the
+// sanitizer should have trimmed or nulled.
+func assertSanitized(t *testing.T, v interface{}, path string) {
+	t.Helper()
+
+	switch v := v.(type) {
+	case string:
+		assert.Equal(t, strings.TrimSpace(v), v, "untrimmed string at %s", path)
+		assert.NotEqual(t, "Access Denied", v, "Access Denied left at %s", path)
+	case map[string]interface{}:
+		for key, child := range v {
+			assertSanitized(t, child, path+"."+key)
+		}
+	case []interface{}:
+		for i, child := range v {
+			assertSanitized(t, child, path+"["+strconv.Itoa(i)+"]")
+		}
+	}
+}
+
+// jsonPaths lists the path to every value beneath v. A path element is a
+// map key or a slice index.
+func jsonPaths(v interface{}, prefix []interface{}) [][]interface{} {
+	var paths [][]interface{}
+	visit := func(key, child interface{}) {
+		path := append(append([]interface{}{}, prefix...), key)
+		paths = append(paths, path)
+		paths = append(paths, jsonPaths(child, path)...)
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			visit(key, child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			visit(i, child)
+		}
+	}
+	return paths
+}
+
+// setPath replaces the value at path, which must exist, with value.
+func setPath(v interface{}, path []interface{}, value interface{}) {
+	for i, key := range path {
+		last := i == len(path)-1
+		switch key := key.(type) {
+		case string:
+			m := v.(map[string]interface{})
+			if last {
+				m[key] = value
+				return
+			}
+			v = m[key]
+		case int:
+			s := v.([]interface{})
+			if last {
+				s[key] = value
+				return
+			}
+			v = s[key]
+		}
+	}
+}
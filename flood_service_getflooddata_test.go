@@ -9,13 +9,12 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
-	go_nationalflooddata "github.com/kmesiab/go-nationalflooddata"
 	"github.com/kmesiab/go-nationalflooddata/client"
 )
 
 func TestGetFloodData_ShouldReturnErrorWhenDoRequestFails(t *testing.T) {
 	apiKey := "test-api-key"
-	service := go_nationalflooddata.NewService(apiKey)
+	service := newTestService(t, apiKey)
 
 	ctx := context.Background()
 	opts := client.FloodDataOptions{
@@ -43,7 +42,7 @@ func TestGetFloodData_ShouldReturnErrorWhenDoRequestFails(t *testing.T) {
 
 func TestGetFloodData_ShouldReturnErrorWhenSanitizeResponseFails(t *testing.T) {
 	apiKey := "test-api-key"
-	service := go_nationalflooddata.NewService(apiKey)
+	service := newTestService(t, apiKey)
 
 	ctx := context.Background()
 	opts := client.FloodDataOptions{
@@ -75,7 +74,7 @@ func TestGetFloodData_ShouldReturnErrorWhenSanitizeResponseFails(t *testing.T) {
 
 func TestGetFloodData_ShouldReturnErrorWhenJSONUnmarshalFails(t *testing.T) {
 	apiKey := "test-api-key"
-	service := go_nationalflooddata.NewService(apiKey)
+	service := newTestService(t, apiKey)
 
 	ctx := context.Background()
 	opts := client.FloodDataOptions{
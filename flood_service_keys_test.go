@@ -0,0 +1,221 @@
+package go_nationalflooddata_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata"
+	"github.com/kmesiab/go-nationalflooddata/client"
+)
+
+func TestKeyPool_RoundRobinShouldCycleThroughKeys(t *testing.T) {
+	pool := go_nationalflooddata.NewKeyPool(go_nationalflooddata.RoundRobin, time.Minute,
+		go_nationalflooddata.PooledKey{Key: "k1"},
+		go_nationalflooddata.PooledKey{Key: "k2"},
+	)
+
+	var got []string
+	for i := 0; i < 3; i++ {
+		key, err := pool.Key(context.Background(), go_nationalflooddata.KeyRequest{})
+		require.NoError(t, err)
+		got = append(got, key)
+	}
+
+	assert.Equal(t, []string{"k1", "k2", "k1"}, got)
+}
+
+func TestKeyPool_LeastUsedShouldPreferIdleKeys(t *testing.T) {
+	pool := go_nationalflooddata.NewKeyPool(go_nationalflooddata.LeastUsed, time.Minute,
+		go_nationalflooddata.PooledKey{Key: "k1", Entitlements: go_nationalflooddata.EntitlementParcel},
+		go_nationalflooddata.PooledKey{Key: "k2"},
+	)
+
+	// Parcel calls can only use k1, leaving k2 idle.
+	for i := 0; i < 2; i++ {
+		_, err := pool.Key(context.Background(), go_nationalflooddata.KeyRequest{Entitlements: go_nationalflooddata.EntitlementParcel})
+		require.NoError(t, err)
+	}
+
+	key, err := pool.Key(context.Background(), go_nationalflooddata.KeyRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "k2", key)
+}
+
+func TestKeyPool_ShouldRouteEntitledRequestsToLicensedKeys(t *testing.T) {
+	pool := go_nationalflooddata.NewKeyPool(go_nationalflooddata.RoundRobin, time.Minute,
+		go_nationalflooddata.PooledKey{Key: "basic"},
+		go_nationalflooddata.PooledKey{
+			Key:          "premium",
+			Entitlements: go_nationalflooddata.EntitlementProperty | go_nationalflooddata.EntitlementParcel,
+		},
+	)
+
+	for i := 0; i < 2; i++ {
+		key, err := pool.Key(context.Background(), go_nationalflooddata.KeyRequest{Entitlements: go_nationalflooddata.EntitlementProperty})
+		require.NoError(t, err)
+		assert.Equal(t, "premium", key)
+	}
+}
+
+func TestKeyPool_ShouldSidelineKeysAfterAuthenticationAndQuotaErrors(t *testing.T) {
+	pool := go_nationalflooddata.NewKeyPool(go_nationalflooddata.RoundRobin, time.Minute,
+		go_nationalflooddata.PooledKey{Key: "k1"},
+		go_nationalflooddata.PooledKey{Key: "k2"},
+	)
+
+	pool.Report("k1", go_nationalflooddata.ParseError(&client.ErrorResponse{Status: 401}))
+	pool.Report("k2", fmt.Errorf("lookup: %w", go_nationalflooddata.ParseError(&client.ErrorResponse{Status: 402})))
+
+	_, err := pool.Key(context.Background(), go_nationalflooddata.KeyRequest{Endpoint: go_nationalflooddata.EndpointGetFloodData})
+	assert.ErrorIs(t, err, go_nationalflooddata.ErrNoKeyAvailable)
+
+	for _, usage := range pool.Usage() {
+		assert.Equal(t, 1, usage.Failures)
+		assert.False(t, usage.SidelinedUntil.IsZero())
+	}
+}
+
+func TestKeyPool_ShouldNotSidelineKeysForOtherErrors(t *testing.T) {
+	pool := go_nationalflooddata.NewKeyPool(go_nationalflooddata.RoundRobin, time.Minute,
+		go_nationalflooddata.PooledKey{Key: "k1"},
+	)
+
+	pool.Report("k1", &client.LocationNotFoundError{ErrorResponse: &client.ErrorResponse{Status: 404}})
+
+	key, err := pool.Key(context.Background(), go_nationalflooddata.KeyRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "k1", key)
+}
+
+func TestKeyPool_SidelineOn_ShouldReplaceSidelineStatuses(t *testing.T) {
+	pool := go_nationalflooddata.NewKeyPool(go_nationalflooddata.RoundRobin, time.Minute,
+		go_nationalflooddata.PooledKey{Key: "k1"},
+	)
+	pool.SidelineOn(401)
+
+	pool.Report("k1", go_nationalflooddata.ParseError(&client.ErrorResponse{Status: 402}))
+	key, err := pool.Key(context.Background(), go_nationalflooddata.KeyRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "k1", key)
+
+	pool.Report("k1", go_nationalflooddata.ParseError(&client.ErrorResponse{Status: 401}))
+	_, err = pool.Key(context.Background(), go_nationalflooddata.KeyRequest{})
+	assert.ErrorIs(t, err, go_nationalflooddata.ErrNoKeyAvailable)
+}
+
+func TestWithKeyProvider_ShouldFailOverToNextKeyAfterAuthenticationError(t *testing.T) {
+	var keys []string
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			key := req.Header.Get("x-api-key")
+			keys = append(keys, key)
+
+			status, body := http.StatusOK, `{}`
+			if key == "revoked" {
+				status, body = http.StatusUnauthorized, `{"message": "Unauthorized"}`
+			}
+			return &http.Response{
+				StatusCode: status,
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Header:     make(http.Header),
+				Request:    req,
+			}
+		}),
+	}
+
+	pool := go_nationalflooddata.NewKeyPool(go_nationalflooddata.RoundRobin, time.Minute,
+		go_nationalflooddata.PooledKey{Key: "revoked"},
+		go_nationalflooddata.PooledKey{Key: "valid"},
+	)
+	service := newTestService(t, "",
+		go_nationalflooddata.WithHTTPClient(httpClient),
+		go_nationalflooddata.WithKeyProvider(pool),
+	)
+
+	for i := 0; i < 3; i++ {
+		_, _ = service.GetFloodVectorTile(context.Background(), 13, 2043, 3140)
+	}
+
+	assert.Equal(t, []string{"revoked", "valid", "valid"}, keys)
+}
+
+func TestGetFloodDataBatch_ShouldCopyProvidedKeyIntoRequestBody(t *testing.T) {
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			var batch client.BatchDataRequest
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&batch))
+
+			assert.Equal(t, "premium", req.Header.Get("x-api-key"))
+			assert.Equal(t, "premium", batch.APIKey)
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"batch_id": "b1"}`)),
+				Header:     make(http.Header),
+			}
+		}),
+	}
+
+	pool := go_nationalflooddata.NewKeyPool(go_nationalflooddata.RoundRobin, time.Minute,
+		go_nationalflooddata.PooledKey{Key: "basic"},
+		go_nationalflooddata.PooledKey{Key: "premium", Entitlements: go_nationalflooddata.EntitlementParcel},
+	)
+	service := newTestService(t, "",
+		go_nationalflooddata.WithHTTPClient(httpClient),
+		go_nationalflooddata.WithKeyProvider(pool),
+	)
+
+	_, err := service.GetFloodDataBatch(context.Background(), client.BatchDataRequest{
+		Requests: []client.BatchRequest{
+			{ID: "r1", SearchType: client.SearchTypeCoord},
+			{ID: "r2", SearchType: client.SearchTypeCoordParcel, Parcel: true},
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestGetFloodDataBatch_ShouldBypassProviderForCallerKey(t *testing.T) {
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			var batch client.BatchDataRequest
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&batch))
+
+			assert.Equal(t, "caller", req.Header.Get("x-api-key"))
+			assert.Equal(t, "caller", batch.APIKey)
+
+			return &http.Response{
+				StatusCode: http.StatusUnauthorized,
+				Body:       io.NopCloser(strings.NewReader(`{"message": "Unauthorized"}`)),
+				Header:     make(http.Header),
+				Request:    req,
+			}
+		}),
+	}
+
+	pool := go_nationalflooddata.NewKeyPool(go_nationalflooddata.RoundRobin, time.Minute,
+		go_nationalflooddata.PooledKey{Key: "pooled"},
+	)
+	service := newTestService(t, "",
+		go_nationalflooddata.WithHTTPClient(httpClient),
+		go_nationalflooddata.WithKeyProvider(pool),
+	)
+
+	_, err := service.GetFloodDataBatch(context.Background(), client.BatchDataRequest{
+		APIKey:   "caller",
+		Requests: []client.BatchRequest{{ID: "r1", SearchType: client.SearchTypeCoord}},
+	})
+	require.Error(t, err)
+
+	usage := pool.Usage()
+	assert.Zero(t, usage[0].Calls)
+	assert.Zero(t, usage[0].Failures)
+}
@@ -0,0 +1,137 @@
+package go_nationalflooddata_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	go_nationalflooddata "github.com/kmesiab/go-nationalflooddata"
+	"github.com/kmesiab/go-nationalflooddata/client"
+)
+
+func TestMiddleware_ShouldAllowRequestHeadersToBeInjected(t *testing.T) {
+	service := newTestService(t, "test-api-key")
+	service.HTTPClient = &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			assert.Equal(t, "acme", req.Header.Get("X-Tenant"))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{}`)),
+				Header:     make(http.Header),
+			}
+		}),
+	}
+
+	service.Use(func(next go_nationalflooddata.Handler) go_nationalflooddata.Handler {
+		return func(inv *go_nationalflooddata.Invocation) (*http.Response, error) {
+			inv.Request.Header.Set("X-Tenant", "acme")
+			return next(inv)
+		}
+	})
+
+	_, err := service.GetFloodVectorTile(context.Background(), 13, 2043, 3140)
+	require.NoError(t, err)
+}
+
+func TestMiddleware_ShouldSeeEndpointOptionsAndResponse(t *testing.T) {
+	service := newTestService(t, "test-api-key")
+	service.HTTPClient = &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"status": "OK"}`)),
+				Header:     make(http.Header),
+			}
+		}),
+	}
+
+	opts := client.FloodDataOptions{
+		SearchType: client.SearchTypeAddressCoord,
+		Address:    "123 Test St",
+		LOMA:       true,
+	}
+
+	var seen *go_nationalflooddata.Invocation
+	var status int
+	service.Use(func(next go_nationalflooddata.Handler) go_nationalflooddata.Handler {
+		return func(inv *go_nationalflooddata.Invocation) (*http.Response, error) {
+			seen = inv
+			resp, err := next(inv)
+			if resp != nil {
+				status = resp.StatusCode
+			}
+			return resp, err
+		}
+	})
+
+	_, err := service.GetFloodData(context.Background(), opts)
+	require.NoError(t, err)
+
+	require.NotNil(t, seen)
+	assert.Equal(t, go_nationalflooddata.EndpointGetFloodData, seen.Endpoint)
+	assert.Equal(t, opts, seen.Options)
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func TestMiddleware_ShouldRunInRegistrationOrder(t *testing.T) {
+	service := newTestService(t, "test-api-key")
+	service.HTTPClient = &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{}`)),
+				Header:     make(http.Header),
+			}
+		}),
+	}
+
+	var order []string
+	trace := func(name string) go_nationalflooddata.Middleware {
+		return func(next go_nationalflooddata.Handler) go_nationalflooddata.Handler {
+			return func(inv *go_nationalflooddata.Invocation) (*http.Response, error) {
+				order = append(order, name+":before")
+				resp, err := next(inv)
+				order = append(order, name+":after")
+				return resp, err
+			}
+		}
+	}
+
+	service.Use(trace("audit"), trace("tenant"))
+
+	_, _, err := service.DoRequest(context.Background(), http.MethodGet, "/test-path", nil, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"audit:before", "tenant:before", "tenant:after", "audit:after"}, order)
+}
+
+func TestMiddleware_ShouldAllowShortCircuitingTheHTTPClient(t *testing.T) {
+	service := newTestService(t, "test-api-key")
+	service.HTTPClient = &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			t.Error("expected the HTTP client not to be called")
+			return nil
+		}),
+	}
+
+	service.Use(func(next go_nationalflooddata.Handler) go_nationalflooddata.Handler {
+		return func(inv *go_nationalflooddata.Invocation) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusNotFound,
+				Body:       io.NopCloser(strings.NewReader(`{"message": "Location not found"}`)),
+				Header:     make(http.Header),
+				Request:    inv.Request,
+			}, nil
+		}
+	})
+
+	_, err := service.GetFloodData(context.Background(), client.FloodDataOptions{SearchType: client.SearchTypeCoord})
+
+	var notFound *client.LocationNotFoundError
+	assert.ErrorAs(t, err, &notFound)
+}
@@ -0,0 +1,398 @@
+package go_nationalflooddata_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata"
+	"github.com/kmesiab/go-nationalflooddata/client"
+)
+
+func TestNewService_ShouldApplyOptions(t *testing.T) {
+	httpClient := &http.Client{}
+	cache := go_nationalflooddata.NewMemoryCache(time.Minute)
+	logger := log.New(io.Discard, "", 0)
+
+	service := newTestService(t, "test-api-key",
+		go_nationalflooddata.WithBaseURL("https://example.com/v3"),
+		go_nationalflooddata.WithHTTPClient(httpClient),
+		go_nationalflooddata.WithUserAgent("underwriting/1.0"),
+		go_nationalflooddata.WithRetry(go_nationalflooddata.RetryPolicy{MaxAttempts: 3}),
+		go_nationalflooddata.WithCache(cache),
+		go_nationalflooddata.WithLogger(logger),
+	)
+
+	assert.Equal(t, "https://example.com/v3", service.BaseURL)
+	assert.Same(t, httpClient, service.HTTPClient)
+	assert.Equal(t, "underwriting/1.0", service.UserAgent)
+	assert.Equal(t, 3, service.Retry.MaxAttempts)
+	assert.Same(t, cache, service.Cache)
+	assert.Same(t, logger, service.Logger)
+}
+
+func TestNewService_ShouldApplyTimeoutWithoutMutatingDefaultHTTPClient(t *testing.T) {
+	service := newTestService(t, "test-api-key", go_nationalflooddata.WithTimeout(5*time.Second))
+
+	assert.NotSame(t, http.DefaultClient, service.HTTPClient)
+	assert.Equal(t, 5*time.Second, service.HTTPClient.Timeout)
+	assert.Zero(t, http.DefaultClient.Timeout)
+}
+
+func TestNewService_ShouldReturnValidationErrors(t *testing.T) {
+	tests := map[string][]go_nationalflooddata.Option{
+		"relative base URL":   {go_nationalflooddata.WithBaseURL("/v3")},
+		"unsupported scheme":  {go_nationalflooddata.WithBaseURL("ftp://example.com")},
+		"unparsable base URL": {go_nationalflooddata.WithBaseURL("://invalid-url")},
+		"nil HTTP client":     {go_nationalflooddata.WithHTTPClient(nil)},
+		"negative timeout":    {go_nationalflooddata.WithTimeout(-time.Second)},
+		"zero retry attempts": {go_nationalflooddata.WithRetry(go_nationalflooddata.RetryPolicy{})},
+		"inverted backoff": {go_nationalflooddata.WithRetry(go_nationalflooddata.RetryPolicy{
+			MaxAttempts:    2,
+			InitialBackoff: time.Second,
+			MaxBackoff:     time.Millisecond,
+		})},
+	}
+
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			service, err := go_nationalflooddata.NewService("test-api-key", opts...)
+
+			assert.Nil(t, service)
+			assert.ErrorIs(t, err, go_nationalflooddata.ErrInvalidConfig)
+		})
+	}
+}
+
+func TestMustNewService_ShouldReturnService(t *testing.T) {
+	service := go_nationalflooddata.MustNewService("test-api-key")
+
+	assert.Equal(t, "test-api-key", service.APIKey)
+	assert.Equal(t, go_nationalflooddata.DefaultBaseURL, service.BaseURL)
+}
+
+func TestMustNewService_ShouldPanicOnValidationErrors(t *testing.T) {
+	assert.Panics(t, func() {
+		go_nationalflooddata.MustNewService("test-api-key", go_nationalflooddata.WithBaseURL("/v3"))
+	})
+}
+
+func TestNewServiceFromEnv_ShouldReadAPIKeyAndBaseURL(t *testing.T) {
+	t.Setenv(go_nationalflooddata.EnvAPIKey, "env-api-key")
+	t.Setenv(go_nationalflooddata.EnvBaseURL, "https://staging.example.com/v3")
+
+	service, err := go_nationalflooddata.NewServiceFromEnv()
+	require.NoError(t, err)
+
+	assert.Equal(t, "env-api-key", service.APIKey)
+	assert.Equal(t, "https://staging.example.com/v3", service.BaseURL)
+}
+
+func TestNewServiceFromEnv_ShouldLetOptionsOverrideEnvironment(t *testing.T) {
+	t.Setenv(go_nationalflooddata.EnvAPIKey, "env-api-key")
+	t.Setenv(go_nationalflooddata.EnvBaseURL, "https://staging.example.com/v3")
+
+	service, err := go_nationalflooddata.NewServiceFromEnv(go_nationalflooddata.WithBaseURL("https://example.com/v3"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://example.com/v3", service.BaseURL)
+}
+
+func TestNewServiceFromEnv_ShouldFailWithoutAPIKey(t *testing.T) {
+	t.Setenv(go_nationalflooddata.EnvAPIKey, "")
+
+	_, err := go_nationalflooddata.NewServiceFromEnv()
+	assert.ErrorIs(t, err, go_nationalflooddata.ErrInvalidConfig)
+}
+
+func TestWithUserAgent_ShouldSetUserAgentHeader(t *testing.T) {
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			assert.Equal(t, "underwriting/1.0", req.Header.Get("User-Agent"))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{}`)),
+				Header:     make(http.Header),
+			}
+		}),
+	}
+
+	service := newTestService(t, "test-api-key",
+		go_nationalflooddata.WithHTTPClient(httpClient),
+		go_nationalflooddata.WithUserAgent("underwriting/1.0"),
+	)
+
+	_, _, err := service.DoRequest(context.Background(), http.MethodGet, "/test-path", nil, nil)
+	require.NoError(t, err)
+}
+
+func TestWithRetry_ShouldRetryTransientFailures(t *testing.T) {
+	attempts := 0
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			attempts++
+			body, _ := io.ReadAll(req.Body)
+			assert.Equal(t, `{"apiKey":"test-api-key","requests":null}`, string(body))
+
+			status := http.StatusServiceUnavailable
+			if attempts == 3 {
+				status = http.StatusOK
+			}
+			return &http.Response{
+				StatusCode: status,
+				Body:       io.NopCloser(strings.NewReader(`{"batch_id": "b1"}`)),
+				Header:     make(http.Header),
+				Request:    req,
+			}
+		}),
+	}
+
+	service := newTestService(t, "test-api-key",
+		go_nationalflooddata.WithHTTPClient(httpClient),
+		go_nationalflooddata.WithRetry(go_nationalflooddata.RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			RetryPOST:      true,
+		}),
+	)
+
+	batch, err := service.GetFloodDataBatch(context.Background(), client.BatchDataRequest{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, "b1", batch.BatchID)
+}
+
+func TestWithRetry_ShouldNotRetryPOSTByDefault(t *testing.T) {
+	attempts := 0
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			attempts++
+			return &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Body:       io.NopCloser(strings.NewReader(`{"message": "Service Unavailable"}`)),
+				Header:     make(http.Header),
+				Request:    req,
+			}
+		}),
+	}
+
+	service := newTestService(t, "test-api-key",
+		go_nationalflooddata.WithHTTPClient(httpClient),
+		go_nationalflooddata.WithRetry(go_nationalflooddata.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}),
+	)
+
+	_, err := service.GetFloodDataBatch(context.Background(), client.BatchDataRequest{})
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestWithRetry_ShouldWaitForRetryAfter(t *testing.T) {
+	attempts := 0
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			attempts++
+			header := make(http.Header)
+			status := http.StatusOK
+			if attempts == 1 {
+				status = http.StatusTooManyRequests
+				header.Set("Retry-After", "0")
+			}
+			return &http.Response{
+				StatusCode: status,
+				Body:       io.NopCloser(strings.NewReader("tile")),
+				Header:     header,
+				Request:    req,
+			}
+		}),
+	}
+
+	// The backoff would outlast the test; Retry-After replaces it.
+	service := newTestService(t, "test-api-key",
+		go_nationalflooddata.WithHTTPClient(httpClient),
+		go_nationalflooddata.WithRetry(go_nationalflooddata.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Hour}),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := service.GetFloodVectorTile(ctx, 13, 2043, 3140)
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestWithRetry_ShouldNotRetryClientErrors(t *testing.T) {
+	attempts := 0
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			attempts++
+			return &http.Response{
+				StatusCode: http.StatusUnauthorized,
+				Body:       io.NopCloser(strings.NewReader(`{"message": "Unauthorized"}`)),
+				Header:     make(http.Header),
+				Request:    req,
+			}
+		}),
+	}
+
+	service := newTestService(t, "test-api-key",
+		go_nationalflooddata.WithHTTPClient(httpClient),
+		go_nationalflooddata.WithRetry(go_nationalflooddata.RetryPolicy{MaxAttempts: 3}),
+	)
+
+	_, err := service.GetFloodVectorTile(context.Background(), 13, 2043, 3140)
+
+	var authErr *client.AuthenticationError
+	assert.ErrorAs(t, err, &authErr)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestWithCache_ShouldServeRepeatedRequestsFromCache(t *testing.T) {
+	attempts := 0
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			attempts++
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("tile-bytes")),
+				Header:     make(http.Header),
+			}
+		}),
+	}
+
+	service := newTestService(t, "test-api-key",
+		go_nationalflooddata.WithHTTPClient(httpClient),
+		go_nationalflooddata.WithCache(go_nationalflooddata.NewMemoryCache(time.Minute)),
+	)
+
+	for i := 0; i < 2; i++ {
+		tile, err := service.GetFloodVectorTile(context.Background(), 13, 2043, 3140)
+		require.NoError(t, err)
+		assert.Equal(t, "tile-bytes", string(tile))
+	}
+
+	assert.Equal(t, 1, attempts)
+}
+
+// keyCache is a Cache that remembers the keys it was given.
+type keyCache struct {
+	*go_nationalflooddata.MemoryCache
+	keys []string
+}
+
+func (c *keyCache) Set(key string, body []byte) {
+	c.keys = append(c.keys, key)
+	c.MemoryCache.Set(key, body)
+}
+
+func TestWithCache_ShouldNotShareResponsesAcrossAPIKeys(t *testing.T) {
+	attempts := 0
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			attempts++
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("tile-bytes")),
+				Header:     make(http.Header),
+			}
+		}),
+	}
+
+	cache := &keyCache{MemoryCache: go_nationalflooddata.NewMemoryCache(time.Minute)}
+	for _, key := range []string{"first-api-key", "second-api-key", "first-api-key"} {
+		service := newTestService(t, key,
+			go_nationalflooddata.WithHTTPClient(httpClient),
+			go_nationalflooddata.WithCache(cache),
+		)
+		_, err := service.GetFloodVectorTile(context.Background(), 13, 2043, 3140)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 2, attempts)
+	for _, key := range cache.keys {
+		assert.NotContains(t, key, "api-key")
+	}
+}
+
+func TestWithCache_ShouldKeepDynamicMapKeyOutOfCache(t *testing.T) {
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			assert.Equal(t, "map-secret", req.URL.Query().Get("key"))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("<html></html>")),
+				Header:     make(http.Header),
+			}
+		}),
+	}
+
+	cache := &keyCache{MemoryCache: go_nationalflooddata.NewMemoryCache(time.Minute)}
+	service := newTestService(t, "test-api-key",
+		go_nationalflooddata.WithHTTPClient(httpClient),
+		go_nationalflooddata.WithCache(cache),
+	)
+
+	_, err := service.GetDynamicFloodMap(context.Background(), "map-secret", 26.7, -80.04, 15, true)
+	require.NoError(t, err)
+
+	require.Len(t, cache.keys, 1)
+	assert.NotContains(t, cache.keys[0], "map-secret")
+	assert.Contains(t, cache.keys[0], "/dynamic.html?lat=26.7")
+}
+
+func TestWithLimiter_ShouldStopRequestsWhenLimiterFails(t *testing.T) {
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			t.Error("expected the HTTP client not to be called")
+			return nil
+		}),
+	}
+
+	service := newTestService(t, "test-api-key",
+		go_nationalflooddata.WithHTTPClient(httpClient),
+		go_nationalflooddata.WithLimiter(limiterFunc(func(ctx context.Context) error {
+			return errors.New("quota exhausted")
+		})),
+	)
+
+	_, err := service.GetFloodVectorTile(context.Background(), 13, 2043, 3140)
+	assert.ErrorContains(t, err, "quota exhausted")
+}
+
+func TestWithLogger_ShouldLogEveryAttemptWithoutQueryString(t *testing.T) {
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("<html></html>")),
+				Header:     make(http.Header),
+			}
+		}),
+	}
+
+	var buf bytes.Buffer
+	service := newTestService(t, "test-api-key",
+		go_nationalflooddata.WithHTTPClient(httpClient),
+		go_nationalflooddata.WithLogger(log.New(&buf, "", 0)),
+	)
+
+	_, err := service.GetDynamicFloodMap(context.Background(), "secret-key", 34.07, -118.25, 13, true)
+	require.NoError(t, err)
+
+	assert.Contains(t, buf.String(), "GetDynamicFloodMap GET /v3/dynamic.html: 200")
+	assert.NotContains(t, buf.String(), "secret-key")
+}
+
+type limiterFunc func(ctx context.Context) error
+
+func (f limiterFunc) Wait(ctx context.Context) error {
+	return f(ctx)
+}
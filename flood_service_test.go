@@ -10,14 +10,13 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/kmesiab/go-nationalflooddata"
 	"github.com/kmesiab/go-nationalflooddata/client"
 )
 
 // Do Request Tests
 func TestDoRequest_ShouldReturnErrorForInvalidEndpointURL(t *testing.T) {
 	apiKey := "test-api-key"
-	service := go_nationalflooddata.NewService(apiKey)
+	service := newTestService(t, apiKey)
 	service.BaseURL = "://invalid-url"
 
 	ctx := context.Background()
@@ -35,7 +34,7 @@ func TestDoRequest_ShouldReturnErrorForInvalidEndpointURL(t *testing.T) {
 
 func TestDoRequest_ShouldCorrectlyAttachQueryParametersToRequestURL(t *testing.T) {
 	apiKey := "test-api-key"
-	service := go_nationalflooddata.NewService(apiKey)
+	service := newTestService(t, apiKey)
 
 	ctx := context.Background()
 	queryParams := url.Values{}
@@ -65,7 +64,7 @@ func TestDoRequest_ShouldCorrectlyAttachQueryParametersToRequestURL(t *testing.T
 
 func TestDoRequest_ShouldHandleEmptyBodyWithoutErrors(t *testing.T) {
 	apiKey := "test-api-key"
-	service := go_nationalflooddata.NewService(apiKey)
+	service := newTestService(t, apiKey)
 
 	ctx := context.Background()
 	queryParams := url.Values{}
@@ -93,7 +92,7 @@ func TestDoRequest_ShouldHandleEmptyBodyWithoutErrors(t *testing.T) {
 
 func TestDoRequest_ShouldReturnErrorWhenCreatingRequestFails(t *testing.T) {
 	apiKey := "test-api-key"
-	service := go_nationalflooddata.NewService(apiKey)
+	service := newTestService(t, apiKey)
 
 	// Invalid method to trigger request creation failure
 	invalidMethod := "INVALID_METHOD"
@@ -112,7 +111,7 @@ func TestDoRequest_ShouldReturnErrorWhenCreatingRequestFails(t *testing.T) {
 
 func TestDoRequest_ShouldSetXApiKeyHeader(t *testing.T) {
 	apiKey := "test-api-key"
-	service := go_nationalflooddata.NewService(apiKey)
+	service := newTestService(t, apiKey)
 
 	ctx := context.Background()
 	path := "/test-path"
@@ -139,7 +138,7 @@ func TestDoRequest_ShouldSetXApiKeyHeader(t *testing.T) {
 
 func TestDoRequest_ShouldSetContentTypeHeaderToApplicationJSON(t *testing.T) {
 	apiKey := "test-api-key"
-	service := go_nationalflooddata.NewService(apiKey)
+	service := newTestService(t, apiKey)
 
 	ctx := context.Background()
 	path := "/test-path"
@@ -166,7 +165,7 @@ func TestDoRequest_ShouldSetContentTypeHeaderToApplicationJSON(t *testing.T) {
 
 func TestDoRequest_ShouldReturnErrorWhenHTTPClientFails(t *testing.T) {
 	apiKey := "test-api-key"
-	service := go_nationalflooddata.NewService(apiKey)
+	service := newTestService(t, apiKey)
 
 	ctx := context.Background()
 	path := "/test-path"
@@ -191,7 +190,7 @@ func TestDoRequest_ShouldReturnErrorWhenHTTPClientFails(t *testing.T) {
 
 func TestDoRequest_ShouldCorrectlyReadAndReturnResponseBodyForSuccessfulRequests(t *testing.T) {
 	apiKey := "test-api-key"
-	service := go_nationalflooddata.NewService(apiKey)
+	service := newTestService(t, apiKey)
 
 	ctx := context.Background()
 	path := "/test-path"
@@ -220,7 +219,7 @@ func TestDoRequest_ShouldCorrectlyReadAndReturnResponseBodyForSuccessfulRequests
 
 func TestDoRequest_ShouldReturnErrorWhenReadingResponseBodyFails(t *testing.T) {
 	apiKey := "test-api-key"
-	service := go_nationalflooddata.NewService(apiKey)
+	service := newTestService(t, apiKey)
 
 	ctx := context.Background()
 	path := "/test-path"
@@ -249,7 +248,7 @@ func TestDoRequest_ShouldReturnErrorWhenReadingResponseBodyFails(t *testing.T) {
 
 func TestDoRequest_ShouldParseAndReturnErrorResponseForHTTPStatusCodes400AndAbove(t *testing.T) {
 	apiKey := "test-api-key"
-	service := go_nationalflooddata.NewService(apiKey)
+	service := newTestService(t, apiKey)
 
 	ctx := context.Background()
 	path := "/test-path"
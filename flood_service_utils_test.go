@@ -1,2 +1,20 @@
 package go_nationalflooddata_test
 
+import (
+	"testing"
+
+	"github.com/kmesiab/go-nationalflooddata"
+)
+
+// newTestService returns a Service for apiKey, failing the test if the
+// options do not validate.
+func newTestService(t *testing.T, apiKey string, opts ...go_nationalflooddata.Option) *go_nationalflooddata.Service {
+	t.Helper()
+
+	service, err := go_nationalflooddata.NewService(apiKey, opts...)
+	if err != nil {
+		t.Fatalf("unexpected error creating service: %v", err)
+	}
+
+	return service
+}
@@ -0,0 +1,5 @@
+package go_nationalflooddata
+
+// The option structs, their query encoders and the model types are
+// generated from the OpenAPI document. See internal/cmd/nfdgen.
+//go:generate go run ./internal/cmd/nfdgen -spec docs/openapi.json -overlay internal/cmd/nfdgen/overlay.json
@@ -0,0 +1,204 @@
+// Command nfdgen generates the client's option structs, query encoders and
+// model types from docs/openapi.json. It is run by go generate from the
+// repository root:
+//
+//	go generate ./...
+//
+// Decisions the spec cannot express, such as Go names and fields the live
+// API returns but the spec omits, live in overlay.json.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/kmesiab/go-nationalflooddata/internal/openapi"
+)
+
+// header starts every generated file.
+const header = "// Code generated by nfdgen from docs/openapi.json; DO NOT EDIT.\n\n"
+
+func main() {
+	spec := flag.String("spec", "docs/openapi.json", "path to the OpenAPI document")
+	overlayPath := flag.String("overlay", "internal/cmd/nfdgen/overlay.json", "path to the overlay")
+	out := flag.String("out", ".", "module root to write generated files under")
+	flag.Parse()
+
+	doc, err := openapi.Load(*spec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	overlay, err := loadOverlay(*overlayPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	files, err := generate(doc, overlay)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(*out, name), src, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// generate returns the generated files, keyed by path relative to the
+// module root.
+func generate(doc *openapi.Document, overlay *Overlay) (map[string][]byte, error) {
+	files := make(map[string]*file)
+
+	if err := generateModels(doc, overlay, files); err != nil {
+		return nil, err
+	}
+	if err := generateOptions(doc, overlay, files); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string][]byte, len(files))
+	for name, f := range files {
+		src, err := f.format()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		out[name] = src
+	}
+	return out, nil
+}
+
+// file accumulates the declarations of one generated file.
+type file struct {
+	pkg     string
+	imports map[string]bool
+	decls   []string
+}
+
+func fileFor(files map[string]*file, pkg, name string) *file {
+	path := filepath.ToSlash(filepath.Join(pkg, name))
+	f, ok := files[path]
+	if !ok {
+		f = &file{pkg: pkg, imports: make(map[string]bool)}
+		files[path] = f
+	}
+	return f
+}
+
+func (f *file) format() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	fmt.Fprintf(&buf, "package %s\n\n", f.pkg)
+
+	if len(f.imports) > 0 {
+		imports := make([]string, 0, len(f.imports))
+		for imp := range f.imports {
+			imports = append(imports, imp)
+		}
+		sort.Strings(imports)
+
+		if len(imports) == 1 {
+			fmt.Fprintf(&buf, "import %q\n\n", imports[0])
+		} else {
+			buf.WriteString("import (\n")
+			for _, imp := range imports {
+				fmt.Fprintf(&buf, "\t%q\n", imp)
+			}
+			buf.WriteString(")\n\n")
+		}
+	}
+
+	buf.WriteString(strings.Join(f.decls, "\n"))
+	return format.Source(buf.Bytes())
+}
+
+// comment formats doc as a Go comment with the given indent. Overlay docs
+// keep their line breaks; spec descriptions are wrapped.
+func comment(doc, indent string) string {
+	if doc == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimSpace(doc), "\n") {
+		b.WriteString(indent + "// " + strings.TrimSpace(line) + "\n")
+	}
+	return b.String()
+}
+
+// wrap breaks s into lines of at most width characters.
+func wrap(s string, width int) string {
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(s) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// initialisms are upper-cased when deriving Go names.
+var initialisms = map[string]string{
+	"id":   "ID",
+	"url":  "URL",
+	"api":  "API",
+	"json": "JSON",
+	"loma": "LOMA",
+	"bfe":  "BFE",
+}
+
+// goName derives an exported Go name from a JSON name such as
+// "fld_ar_id" or "flood.s_firm_pan".
+func goName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for _, part := range parts {
+		if up, ok := initialisms[strings.ToLower(part)]; ok {
+			b.WriteString(up)
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+
+	out := b.String()
+	if out == "" || unicode.IsDigit(rune(out[0])) {
+		out = "Field" + out
+	}
+	return out
+}
+
+// describe documents a declaration derived from the spec, for those the
+// overlay does not document.
+func describe(name, kind, specName, description string) string {
+	doc := fmt.Sprintf("%s corresponds to the %q %s.", name, specName, kind)
+
+	description = strings.TrimSpace(description)
+	if description != "" {
+		description = strings.ToUpper(description[:1]) + description[1:]
+		if !strings.HasSuffix(description, ".") {
+			description += "."
+		}
+		doc += " " + description
+	}
+	return wrap(doc, 80)
+}
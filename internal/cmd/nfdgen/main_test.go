@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata/internal/openapi"
+)
+
+const moduleRoot = "../../.."
+
+// TestGeneratedFilesAreUpToDate fails when the spec or overlay changed
+// without re-running go generate, or a generated file was edited by hand.
+func TestGeneratedFilesAreUpToDate(t *testing.T) {
+	doc, err := openapi.Load(filepath.Join(moduleRoot, "docs", "openapi.json"))
+	require.NoError(t, err)
+	overlay, err := loadOverlay("overlay.json")
+	require.NoError(t, err)
+
+	files, err := generate(doc, overlay)
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for name, want := range files {
+		got, err := os.ReadFile(filepath.Join(moduleRoot, name))
+		if !assert.NoError(t, err, "%s is missing; run go generate ./...", name) {
+			continue
+		}
+		assert.Equal(t, string(want), string(got), "%s is stale; run go generate ./...", name)
+	}
+}
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"fld_ar_id":         "FldArID",
+		"flood.s_firm_pan":  "FloodSFirmPan",
+		"loma":              "LOMA",
+		"bfe_ln_id":         "BFELnID",
+		"1":                 "Field1",
+		"propertyelevation": "Propertyelevation",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, goName(in), in)
+	}
+}
+
+func TestEncodeField(t *testing.T) {
+	param := openapi.Parameter{Name: "size"}
+
+	assert.Equal(t,
+		"\tif o.Size != 0 {\n\t\tq.Set(\"size\", strconv.FormatFloat(o.Size, 'f', 2, 64))\n\t}\n",
+		encodeField(optionField{param: param, name: "Size", typ: "float64", prec: 2}))
+
+	param.Name = "loma"
+	assert.Equal(t,
+		"\tif o.LOMA {\n\t\tq.Set(\"loma\", \"true\")\n\t}\n",
+		encodeField(optionField{param: param, name: "LOMA", typ: "bool"}))
+
+	param.Name = "geojson"
+	assert.Equal(t,
+		"\tq.Set(\"geojson\", strconv.FormatBool(o.GeoJSON))\n",
+		encodeField(optionField{param: param, name: "GeoJSON", typ: "bool", always: true}))
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kmesiab/go-nationalflooddata/internal/openapi"
+)
+
+// modelsPackage is the package types are generated into by default.
+const modelsPackage = "models"
+
+// model is an object schema and the Go type it maps to.
+type model struct {
+	key     string
+	schema  *openapi.Schema
+	overlay ModelOverlay
+}
+
+// generateModels emits a type for every object schema in the document,
+// including inline objects nested in properties and array items.
+func generateModels(doc *openapi.Document, overlay *Overlay, files map[string]*file) error {
+	models := make(map[string]*model)
+
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := collectModels(doc, overlay, name, doc.Components.Schemas[name], models); err != nil {
+			return err
+		}
+	}
+
+	keys := make([]string, 0, len(models))
+	for key := range models {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		m := models[key]
+		if m.overlay.Handwritten {
+			continue
+		}
+
+		decl, imports, err := modelDecl(doc, m, models)
+		if err != nil {
+			return fmt.Errorf("schema %s: %w", key, err)
+		}
+
+		f := fileFor(files, m.overlay.Package, m.overlay.File)
+		f.decls = append(f.decls, decl)
+		for _, imp := range imports {
+			f.imports[imp] = true
+		}
+	}
+
+	return nil
+}
+
+// collectModels registers the schema at key, if it is an object, and
+// every inline object beneath it.
+func collectModels(doc *openapi.Document, overlay *Overlay, key string, s *openapi.Schema, models map[string]*model) error {
+	if s == nil || s.Ref != "" {
+		return nil
+	}
+	if s.Type == nil && s.Properties == nil && s.Schema != nil {
+		return collectModels(doc, overlay, key, s.Schema, models)
+	}
+
+	if s.Type.Is("array") {
+		return collectModels(doc, overlay, key, s.Items, models)
+	}
+	if s.Properties == nil {
+		return nil
+	}
+
+	o := overlay.Models[key]
+	if o.Type == "" {
+		parts := strings.Split(key, "/")
+		o.Type = goName(parts[len(parts)-1])
+	}
+	if o.Package == "" {
+		o.Package = modelsPackage
+	}
+	if o.File == "" {
+		o.File = strings.ToLower(o.Type) + ".go"
+	}
+	models[key] = &model{key: key, schema: s, overlay: o}
+
+	for _, name := range s.PropertyNames {
+		if err := collectModels(doc, overlay, key+"/"+name, s.Properties[name], models); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func modelDecl(doc *openapi.Document, m *model, models map[string]*model) (string, []string, error) {
+	var b strings.Builder
+	var imports []string
+
+	typeDoc := m.overlay.Doc
+	if typeDoc == "" {
+		typeDoc = describe(m.overlay.Type, "schema", m.key, m.schema.Description)
+	}
+	b.WriteString(comment(typeDoc, ""))
+	fmt.Fprintf(&b, "type %s struct {\n", m.overlay.Type)
+
+	for i, name := range m.schema.PropertyNames {
+		prop := m.schema.Properties[name]
+		fo := m.overlay.Fields[name]
+
+		field := fo.Name
+		if field == "" {
+			field = goName(name)
+		}
+
+		typ := fo.Type
+		if typ == "" {
+			var err error
+			typ, err = goType(doc, m.key+"/"+name, prop, m.overlay.Package, models)
+			if err != nil {
+				return "", nil, fmt.Errorf("property %s: %w", name, err)
+			}
+		}
+		if strings.Contains(typ, modelsPackage+".") {
+			imports = append(imports, "github.com/kmesiab/go-nationalflooddata/models")
+		}
+
+		fieldDoc := fo.Doc
+		if fieldDoc == "" {
+			resolved, err := doc.Resolve(prop)
+			if err != nil {
+				return "", nil, err
+			}
+			if resolved.Description != "" {
+				fieldDoc = describe(field, "property", name, resolved.Description)
+			}
+		}
+
+		tag := name
+		if fo.OmitEmpty {
+			tag += ",omitempty"
+		}
+
+		if i > 0 && fieldDoc != "" {
+			b.WriteString("\n")
+		}
+		b.WriteString(comment(fieldDoc, "\t"))
+		fmt.Fprintf(&b, "\t%s %s `json:%q`\n", field, typ, tag)
+	}
+
+	for _, extra := range m.overlay.Extra {
+		if extra.Doc != "" {
+			b.WriteString("\n")
+		}
+		b.WriteString(comment(extra.Doc, "\t"))
+		if extra.Tag != "" {
+			fmt.Fprintf(&b, "\t%s %s `%s`\n", extra.Name, extra.Type, extra.Tag)
+		} else {
+			fmt.Fprintf(&b, "\t%s %s\n", extra.Name, extra.Type)
+		}
+		if strings.Contains(extra.Type, modelsPackage+".") {
+			imports = append(imports, "github.com/kmesiab/go-nationalflooddata/models")
+		}
+	}
+
+	b.WriteString("}\n")
+	return b.String(), imports, nil
+}
+
+// goType derives the Go type of a property. key is where an inline object
+// at this property would be registered.
+func goType(doc *openapi.Document, key string, s *openapi.Schema, pkg string, models map[string]*model) (string, error) {
+	if s.Type == nil && s.Properties == nil && s.Ref == "" && s.Schema != nil {
+		s = s.Schema
+	}
+
+	if s.Ref != "" {
+		name, ok := strings.CutPrefix(s.Ref, "#/components/schemas/")
+		if !ok {
+			return "", fmt.Errorf("unsupported $ref %q", s.Ref)
+		}
+		m, ok := models[name]
+		if !ok {
+			resolved, err := doc.Resolve(s)
+			if err != nil {
+				return "", err
+			}
+			return goType(doc, name, resolved, pkg, models)
+		}
+		return qualify(m, pkg), nil
+	}
+
+	if s.Type.Is("array") {
+		if s.Items == nil {
+			return "[]interface{}", nil
+		}
+		item, err := goType(doc, key, s.Items, pkg, models)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + item, nil
+	}
+
+	if m, ok := models[key]; ok && s.Properties != nil {
+		name := qualify(m, pkg)
+		if s.IsNullable() {
+			name = "*" + name
+		}
+		return name, nil
+	}
+
+	var base string
+	switch {
+	case s.Type.Is("string"):
+		base = "string"
+	case s.Type.Is("integer"):
+		base = "int64"
+	case s.Type.Is("number"):
+		base = "float64"
+	case s.Type.Is("boolean"):
+		base = "bool"
+	default:
+		return "interface{}", nil
+	}
+
+	if s.IsNullable() {
+		return "*" + base, nil
+	}
+	return base, nil
+}
+
+func qualify(m *model, pkg string) string {
+	if m.overlay.Package == pkg {
+		return m.overlay.Type
+	}
+	return m.overlay.Package + "." + m.overlay.Type
+}
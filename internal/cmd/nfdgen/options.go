@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/kmesiab/go-nationalflooddata/internal/openapi"
+)
+
+// optionsPackage is the package options structs are generated into.
+const optionsPackage = "client"
+
+// optionField is a query parameter and the struct field that carries it.
+type optionField struct {
+	param  openapi.Parameter
+	name   string
+	typ    string
+	always bool
+	prec   int
+	doc    string
+}
+
+// generateOptions emits an options struct and a Values encoder for every
+// operation listed in the overlay's options.
+func generateOptions(doc *openapi.Document, overlay *Overlay, files map[string]*file) error {
+	for _, endpoint := range doc.Endpoints() {
+		o, ok := overlay.Options[endpoint.Operation.OperationID]
+		if !ok {
+			continue
+		}
+
+		var fields []optionField
+		for _, p := range endpoint.ParametersIn("query") {
+			field, err := newOptionField(doc, p, o.Fields[p.Name])
+			if err != nil {
+				return fmt.Errorf("%s parameter %s: %w", endpoint.Operation.OperationID, p.Name, err)
+			}
+			fields = append(fields, field)
+		}
+
+		name := o.File
+		if name == "" {
+			name = strings.ToLower(o.Type) + ".go"
+		}
+		f := fileFor(files, optionsPackage, name)
+		f.imports["net/url"] = true
+		for _, field := range fields {
+			if field.typ == "float64" || field.typ == "int" || (field.typ == "bool" && field.always) {
+				f.imports["strconv"] = true
+			}
+		}
+		f.decls = append(f.decls, optionsDecl(o, endpoint, fields))
+	}
+	return nil
+}
+
+func newOptionField(doc *openapi.Document, p openapi.Parameter, fo FieldOverlay) (optionField, error) {
+	s, err := doc.Resolve(p.Schema)
+	if err != nil {
+		return optionField{}, err
+	}
+
+	field := optionField{
+		param:  p,
+		name:   fo.Name,
+		typ:    fo.Type,
+		always: fo.Always || p.Required,
+		prec:   fo.Precision,
+		doc:    fo.Doc,
+	}
+	if field.name == "" {
+		field.name = goName(p.Name)
+	}
+	if field.doc == "" && p.Description != "" {
+		field.doc = describe(field.name, "parameter", p.Name, p.Description)
+	}
+
+	if field.typ == "" {
+		switch {
+		case s == nil, s.Type.Is("string"):
+			field.typ = "string"
+		case s.Type.Is("integer"):
+			field.typ = "int"
+		case s.Type.Is("number"):
+			field.typ = "float64"
+		case s.Type.Is("boolean"):
+			field.typ = "bool"
+		default:
+			return optionField{}, fmt.Errorf("unsupported type %v", s.Type)
+		}
+	}
+
+	// An optional boolean that defaults to true must be sent as false
+	// explicitly, or the API would apply its default.
+	if s != nil && s.Type.Is("boolean") && string(s.Default) == "true" {
+		field.always = true
+	}
+	return field, nil
+}
+
+func optionsDecl(o OptionsOverlay, endpoint openapi.Endpoint, fields []optionField) string {
+	var b strings.Builder
+
+	b.WriteString(comment(o.Doc, ""))
+	fmt.Fprintf(&b, "type %s struct {\n", o.Type)
+	for i, field := range fields {
+		if i > 0 && field.doc != "" {
+			b.WriteString("\n")
+		}
+		b.WriteString(comment(field.doc, "\t"))
+		fmt.Fprintf(&b, "\t%s %s\n", field.name, field.typ)
+	}
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// Values encodes the options as %s query parameters.\n", endpoint.Path)
+	fmt.Fprintf(&b, "func (o %s) Values() url.Values {\n", o.Type)
+	b.WriteString("\tq := url.Values{}\n")
+	for _, field := range fields {
+		b.WriteString(encodeField(field))
+	}
+	b.WriteString("\treturn q\n}\n")
+
+	return b.String()
+}
+
+// encodeField returns the statements that add field to q.
+func encodeField(f optionField) string {
+	v := "o." + f.name
+	var value, zero string
+
+	switch f.typ {
+	case "string":
+		value, zero = v, `""`
+	case "float64":
+		prec := -1
+		if f.prec > 0 {
+			prec = f.prec
+		}
+		value, zero = fmt.Sprintf("strconv.FormatFloat(%s, 'f', %d, 64)", v, prec), "0"
+	case "int":
+		value, zero = fmt.Sprintf("strconv.Itoa(%s)", v), "0"
+	case "bool":
+		if !f.always {
+			return fmt.Sprintf("\tif %s {\n\t\tq.Set(%q, \"true\")\n\t}\n", v, f.param.Name)
+		}
+		value = fmt.Sprintf("strconv.FormatBool(%s)", v)
+	default:
+		// Named string types such as SearchType.
+		value, zero = fmt.Sprintf("string(%s)", v), `""`
+	}
+
+	var b bytes.Buffer
+	if f.always {
+		fmt.Fprintf(&b, "\tq.Set(%q, %s)\n", f.param.Name, value)
+	} else {
+		fmt.Fprintf(&b, "\tif %s != %s {\n\t\tq.Set(%q, %s)\n\t}\n", v, zero, f.param.Name, value)
+	}
+	return b.String()
+}
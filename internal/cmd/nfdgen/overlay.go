@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Overlay holds the hand-written decisions the spec cannot express: Go
+// names, doc comments, pointer-ness for fields the sanitizer nils out, and
+// fields the live API returns but the spec omits.
+type Overlay struct {
+	// Options maps an operationId to the options struct generated for it.
+	// Operations without an entry get no options struct.
+	Options map[string]OptionsOverlay `json:"options"`
+
+	// Models maps a schema key to the type generated for it. A key is a
+	// component schema name, followed by "/property" for each inline
+	// object nested beneath it, e.g. "FemaResult/elevation".
+	Models map[string]ModelOverlay `json:"models"`
+}
+
+// OptionsOverlay describes an options struct and its query encoder.
+type OptionsOverlay struct {
+	Type   string                  `json:"type"`
+	File   string                  `json:"file"`
+	Doc    string                  `json:"doc"`
+	Fields map[string]FieldOverlay `json:"fields"`
+}
+
+// ModelOverlay describes a model type.
+type ModelOverlay struct {
+	Type    string `json:"type"`
+	Package string `json:"package"`
+	File    string `json:"file"`
+	Doc     string `json:"doc"`
+
+	// Handwritten types are referenced by generated code but not emitted,
+	// usually because the live API disagrees with the spec.
+	Handwritten bool `json:"handwritten"`
+
+	Fields map[string]FieldOverlay `json:"fields"`
+
+	// Extra are fields appended after the spec's properties.
+	Extra []ExtraField `json:"extra"`
+}
+
+// FieldOverlay overrides what is derived from a property or parameter.
+type FieldOverlay struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Doc       string `json:"doc"`
+	OmitEmpty bool   `json:"omitempty"`
+
+	// Always sends an optional query parameter even when it is the zero
+	// value, for parameters whose API default differs from Go's.
+	Always bool `json:"always"`
+
+	// Precision is the number of decimals used to encode a float query
+	// parameter. Zero means the shortest representation.
+	Precision int `json:"precision"`
+}
+
+// ExtraField is a struct field with no counterpart in the spec.
+type ExtraField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Tag  string `json:"tag"`
+	Doc  string `json:"doc"`
+}
+
+func loadOverlay(path string) (*Overlay, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading overlay: %w", err)
+	}
+
+	var o Overlay
+	if err := json.Unmarshal(raw, &o); err != nil {
+		return nil, fmt.Errorf("parsing overlay %s: %w", path, err)
+	}
+	return &o, nil
+}
@@ -0,0 +1,316 @@
+// Package openapi loads the National Flood Data OpenAPI document shipped in
+// docs/openapi.json. It models only the parts of OpenAPI 3.0 the document
+// uses, and is shared by the contract tests and the code generator.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Document is an OpenAPI document.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Components holds the reusable schemas of a Document.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// PathItem is the set of operations on a path.
+type PathItem struct {
+	Parameters []Parameter `json:"parameters"`
+	Get        *Operation  `json:"get"`
+	Post       *Operation  `json:"post"`
+}
+
+// Operation is a single API operation.
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary"`
+	Parameters  []Parameter         `json:"parameters"`
+	RequestBody *RequestBody        `json:"requestBody"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter is an operation parameter.
+type Parameter struct {
+	Name        string          `json:"name"`
+	In          string          `json:"in"`
+	Description string          `json:"description"`
+	Required    bool            `json:"required"`
+	Schema      *Schema         `json:"schema"`
+	Example     json.RawMessage `json:"example"`
+}
+
+// RequestBody is the body of an operation.
+type RequestBody struct {
+	Content map[string]MediaType `json:"content"`
+}
+
+// Response is an operation response.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+// MediaType is the content of a request or response for one media type.
+type MediaType struct {
+	Schema  *Schema         `json:"schema"`
+	Example json.RawMessage `json:"example"`
+}
+
+// Schema is a JSON schema.
+type Schema struct {
+	Ref         string             `json:"$ref"`
+	Type        Types              `json:"type"`
+	Format      string             `json:"format"`
+	Description string             `json:"description"`
+	Properties  map[string]*Schema `json:"properties"`
+	Items       *Schema            `json:"items"`
+	Enum        []json.RawMessage  `json:"enum"`
+	Default     json.RawMessage    `json:"default"`
+	Example     json.RawMessage    `json:"example"`
+	Minimum     *float64           `json:"minimum"`
+	Maximum     *float64           `json:"maximum"`
+	Nullable    bool               `json:"nullable"`
+
+	// Schema is set where the document describes a property the way it
+	// describes a parameter, wrapping the real schema. Resolve unwraps it.
+	Schema *Schema `json:"schema"`
+
+	// PropertyNames lists the keys of Properties in document order.
+	PropertyNames []string `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, recording the order of the
+// properties so generated code follows the document.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	type plain Schema
+	if err := json.Unmarshal(data, (*plain)(s)); err != nil {
+		return err
+	}
+
+	var raw struct {
+		Properties json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil || len(raw.Properties) == 0 {
+		return err
+	}
+
+	names, err := objectKeys(raw.Properties)
+	if err != nil {
+		return fmt.Errorf("schema properties: %w", err)
+	}
+	s.PropertyNames = names
+	return nil
+}
+
+// IsNullable reports whether the schema allows null.
+func (s *Schema) IsNullable() bool {
+	return s.Nullable || s.Type.Is("null")
+}
+
+// objectKeys returns the keys of a JSON object in order.
+func objectKeys(data []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, tok.(string))
+
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// Types is a schema type, which may be a single name or a list such as
+// ["object", "null"].
+type Types []string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = Types{one}
+		return nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("schema type: %w", err)
+	}
+	*t = many
+	return nil
+}
+
+// Is reports whether name is one of the types.
+func (t Types) Is(name string) bool {
+	for _, v := range t {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Load reads and parses the OpenAPI document at path.
+func Load(path string) (*Document, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading OpenAPI document: %w", err)
+	}
+	return Parse(raw)
+}
+
+// Parse parses an OpenAPI document.
+func Parse(raw []byte) (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("parsing OpenAPI document: %w", err)
+	}
+	return &doc, nil
+}
+
+// Endpoint is an operation together with its path and method.
+type Endpoint struct {
+	Path      string
+	Method    string
+	Operation *Operation
+
+	// Parameters are the path-level and operation parameters combined.
+	Parameters []Parameter
+}
+
+// Endpoints returns every operation in the document, sorted by path and
+// method.
+func (d *Document) Endpoints() []Endpoint {
+	var endpoints []Endpoint
+	for path, item := range d.Paths {
+		for method, op := range map[string]*Operation{"GET": item.Get, "POST": item.Post} {
+			if op == nil {
+				continue
+			}
+			params := append(append([]Parameter(nil), item.Parameters...), op.Parameters...)
+			endpoints = append(endpoints, Endpoint{Path: path, Method: method, Operation: op, Parameters: params})
+		}
+	}
+
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].Path != endpoints[j].Path {
+			return endpoints[i].Path < endpoints[j].Path
+		}
+		return endpoints[i].Method < endpoints[j].Method
+	})
+	return endpoints
+}
+
+// ParametersIn returns the endpoint's parameters of the given location,
+// such as "query", "path" or "header".
+func (e Endpoint) ParametersIn(in string) []Parameter {
+	var params []Parameter
+	for _, p := range e.Parameters {
+		if strings.EqualFold(p.In, in) {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+var pathParam = regexp.MustCompile(`\{[^}]+\}`)
+
+// Match reports whether a request path, relative to the server URL, matches
+// the endpoint's path template.
+func (e Endpoint) Match(path string) bool {
+	parts := pathParam.Split(e.Path, -1)
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, "[^/]+") + "$").MatchString(path)
+}
+
+// Resolve follows $ref links and parameter-style wrappers until it reaches
+// a concrete schema.
+func (d *Document) Resolve(s *Schema) (*Schema, error) {
+	for s != nil {
+		switch {
+		case s.Ref != "":
+			name, ok := strings.CutPrefix(s.Ref, "#/components/schemas/")
+			if !ok {
+				return nil, fmt.Errorf("unsupported $ref %q", s.Ref)
+			}
+			target, ok := d.Components.Schemas[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown schema %q", name)
+			}
+			s = target
+		case s.Type == nil && s.Properties == nil && s.Schema != nil:
+			s = s.Schema
+		default:
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+
+// Example builds an example value for s from the examples on its
+// properties. Properties without an example get the zero value of their
+// type, so every documented field appears in the result.
+func (d *Document) Example(s *Schema) (interface{}, error) {
+	s, err := d.Resolve(s)
+	if err != nil || s == nil {
+		return nil, err
+	}
+
+	if len(s.Example) > 0 {
+		var v interface{}
+		if err := json.Unmarshal(s.Example, &v); err != nil {
+			return nil, fmt.Errorf("parsing example: %w", err)
+		}
+		return v, nil
+	}
+
+	switch {
+	case s.Properties != nil || s.Type.Is("object"):
+		obj := make(map[string]interface{}, len(s.Properties))
+		for name, prop := range s.Properties {
+			v, err := d.Example(prop)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			obj[name] = v
+		}
+		return obj, nil
+	case s.Type.Is("array"):
+		item, err := d.Example(s.Items)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{item}, nil
+	case s.Type.Is("string"):
+		return "", nil
+	case s.Type.Is("number"), s.Type.Is("integer"):
+		return 0, nil
+	case s.Type.Is("boolean"):
+		return false, nil
+	}
+	return nil, nil
+}
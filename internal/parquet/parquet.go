@@ -0,0 +1,275 @@
+// Package parquet writes flat tables as Apache Parquet files. It supports
+// only what the exporters need: optional columns of strings, doubles,
+// booleans and 64-bit integers, written as one uncompressed row group with
+// PLAIN encoding, which every Parquet reader accepts.
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Type is the type of a column.
+type Type int
+
+// Column types.
+const (
+	String Type = iota
+	Double
+	Boolean
+	Int64
+)
+
+// Column describes one column of a table.
+type Column struct {
+	Name string
+	Type Type
+}
+
+// Parquet enum values, from parquet.thrift.
+const (
+	physicalBoolean   = 0
+	physicalInt64     = 2
+	physicalDouble    = 5
+	physicalByteArray = 6
+
+	repetitionOptional = 1
+	convertedUTF8      = 0
+	encodingPlain      = 0
+	encodingRLE        = 3
+	codecUncompressed  = 0
+	pageData           = 0
+)
+
+const magic = "PAR1"
+
+// createdBy is recorded in the file metadata.
+const createdBy = "go-nationalflooddata"
+
+func (t Type) physical() int32 {
+	switch t {
+	case Double:
+		return physicalDouble
+	case Boolean:
+		return physicalBoolean
+	case Int64:
+		return physicalInt64
+	default:
+		return physicalByteArray
+	}
+}
+
+// Write writes rows as a Parquet file. Each row holds one value per
+// column: nil for a null, or a string, float64, bool or int64 matching the
+// column's type.
+func Write(w io.Writer, columns []Column, rows [][]interface{}) error {
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			return fmt.Errorf("parquet: row %d has %d values, want %d", i, len(row), len(columns))
+		}
+	}
+
+	var file bytes.Buffer
+	file.WriteString(magic)
+
+	chunks := make([]chunk, len(columns))
+	for i, col := range columns {
+		page, err := encodePage(col, i, rows)
+		if err != nil {
+			return err
+		}
+
+		header := pageHeader(len(rows), len(page))
+		chunks[i] = chunk{
+			column: col,
+			offset: int64(file.Len()),
+			size:   int64(len(header) + len(page)),
+			values: int64(len(rows)),
+		}
+		file.Write(header)
+		file.Write(page)
+	}
+
+	footer := fileMetaData(columns, chunks, int64(len(rows)))
+	file.Write(footer)
+	_ = binary.Write(&file, binary.LittleEndian, uint32(len(footer)))
+	file.WriteString(magic)
+
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+// chunk locates a column chunk in the file.
+type chunk struct {
+	column Column
+	offset int64
+	size   int64
+	values int64
+}
+
+// encodePage encodes column i of rows as a v1 data page body: definition
+// levels followed by the PLAIN-encoded non-null values.
+func encodePage(col Column, i int, rows [][]interface{}) ([]byte, error) {
+	defined := make([]bool, len(rows))
+	var values bytes.Buffer
+	var bits []bool
+
+	for r, row := range rows {
+		v := row[i]
+		if v == nil {
+			continue
+		}
+		defined[r] = true
+
+		var ok bool
+		switch col.Type {
+		case String:
+			var s string
+			if s, ok = v.(string); ok {
+				_ = binary.Write(&values, binary.LittleEndian, uint32(len(s)))
+				values.WriteString(s)
+			}
+		case Double:
+			var f float64
+			if f, ok = v.(float64); ok {
+				_ = binary.Write(&values, binary.LittleEndian, math.Float64bits(f))
+			}
+		case Int64:
+			var n int64
+			if n, ok = v.(int64); ok {
+				_ = binary.Write(&values, binary.LittleEndian, n)
+			}
+		case Boolean:
+			var b bool
+			if b, ok = v.(bool); ok {
+				bits = append(bits, b)
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("parquet: row %d column %s: %T is not a %s", r, col.Name, v, col.Type)
+		}
+	}
+	if col.Type == Boolean {
+		values.Write(packBits(bits))
+	}
+
+	levels := bitPackedRun(defined)
+	var page bytes.Buffer
+	_ = binary.Write(&page, binary.LittleEndian, uint32(len(levels)))
+	page.Write(levels)
+	page.Write(values.Bytes())
+	return page.Bytes(), nil
+}
+
+func (t Type) String() string {
+	switch t {
+	case String:
+		return "string"
+	case Double:
+		return "double"
+	case Boolean:
+		return "boolean"
+	case Int64:
+		return "int64"
+	default:
+		return fmt.Sprintf("Type(%d)", int(t))
+	}
+}
+
+// bitPackedRun encodes 1-bit levels as a single bit-packed run of the
+// RLE/bit-packing hybrid encoding.
+func bitPackedRun(levels []bool) []byte {
+	groups := (len(levels) + 7) / 8
+
+	var b bytes.Buffer
+	var header [binary.MaxVarintLen64]byte
+	b.Write(header[:binary.PutUvarint(header[:], uint64(groups)<<1|1)])
+	// packBits pads the last group of eight with zeros.
+	b.Write(packBits(levels))
+	return b.Bytes()
+}
+
+// packBits packs bools LSB first.
+func packBits(bits []bool) []byte {
+	out := make([]byte, (len(bits)+7)/8)
+	for i, bit := range bits {
+		if bit {
+			out[i/8] |= 1 << (i % 8)
+		}
+	}
+	return out
+}
+
+func pageHeader(numValues, size int) []byte {
+	var c compact
+	c.begin()
+	c.i32(1, pageData)
+	c.i32(2, int32(size))
+	c.i32(3, int32(size))
+	c.structField(5)
+	c.i32(1, int32(numValues))
+	c.i32(2, encodingPlain)
+	c.i32(3, encodingRLE)
+	c.i32(4, encodingRLE)
+	c.end()
+	c.end()
+	return c.buf.Bytes()
+}
+
+func fileMetaData(columns []Column, chunks []chunk, numRows int64) []byte {
+	var c compact
+	c.begin()
+	c.i32(1, 1)
+
+	c.list(2, tStruct, len(columns)+1)
+	c.begin()
+	c.binary(4, "schema")
+	c.i32(5, int32(len(columns)))
+	c.end()
+	for _, col := range columns {
+		c.begin()
+		c.i32(1, col.Type.physical())
+		c.i32(3, repetitionOptional)
+		c.binary(4, col.Name)
+		if col.Type == String {
+			c.i32(6, convertedUTF8)
+		}
+		c.end()
+	}
+
+	c.i64(3, numRows)
+
+	var total int64
+	for _, ch := range chunks {
+		total += ch.size
+	}
+
+	c.list(4, tStruct, 1)
+	c.begin()
+	c.list(1, tStruct, len(chunks))
+	for _, ch := range chunks {
+		c.begin()
+		c.i64(2, ch.offset)
+		c.structField(3)
+		c.i32(1, ch.column.Type.physical())
+		c.listI32(2, encodingPlain, encodingRLE)
+		c.listBinary(3, ch.column.Name)
+		c.i32(4, codecUncompressed)
+		c.i64(5, ch.values)
+		c.i64(6, ch.size)
+		c.i64(7, ch.size)
+		c.i64(9, ch.offset)
+		c.end()
+		c.end()
+	}
+	c.i64(2, total)
+	c.i64(3, numRows)
+	c.end()
+
+	c.binary(6, createdBy)
+	c.end()
+	return c.buf.Bytes()
+}
@@ -0,0 +1,228 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite_RoundTrips(t *testing.T) {
+	columns := []Column{
+		{Name: "name", Type: String},
+		{Name: "score", Type: Double},
+		{Name: "ok", Type: Boolean},
+		{Name: "count", Type: Int64},
+	}
+	rows := [][]interface{}{
+		{"a", 1.5, true, int64(1)},
+		{nil, nil, nil, nil},
+		{"", -2.25, false, int64(-7)},
+	}
+	// More than one group of eight definition levels.
+	for i := 0; i < 10; i++ {
+		rows = append(rows, []interface{}{fmt.Sprint(i), nil, i%3 == 0, int64(i)})
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, columns, rows))
+
+	got := read(t, buf.Bytes())
+	assert.Equal(t, []string{"name", "score", "ok", "count"}, got.names)
+	assert.Equal(t, rows, got.rows)
+}
+
+func TestWrite_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, []Column{{Name: "a", Type: String}}, nil))
+
+	got := read(t, buf.Bytes())
+	assert.Equal(t, []string{"a"}, got.names)
+	assert.Empty(t, got.rows)
+}
+
+func TestWrite_RejectsMismatchedValues(t *testing.T) {
+	columns := []Column{{Name: "score", Type: Double}}
+
+	err := Write(&bytes.Buffer{}, columns, [][]interface{}{{"high"}})
+	assert.EqualError(t, err, "parquet: row 0 column score: string is not a double")
+
+	err = Write(&bytes.Buffer{}, columns, [][]interface{}{{1.0, 2.0}})
+	assert.EqualError(t, err, "parquet: row 0 has 2 values, want 1")
+}
+
+// table is a file decoded by read.
+type table struct {
+	names []string
+	rows  [][]interface{}
+}
+
+// read decodes a file written by Write, checking the structure a reader
+// relies on along the way.
+func read(t *testing.T, file []byte) table {
+	t.Helper()
+
+	require.Equal(t, magic, string(file[:4]))
+	require.Equal(t, magic, string(file[len(file)-4:]))
+	footerLen := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footerStart := len(file) - 8 - footerLen
+
+	meta := (&decoder{t: t, b: file[footerStart : len(file)-8]}).structure()
+	require.EqualValues(t, 1, meta[1])
+	numRows := int(meta[3].(int64))
+
+	schema := meta[2].([]interface{})
+	root := schema[0].(map[int16]interface{})
+	require.EqualValues(t, len(schema)-1, root[5])
+
+	var out table
+	var types []int64
+	for _, el := range schema[1:] {
+		el := el.(map[int16]interface{})
+		out.names = append(out.names, el[4].(string))
+		types = append(types, el[1].(int64))
+		require.EqualValues(t, repetitionOptional, el[3])
+	}
+
+	groups := meta[4].([]interface{})
+	require.Len(t, groups, 1)
+	group := groups[0].(map[int16]interface{})
+	require.EqualValues(t, numRows, group[3])
+	chunks := group[1].([]interface{})
+	require.Len(t, chunks, len(types))
+
+	out.rows = make([][]interface{}, numRows)
+	for i := range out.rows {
+		out.rows[i] = make([]interface{}, len(types))
+	}
+
+	for c, ch := range chunks {
+		md := ch.(map[int16]interface{})[3].(map[int16]interface{})
+		require.Equal(t, types[c], md[1])
+		require.EqualValues(t, numRows, md[5])
+
+		offset := int(md[9].(int64))
+		size := int(md[7].(int64))
+		d := &decoder{t: t, b: file[offset : offset+size]}
+		header := d.structure()
+		require.EqualValues(t, pageData, header[1])
+		page := d.b
+		require.EqualValues(t, len(page), header[3])
+
+		levelsLen := int(binary.LittleEndian.Uint32(page))
+		levels := page[4 : 4+levelsLen]
+		values := page[4+levelsLen:]
+
+		runHeader, n := binary.Uvarint(levels)
+		require.EqualValues(t, 1, runHeader&1, "bit-packed run")
+		bits := levels[n:]
+
+		var bit int
+		for r := 0; r < numRows; r++ {
+			if bits[r/8]&(1<<(r%8)) == 0 {
+				continue
+			}
+			switch types[c] {
+			case physicalByteArray:
+				l := int(binary.LittleEndian.Uint32(values))
+				out.rows[r][c] = string(values[4 : 4+l])
+				values = values[4+l:]
+			case physicalDouble:
+				out.rows[r][c] = math.Float64frombits(binary.LittleEndian.Uint64(values))
+				values = values[8:]
+			case physicalInt64:
+				out.rows[r][c] = int64(binary.LittleEndian.Uint64(values))
+				values = values[8:]
+			case physicalBoolean:
+				out.rows[r][c] = values[bit/8]&(1<<(bit%8)) != 0
+				bit++
+			}
+		}
+	}
+
+	return out
+}
+
+// decoder reads the Thrift compact protocol into maps of field id to
+// value, which is all the test needs to inspect the metadata.
+type decoder struct {
+	t *testing.T
+	b []byte
+}
+
+func (d *decoder) byte() byte {
+	require.NotEmpty(d.t, d.b, "unexpected end of input")
+	c := d.b[0]
+	d.b = d.b[1:]
+	return c
+}
+
+func (d *decoder) varint() uint64 {
+	v, n := binary.Uvarint(d.b)
+	require.Positive(d.t, n, "bad varint")
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *decoder) zigzag() int64 {
+	v := d.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (d *decoder) structure() map[int16]interface{} {
+	fields := make(map[int16]interface{})
+	var id int16
+	for {
+		h := d.byte()
+		if h == 0 {
+			return fields
+		}
+		typ := h & 0x0f
+		if delta := int16(h >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(d.zigzag())
+		}
+
+		switch typ {
+		case tBoolTrue:
+			fields[id] = true
+		case tBoolFalse:
+			fields[id] = false
+		default:
+			fields[id] = d.value(typ)
+		}
+	}
+}
+
+func (d *decoder) value(typ byte) interface{} {
+	switch typ {
+	case tI32, tI64:
+		return d.zigzag()
+	case tBinary:
+		n := int(d.varint())
+		s := string(d.b[:n])
+		d.b = d.b[n:]
+		return s
+	case tStruct:
+		return d.structure()
+	case tList:
+		h := d.byte()
+		n := int(h >> 4)
+		if n == 15 {
+			n = int(d.varint())
+		}
+		list := make([]interface{}, n)
+		for i := range list {
+			list[i] = d.value(h & 0x0f)
+		}
+		return list
+	default:
+		d.t.Fatalf("unsupported thrift type %d", typ)
+		return nil
+	}
+}
@@ -0,0 +1,8 @@
+// Package readcheck reads the Parquet files internal/parquet and
+// export.WriteParquet write back with github.com/xitongsys/parquet-go, an
+// independent reader, so the encoder is not only verified by a decoder
+// written alongside it. It is a separate module, so the reader stays out of
+// the main module's requirements. Run it from this directory:
+//
+//	go test ./...
+package readcheck
@@ -0,0 +1,109 @@
+package readcheck_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/export"
+	"github.com/kmesiab/go-nationalflooddata/internal/parquet"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+)
+
+// open reads file with parquet-go's column reader, checking the row count
+// and column names.
+func open(t *testing.T, file []byte, numRows int, names []string) *reader.ParquetReader {
+	t.Helper()
+
+	src, err := buffer.NewBufferFile(file)
+	require.NoError(t, err)
+	pr, err := reader.NewParquetColumnReader(src, 1)
+	require.NoError(t, err)
+	t.Cleanup(pr.ReadStop)
+
+	require.EqualValues(t, numRows, pr.GetNumRows())
+	require.Len(t, pr.SchemaHandler.Infos, len(names)+1)
+	for i, name := range names {
+		assert.Equal(t, name, pr.SchemaHandler.GetExName(i+1))
+	}
+	return pr
+}
+
+// column reads column c, with nil for null values.
+func column(t *testing.T, pr *reader.ParquetReader, c int) []interface{} {
+	t.Helper()
+
+	values, _, dls, err := pr.ReadColumnByIndex(int64(c), pr.GetNumRows())
+	require.NoError(t, err)
+	require.Len(t, dls, int(pr.GetNumRows()))
+
+	out := make([]interface{}, len(dls))
+	for r, dl := range dls {
+		if dl != 0 {
+			out[r] = values[r]
+		}
+	}
+	return out
+}
+
+func TestWrite(t *testing.T) {
+	columns := []parquet.Column{
+		{Name: "name", Type: parquet.String},
+		{Name: "score", Type: parquet.Double},
+		{Name: "ok", Type: parquet.Boolean},
+		{Name: "count", Type: parquet.Int64},
+	}
+	rows := [][]interface{}{
+		{"Lake Worth Lagoon", 1.5, true, int64(1)},
+		{nil, nil, nil, nil},
+		{"", -2.25, false, int64(-7)},
+	}
+	for i := 0; i < 10; i++ {
+		rows = append(rows, []interface{}{fmt.Sprint(i), nil, i%3 == 0, int64(i)})
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, parquet.Write(&buf, columns, rows))
+
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	pr := open(t, buf.Bytes(), len(rows), names)
+	for c, col := range columns {
+		want := make([]interface{}, len(rows))
+		for r := range rows {
+			want[r] = rows[r][c]
+		}
+		assert.Equal(t, want, column(t, pr, c), col.Name)
+	}
+}
+
+func TestWriteParquet(t *testing.T) {
+	rows := []export.Row{
+		export.Flatten(nfdtest.FloodData(t)),
+		export.Flatten(&client.Response{Status: "OK"}),
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteParquet(&buf, rows))
+
+	names := make([]string, len(export.Columns))
+	for i, c := range export.Columns {
+		names[i] = c.Name
+	}
+	pr := open(t, buf.Bytes(), len(rows), names)
+	for c, col := range export.Columns {
+		want := make([]interface{}, len(rows))
+		for r, row := range rows {
+			want[r] = row.Values()[c]
+		}
+		assert.Equal(t, want, column(t, pr, c), col.Name)
+	}
+}
@@ -0,0 +1,110 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Thrift compact protocol type ids.
+const (
+	tBoolTrue  = 1
+	tBoolFalse = 2
+	tI32       = 5
+	tI64       = 6
+	tBinary    = 8
+	tList      = 9
+	tStruct    = 12
+)
+
+// compact writes the subset of the Thrift compact protocol the Parquet
+// metadata needs. Fields must be written in increasing id order.
+type compact struct {
+	buf  bytes.Buffer
+	last []int16
+}
+
+func (c *compact) fieldHeader(id int16, typ byte) {
+	prev := &c.last[len(c.last)-1]
+	if delta := id - *prev; delta > 0 && delta <= 15 {
+		c.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		c.buf.WriteByte(typ)
+		c.varint(uint64(zigzag(int64(id))))
+	}
+	*prev = id
+}
+
+func (c *compact) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	c.buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func zigzag(v int64) uint64 { return uint64(v<<1) ^ uint64(v>>63) }
+
+func (c *compact) begin() { c.last = append(c.last, 0) }
+
+func (c *compact) end() {
+	c.buf.WriteByte(0)
+	c.last = c.last[:len(c.last)-1]
+}
+
+func (c *compact) i32(id int16, v int32) {
+	c.fieldHeader(id, tI32)
+	c.varint(zigzag(int64(v)))
+}
+
+func (c *compact) i64(id int16, v int64) {
+	c.fieldHeader(id, tI64)
+	c.varint(zigzag(v))
+}
+
+func (c *compact) bool(id int16, v bool) {
+	if v {
+		c.fieldHeader(id, tBoolTrue)
+	} else {
+		c.fieldHeader(id, tBoolFalse)
+	}
+}
+
+func (c *compact) binary(id int16, v string) {
+	c.fieldHeader(id, tBinary)
+	c.str(v)
+}
+
+func (c *compact) str(v string) {
+	c.varint(uint64(len(v)))
+	c.buf.WriteString(v)
+}
+
+// structField starts a nested struct field; close it with end.
+func (c *compact) structField(id int16) {
+	c.fieldHeader(id, tStruct)
+	c.begin()
+}
+
+// list writes a list field header for n elements of type elem.
+func (c *compact) list(id int16, elem byte, n int) {
+	c.fieldHeader(id, tList)
+	if n < 15 {
+		c.buf.WriteByte(byte(n)<<4 | elem)
+	} else {
+		c.buf.WriteByte(0xf0 | elem)
+		c.varint(uint64(n))
+	}
+}
+
+// listI32 writes a list<i32> field.
+func (c *compact) listI32(id int16, values ...int32) {
+	c.list(id, tI32, len(values))
+	for _, v := range values {
+		c.varint(zigzag(int64(v)))
+	}
+}
+
+// listBinary writes a list<string> field.
+func (c *compact) listBinary(id int16, values ...string) {
+	c.list(id, tBinary, len(values))
+	for _, v := range values {
+		c.str(v)
+	}
+}
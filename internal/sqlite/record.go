@@ -0,0 +1,164 @@
+package sqlite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// record encodes values in the SQLite record format.
+func record(values []interface{}) ([]byte, error) {
+	var types, body bytes.Buffer
+
+	for _, v := range values {
+		switch v := v.(type) {
+		case nil:
+			types.Write(varint(0))
+		case int:
+			writeInt(&types, &body, int64(v))
+		case int64:
+			writeInt(&types, &body, v)
+		case float64:
+			types.Write(varint(7))
+			_ = binary.Write(&body, binary.BigEndian, math.Float64bits(v))
+		case string:
+			types.Write(varint(uint64(len(v))*2 + 13))
+			body.WriteString(v)
+		case []byte:
+			types.Write(varint(uint64(len(v))*2 + 12))
+			body.Write(v)
+		default:
+			return nil, fmt.Errorf("unsupported value type %T", v)
+		}
+	}
+
+	// The header size includes its own varint.
+	size := types.Len() + 1
+	for len(varint(uint64(size)))+types.Len() != size {
+		size = len(varint(uint64(size))) + types.Len()
+	}
+
+	out := append(varint(uint64(size)), types.Bytes()...)
+	return append(out, body.Bytes()...), nil
+}
+
+func writeInt(types, body *bytes.Buffer, v int64) {
+	switch {
+	case v == 0:
+		types.Write(varint(8))
+	case v == 1:
+		types.Write(varint(9))
+	case v >= math.MinInt8 && v <= math.MaxInt8:
+		types.Write(varint(1))
+		body.WriteByte(byte(v))
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		types.Write(varint(2))
+		_ = binary.Write(body, binary.BigEndian, int16(v))
+	case v >= -1<<23 && v < 1<<23:
+		types.Write(varint(3))
+		body.Write([]byte{byte(v >> 16), byte(v >> 8), byte(v)})
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		types.Write(varint(4))
+		_ = binary.Write(body, binary.BigEndian, int32(v))
+	case v >= -1<<47 && v < 1<<47:
+		types.Write(varint(5))
+		_ = binary.Write(body, binary.BigEndian, uint16(v>>32))
+		_ = binary.Write(body, binary.BigEndian, uint32(v))
+	default:
+		types.Write(varint(6))
+		_ = binary.Write(body, binary.BigEndian, v)
+	}
+}
+
+// varint encodes v as a SQLite variable-length integer: big-endian groups
+// of seven bits, with a ninth byte holding eight.
+func varint(v uint64) []byte {
+	if v>>56 != 0 {
+		out := make([]byte, 9)
+		out[8] = byte(v)
+		v >>= 8
+		for i := 7; i >= 0; i-- {
+			out[i] = byte(v&0x7f) | 0x80
+			v >>= 7
+		}
+		return out
+	}
+
+	var tmp [9]byte
+	n := 0
+	for {
+		tmp[n] = byte(v&0x7f) | 0x80
+		n++
+		v >>= 7
+		if v == 0 {
+			break
+		}
+	}
+	tmp[0] &= 0x7f
+
+	out := make([]byte, n)
+	for i := range out {
+		out[i] = tmp[n-1-i]
+	}
+	return out
+}
+
+// compareKeys orders index keys as SQLite's BINARY collation does: NULLs,
+// then numbers, then text, then blobs.
+func compareKeys(a, b []interface{}) int {
+	for i := range a {
+		if c := compareValues(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareValues(a, b interface{}) int {
+	ra, rb := rank(a), rank(b)
+	if ra != rb {
+		return ra - rb
+	}
+
+	switch ra {
+	case 1:
+		fa, fb := number(a), number(b)
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	case 2:
+		return bytes.Compare([]byte(a.(string)), []byte(b.(string)))
+	case 3:
+		return bytes.Compare(a.([]byte), b.([]byte))
+	}
+	return 0
+}
+
+func rank(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case int, int64, float64:
+		return 1
+	case string:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func number(v interface{}) float64 {
+	switch v := v.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	default:
+		return v.(float64)
+	}
+}
@@ -0,0 +1,353 @@
+// Package sqlite writes SQLite database files without linking SQLite. It
+// builds the b-trees of tables whose contents are known up front, which is
+// all a GeoPackage export needs; there is no query engine, and indexes are
+// limited to the small automatic indexes of PRIMARY KEY and UNIQUE
+// constraints.
+//
+// The format is described at https://www.sqlite.org/fileformat.html.
+package sqlite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// PageSize is the page size of written databases.
+const PageSize = 4096
+
+// Database is the content of a database file.
+type Database struct {
+	// ApplicationID and UserVersion are stored in the file header.
+	ApplicationID uint32
+	UserVersion   uint32
+
+	// Tables are written to the schema in order, each followed by its
+	// automatic indexes.
+	Tables []Table
+
+	// Objects are schema entries without storage, such as virtual tables
+	// and triggers. They are written after Tables.
+	Objects []Object
+}
+
+// Table is an ordinary rowid table.
+type Table struct {
+	Name string
+
+	// SQL is the CREATE TABLE statement.
+	SQL string
+
+	// Rows must be in increasing ID order. A column declared INTEGER
+	// PRIMARY KEY aliases the rowid and should hold nil.
+	Rows []Row
+
+	// Unique lists the columns of each PRIMARY KEY and UNIQUE constraint
+	// that SQLite backs with an automatic index, in declaration order.
+	Unique [][]int
+}
+
+// Row is a table row. Values are nil, int64, int, float64, string or
+// []byte.
+type Row struct {
+	ID     int64
+	Values []interface{}
+}
+
+// Object is a schema entry without storage.
+type Object struct {
+	// Type is "table" for virtual tables, "trigger" or "view".
+	Type  string
+	Name  string
+	Table string
+	SQL   string
+}
+
+// Write writes db as a database file.
+func Write(w io.Writer, db *Database) error {
+	b := &builder{}
+	master := b.alloc()
+
+	var schema []Row
+	add := func(typ, name, table string, root int, sql interface{}) {
+		schema = append(schema, Row{
+			ID:     int64(len(schema) + 1),
+			Values: []interface{}{typ, name, table, root, sql},
+		})
+	}
+
+	for _, t := range db.Tables {
+		for i := 1; i < len(t.Rows); i++ {
+			if t.Rows[i].ID <= t.Rows[i-1].ID {
+				return fmt.Errorf("sqlite: table %s: rows are not in increasing ID order", t.Name)
+			}
+		}
+
+		root := b.alloc()
+		if err := b.tableTree(root, t.Rows); err != nil {
+			return fmt.Errorf("sqlite: table %s: %w", t.Name, err)
+		}
+		add("table", t.Name, t.Name, root, t.SQL)
+
+		for i, columns := range t.Unique {
+			name := fmt.Sprintf("sqlite_autoindex_%s_%d", t.Name, i+1)
+			root := b.alloc()
+			if err := b.indexTree(root, t.Rows, columns); err != nil {
+				return fmt.Errorf("sqlite: index %s: %w", name, err)
+			}
+			add("index", name, t.Name, root, nil)
+		}
+	}
+
+	for _, o := range db.Objects {
+		table := o.Table
+		if table == "" {
+			table = o.Name
+		}
+		add(o.Type, o.Name, table, 0, o.SQL)
+	}
+
+	if err := b.tableTree(master, schema); err != nil {
+		return fmt.Errorf("sqlite: schema: %w", err)
+	}
+
+	b.header(db)
+	for _, p := range b.pages {
+		if _, err := w.Write(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// builder accumulates pages; page n is pages[n-1].
+type builder struct {
+	pages [][]byte
+}
+
+func (b *builder) alloc() int {
+	b.pages = append(b.pages, make([]byte, PageSize))
+	return len(b.pages)
+}
+
+func (b *builder) page(n int) []byte { return b.pages[n-1] }
+
+// header fills in the 100-byte database header on page 1.
+func (b *builder) header(db *Database) {
+	h := b.page(1)[:100]
+	copy(h, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(h[16:], PageSize)
+	h[18], h[19] = 1, 1 // legacy (rollback journal) file format
+	h[21], h[22], h[23] = 64, 32, 32
+	binary.BigEndian.PutUint32(h[24:], 1) // file change counter
+	binary.BigEndian.PutUint32(h[28:], uint32(len(b.pages)))
+	binary.BigEndian.PutUint32(h[40:], 1) // schema cookie
+	binary.BigEndian.PutUint32(h[44:], 4) // schema format
+	binary.BigEndian.PutUint32(h[56:], 1) // UTF-8
+	binary.BigEndian.PutUint32(h[60:], db.UserVersion)
+	binary.BigEndian.PutUint32(h[68:], db.ApplicationID)
+	binary.BigEndian.PutUint32(h[92:], 1) // version-valid-for
+	binary.BigEndian.PutUint32(h[96:], sqliteVersion)
+}
+
+// sqliteVersion is the SQLITE_VERSION_NUMBER recorded as the last writer.
+const sqliteVersion = 3040001
+
+// Page types.
+const (
+	interiorIndex = 0x02
+	interiorTable = 0x05
+	leafIndex     = 0x0a
+	leafTable     = 0x0d
+)
+
+// offset is where the b-tree page header starts on page n.
+func offset(n int) int {
+	if n == 1 {
+		return 100
+	}
+	return 0
+}
+
+// fits reports whether cells fit on page n with a header of size header.
+func fits(n, header int, cells [][]byte) bool {
+	used := offset(n) + header
+	for _, c := range cells {
+		used += 2 + len(c)
+	}
+	return used <= PageSize
+}
+
+// writePage lays out a b-tree page.
+func (b *builder) writePage(n int, typ byte, cells [][]byte, right int) {
+	p := b.page(n)
+	off := offset(n)
+
+	header := 8
+	if typ == interiorTable || typ == interiorIndex {
+		header = 12
+		binary.BigEndian.PutUint32(p[off+8:], uint32(right))
+	}
+	p[off] = typ
+	binary.BigEndian.PutUint16(p[off+3:], uint16(len(cells)))
+
+	content := PageSize
+	for i, c := range cells {
+		content -= len(c)
+		copy(p[content:], c)
+		binary.BigEndian.PutUint16(p[off+header+2*i:], uint16(content))
+	}
+	if content == 65536 {
+		content = 0
+	}
+	binary.BigEndian.PutUint16(p[off+5:], uint16(content))
+}
+
+// child is a page in a level of a table b-tree and the largest rowid in
+// it.
+type child struct {
+	page  int
+	rowid int64
+}
+
+// tableTree writes a table b-tree rooted at page root.
+func (b *builder) tableTree(root int, rows []Row) error {
+	cells := make([][]byte, len(rows))
+	for i, row := range rows {
+		payload, err := record(row.Values)
+		if err != nil {
+			return fmt.Errorf("row %d: %w", row.ID, err)
+		}
+		local, overflow := b.spill(payload, PageSize-35)
+
+		var c bytes.Buffer
+		c.Write(varint(uint64(len(payload))))
+		c.Write(varint(uint64(row.ID)))
+		c.Write(local)
+		if overflow != 0 {
+			_ = binary.Write(&c, binary.BigEndian, uint32(overflow))
+		}
+		cells[i] = c.Bytes()
+	}
+
+	if fits(root, 8, cells) {
+		b.writePage(root, leafTable, cells, 0)
+		return nil
+	}
+
+	// Pack leaves greedily, then build interior levels until one fits in
+	// the root.
+	var level []child
+	for start := 0; start < len(cells); {
+		end := start + 1
+		for end < len(cells) && fits(2, 8, cells[start:end+1]) {
+			end++
+		}
+		n := b.alloc()
+		b.writePage(n, leafTable, cells[start:end], 0)
+		level = append(level, child{page: n, rowid: rows[end-1].ID})
+		start = end
+	}
+
+	for {
+		divider := func(c child) []byte {
+			var buf bytes.Buffer
+			_ = binary.Write(&buf, binary.BigEndian, uint32(c.page))
+			buf.Write(varint(uint64(c.rowid)))
+			return buf.Bytes()
+		}
+
+		dividers := make([][]byte, len(level)-1)
+		for i := range dividers {
+			dividers[i] = divider(level[i])
+		}
+		if fits(root, 12, dividers) {
+			b.writePage(root, interiorTable, dividers, level[len(level)-1].page)
+			return nil
+		}
+
+		var next []child
+		for start := 0; start < len(level); {
+			end := start + 1
+			for end < len(level) && fits(2, 12, dividers[start:end]) {
+				end++
+			}
+			n := b.alloc()
+			b.writePage(n, interiorTable, dividers[start:end-1], level[end-1].page)
+			next = append(next, child{page: n, rowid: level[end-1].rowid})
+			start = end
+		}
+		level = next
+	}
+}
+
+// indexTree writes the index of columns in rows as a b-tree rooted at
+// page root. The index must fit on one page.
+func (b *builder) indexTree(root int, rows []Row, columns []int) error {
+	keys := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		key := make([]interface{}, 0, len(columns)+1)
+		for _, c := range columns {
+			key = append(key, row.Values[c])
+		}
+		keys[i] = append(key, row.ID)
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return compareKeys(keys[i], keys[j]) < 0 })
+
+	maxLocal := (PageSize-12)*64/255 - 23
+	cells := make([][]byte, len(keys))
+	for i, key := range keys {
+		payload, err := record(key)
+		if err != nil {
+			return err
+		}
+		local, overflow := b.spill(payload, maxLocal)
+
+		var c bytes.Buffer
+		c.Write(varint(uint64(len(payload))))
+		c.Write(local)
+		if overflow != 0 {
+			_ = binary.Write(&c, binary.BigEndian, uint32(overflow))
+		}
+		cells[i] = c.Bytes()
+	}
+
+	if !fits(root, 8, cells) {
+		return fmt.Errorf("%d entries do not fit on one page", len(cells))
+	}
+	b.writePage(root, leafIndex, cells, 0)
+	return nil
+}
+
+// spill returns the part of payload stored on the b-tree page and the
+// first page of an overflow chain holding the rest, or 0 if it all fits.
+func (b *builder) spill(payload []byte, maxLocal int) ([]byte, int) {
+	if len(payload) <= maxLocal {
+		return payload, 0
+	}
+
+	usable := PageSize
+	minLocal := (usable-12)*32/255 - 23
+	local := minLocal + (len(payload)-minLocal)%(usable-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+
+	rest := payload[local:]
+	first := b.alloc()
+	for n := first; ; {
+		p := b.page(n)
+		chunk := min(len(rest), usable-4)
+		copy(p[4:], rest[:chunk])
+		rest = rest[chunk:]
+		if len(rest) == 0 {
+			break
+		}
+		next := b.alloc()
+		binary.BigEndian.PutUint32(p, uint32(next))
+		n = next
+	}
+	return payload[:local], first
+}
@@ -0,0 +1,138 @@
+package sqlite
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVarint(t *testing.T) {
+	tests := []struct {
+		v    uint64
+		want []byte
+	}{
+		{0, []byte{0x00}},
+		{0x7f, []byte{0x7f}},
+		{0x80, []byte{0x81, 0x00}},
+		{300, []byte{0x82, 0x2c}},
+		{1 << 56, []byte{0x80, 0xc0, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00}},
+		{^uint64(0), []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, varint(tt.v), "%d", tt.v)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	got, err := record([]interface{}{nil, int64(0), 1, 200, "hi", []byte{0xab}, 1.5})
+	require.NoError(t, err)
+
+	want := []byte{
+		8,                     // header size
+		0, 8, 9, 2, 17, 14, 7, // serial types
+		0x00, 0xc8, // 200
+		'h', 'i',
+		0xab,
+		0x3f, 0xf8, 0, 0, 0, 0, 0, 0, // 1.5
+	}
+	assert.Equal(t, want, got)
+
+	_, err = record([]interface{}{true})
+	assert.EqualError(t, err, "unsupported value type bool")
+}
+
+func TestWrite_RejectsUnorderedRows(t *testing.T) {
+	err := Write(&bytes.Buffer{}, &Database{Tables: []Table{{
+		Name: "t",
+		SQL:  "CREATE TABLE t(a)",
+		Rows: []Row{{ID: 2}, {ID: 1}},
+	}}})
+	assert.EqualError(t, err, "sqlite: table t: rows are not in increasing ID order")
+}
+
+// sqlite3 runs the sqlite3 shell on a database written from db.
+func sqlite3(t *testing.T, db *Database, sql string) string {
+	t.Helper()
+
+	bin, err := exec.LookPath("sqlite3")
+	if err != nil {
+		t.Skip("sqlite3 not installed")
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, db))
+	assert.Zero(t, buf.Len()%PageSize)
+
+	path := filepath.Join(t.TempDir(), "test.db")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+
+	out, err := exec.Command(bin, "-bail", path, sql).CombinedOutput()
+	require.NoError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+func TestWrite_ReadBySQLite(t *testing.T) {
+	// Enough rows for interior pages, and payloads that overflow.
+	var rows []Row
+	for i := int64(-5); i < 3000; i++ {
+		data := bytes.Repeat([]byte{byte(i)}, int(max(i, 0)%7)*1000)
+		rows = append(rows, Row{ID: i, Values: []interface{}{nil, "row", i * 3, float64(i) / 2, data}})
+	}
+
+	db := &Database{
+		ApplicationID: 0x47504b47,
+		UserVersion:   10300,
+		Tables: []Table{
+			{
+				Name: "big",
+				SQL:  "CREATE TABLE big(id INTEGER PRIMARY KEY, name TEXT, n INTEGER, f REAL, data BLOB)",
+				Rows: rows,
+			},
+			{
+				Name: "keyed",
+				SQL:  "CREATE TABLE keyed(a TEXT PRIMARY KEY, b TEXT UNIQUE, c, UNIQUE(c, a))",
+				Rows: []Row{
+					{ID: 1, Values: []interface{}{"z", nil, 2}},
+					{ID: 2, Values: []interface{}{"a", "x", nil}},
+					{ID: 3, Values: []interface{}{"m", "y", 1.5}},
+				},
+				Unique: [][]int{{0}, {1}, {2, 0}},
+			},
+		},
+		Objects: []Object{
+			{Type: "view", Name: "v", SQL: "CREATE VIEW v AS SELECT a FROM keyed"},
+			{Type: "trigger", Name: "tr", Table: "keyed", SQL: "CREATE TRIGGER tr AFTER DELETE ON keyed BEGIN SELECT 1; END"},
+		},
+	}
+
+	out := sqlite3(t, db, `PRAGMA integrity_check;
+PRAGMA application_id;
+PRAGMA user_version;
+SELECT count(*), min(id), max(id), sum(n), sum(length(data)) FROM big;
+SELECT name, n, f, hex(substr(data, 1, 2)) FROM big WHERE id = 2999;
+SELECT a FROM keyed WHERE b = 'y';
+SELECT group_concat(a) FROM (SELECT a FROM v ORDER BY a);
+SELECT name FROM sqlite_master WHERE type = 'trigger';`)
+
+	assert.Equal(t, strings.Join([]string{
+		"ok",
+		"1196444487",
+		"10300",
+		"3005|-5|2999|13495455|8994000",
+		"row|8997|1499.5|B7B7",
+		"m",
+		"a,m,z",
+		"tr",
+	}, "\n"), out)
+}
+
+func TestWrite_Empty(t *testing.T) {
+	out := sqlite3(t, &Database{}, "PRAGMA integrity_check; SELECT count(*) FROM sqlite_master;")
+	assert.Equal(t, "ok\n0", out)
+}
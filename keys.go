@@ -0,0 +1,253 @@
+package go_nationalflooddata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+)
+
+// ErrNoKeyAvailable is returned by a KeyPool when no key is both licensed for
+// a request and currently in service.
+var ErrNoKeyAvailable = errors.New("no API key available")
+
+// DefaultKeyCooldown is how long a KeyPool sidelines a key when none is given.
+const DefaultKeyCooldown = 15 * time.Minute
+
+// DefaultSidelineStatuses are the API error statuses after which a KeyPool
+// sidelines a key: 401, a rejected key, and 402, which the API returns
+// when a key has used up its quota as well as when it has no data.
+var DefaultSidelineStatuses = []int{401, 402}
+
+// Entitlement is a set of add-on datasets an API key is licensed for.
+type Entitlement uint
+
+const (
+	// EntitlementProperty licenses the property add-on (FloodDataOptions.Property).
+	EntitlementProperty Entitlement = 1 << iota
+
+	// EntitlementParcel licenses the parcel add-on (FloodDataOptions.Parcel).
+	EntitlementParcel
+)
+
+// Has reports whether e includes every entitlement in other.
+func (e Entitlement) Has(other Entitlement) bool {
+	return e&other == other
+}
+
+// KeyRequest describes the call an API key is being chosen for.
+type KeyRequest struct {
+	// Endpoint is the Service method making the call, such as EndpointGetFloodData.
+	Endpoint string
+
+	// Entitlements are the add-on datasets the call needs the key to be licensed for.
+	Entitlements Entitlement
+}
+
+// KeyProvider supplies the API key for each call. When a Service has a
+// KeyProvider, it is consulted instead of Service.APIKey.
+type KeyProvider interface {
+	// Key returns the API key to use for req.
+	Key(ctx context.Context, req KeyRequest) (string, error)
+
+	// Report is called with the outcome of every call made with key. err is
+	// nil on success.
+	Report(key string, err error)
+}
+
+// entitlementsFor returns the entitlements needed by the typed options of a call.
+func entitlementsFor(opts interface{}) Entitlement {
+	var e Entitlement
+
+	switch o := opts.(type) {
+	case client.FloodDataOptions:
+		if o.Property {
+			e |= EntitlementProperty
+		}
+		if o.Parcel {
+			e |= EntitlementParcel
+		}
+	case client.BatchDataRequest:
+		for _, r := range o.Requests {
+			if r.Property {
+				e |= EntitlementProperty
+			}
+			if r.Parcel {
+				e |= EntitlementParcel
+			}
+		}
+	}
+
+	return e
+}
+
+// apiKey returns the key to use for a call, consulting the KeyProvider if one
+// is configured.
+func (s *Service) apiKey(ctx context.Context, endpoint string, opts interface{}) (string, error) {
+	if s.Keys == nil {
+		return s.APIKey, nil
+	}
+
+	key, err := s.Keys.Key(ctx, KeyRequest{Endpoint: endpoint, Entitlements: entitlementsFor(opts)})
+	if err != nil {
+		return "", fmt.Errorf("selecting API key: %w", err)
+	}
+
+	return key, nil
+}
+
+// KeyPoolStrategy decides which eligible key a KeyPool hands out next.
+type KeyPoolStrategy int
+
+const (
+	// RoundRobin cycles through eligible keys in the order they were added.
+	RoundRobin KeyPoolStrategy = iota
+
+	// LeastUsed picks the eligible key that has served the fewest calls.
+	LeastUsed
+)
+
+// PooledKey is an API key together with the add-ons it is licensed for.
+type PooledKey struct {
+	Key          string
+	Entitlements Entitlement
+}
+
+// KeyUsage is a snapshot of how a pooled key has been used.
+type KeyUsage struct {
+	Key            string
+	Calls          int
+	Failures       int
+	SidelinedUntil time.Time
+}
+
+// KeyPool is a KeyProvider that spreads calls across several API keys. A key
+// that fails with one of the pool's sideline statuses is sidelined for the
+// pool's cooldown, and calls needing property or parcel data only go to keys
+// licensed for them.
+type KeyPool struct {
+	strategy KeyPoolStrategy
+	cooldown time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	keys     []*KeyUsage
+	ents     []Entitlement
+	next     int
+	sideline []int
+}
+
+// NewKeyPool returns a KeyPool over keys. A cooldown of zero uses
+// DefaultKeyCooldown.
+func NewKeyPool(strategy KeyPoolStrategy, cooldown time.Duration, keys ...PooledKey) *KeyPool {
+	if cooldown <= 0 {
+		cooldown = DefaultKeyCooldown
+	}
+
+	p := &KeyPool{
+		strategy: strategy,
+		cooldown: cooldown,
+		now:      time.Now,
+		sideline: DefaultSidelineStatuses,
+	}
+	for _, k := range keys {
+		p.keys = append(p.keys, &KeyUsage{Key: k.Key})
+		p.ents = append(p.ents, k.Entitlements)
+	}
+
+	return p
+}
+
+// SidelineOn replaces the API error statuses after which the pool sidelines
+// a key, DefaultSidelineStatuses unless changed. Pools whose lookups often
+// find no data can pass just 401, so a 402 does not take a key out of
+// service.
+func (p *KeyPool) SidelineOn(statuses ...int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sideline = append([]int(nil), statuses...)
+}
+
+// Key implements KeyProvider.
+func (p *KeyPool) Key(_ context.Context, req KeyRequest) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	chosen := -1
+
+	for i := range p.keys {
+		// Round robin starts scanning just after the last key handed out.
+		idx := i
+		if p.strategy == RoundRobin {
+			idx = (p.next + i) % len(p.keys)
+		}
+
+		k := p.keys[idx]
+		if !p.ents[idx].Has(req.Entitlements) || now.Before(k.SidelinedUntil) {
+			continue
+		}
+
+		if p.strategy == RoundRobin {
+			chosen = idx
+			break
+		}
+		if chosen == -1 || k.Calls < p.keys[chosen].Calls {
+			chosen = idx
+		}
+	}
+
+	if chosen == -1 {
+		return "", fmt.Errorf("%w for %s", ErrNoKeyAvailable, req.Endpoint)
+	}
+
+	p.next = chosen + 1
+	p.keys[chosen].Calls++
+
+	return p.keys[chosen].Key, nil
+}
+
+// Report implements KeyProvider.
+func (p *KeyPool) Report(key string, err error) {
+	if err == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	sideline := false
+	var apiErr interface{ StatusCode() int }
+	if errors.As(err, &apiErr) {
+		for _, status := range p.sideline {
+			sideline = sideline || apiErr.StatusCode() == status
+		}
+	}
+
+	for _, k := range p.keys {
+		if k.Key != key {
+			continue
+		}
+		k.Failures++
+		if sideline {
+			k.SidelinedUntil = p.now().Add(p.cooldown)
+		}
+	}
+}
+
+// Usage returns a snapshot of every key in the pool, in the order added.
+func (p *KeyPool) Usage() []KeyUsage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	usage := make([]KeyUsage, len(p.keys))
+	for i, k := range p.keys {
+		usage[i] = *k
+	}
+
+	return usage
+}
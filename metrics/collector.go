@@ -0,0 +1,199 @@
+// Package metrics exposes Prometheus metrics describing how a National Flood
+// Data Service is being used: request counts, latency, typed errors and
+// in-flight requests, all broken down by API endpoint.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+	"github.com/kmesiab/go-nationalflooddata/client"
+)
+
+// Endpoint label values. Tile paths carry coordinates, so every request is
+// folded into one of these names to keep label cardinality bounded.
+const (
+	EndpointData            = "data"
+	EndpointDataBatch       = "databatch"
+	EndpointFloodMapRaw     = "floodmapraw"
+	EndpointFloodVectorTile = "flood_vector_tile"
+	EndpointStormSurgeTile  = "storm_surge_tile"
+	EndpointStaticFloodMap  = "staticmap"
+	EndpointDynamicFloodMap = "dynamic"
+	EndpointOther           = "other"
+)
+
+// Collector is a prometheus.Collector that instruments every HTTP call made
+// by a Service. Register it with a prometheus.Registerer after creating it
+// with NewCollector.
+type Collector struct {
+	basePath string
+	next     http.RoundTripper
+
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+	inFlight *prometheus.GaugeVec
+}
+
+// NewCollector instruments svc and returns the Collector holding its metrics.
+// The Service's HTTPClient is replaced by a shallow copy whose transport records
+// each request, so a shared client such as http.DefaultClient is never mutated.
+func NewCollector(svc *nfd.Service) *Collector {
+	base := svc.HTTPClient
+	if base == nil {
+		base = http.DefaultClient
+	}
+
+	next := base.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	basePath := ""
+	if u, err := url.Parse(svc.BaseURL); err == nil {
+		basePath = strings.TrimSuffix(u.Path, "/")
+	}
+
+	c := &Collector{
+		basePath: basePath,
+		next:     next,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "nfd",
+			Name:      "requests_total",
+			Help:      "Total number of National Flood Data API requests by endpoint and HTTP status code.",
+		}, []string{"endpoint", "code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "nfd",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of National Flood Data API requests by endpoint.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "nfd",
+			Name:      "request_errors_total",
+			Help:      "Total number of failed National Flood Data API requests by endpoint and error class.",
+		}, []string{"endpoint", "class"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "nfd",
+			Name:      "requests_in_flight",
+			Help:      "Number of National Flood Data API requests currently in flight by endpoint.",
+		}, []string{"endpoint"}),
+	}
+
+	instrumented := *base
+	instrumented.Transport = c
+	svc.HTTPClient = &instrumented
+
+	return c
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.latency.Describe(ch)
+	c.errors.Describe(ch)
+	c.inFlight.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.latency.Collect(ch)
+	c.errors.Collect(ch)
+	c.inFlight.Collect(ch)
+}
+
+// RoundTrip implements http.RoundTripper, recording metrics around the
+// wrapped transport.
+func (c *Collector) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := c.endpoint(req.URL.Path)
+
+	inFlight := c.inFlight.WithLabelValues(endpoint)
+	inFlight.Inc()
+	defer inFlight.Dec()
+
+	start := time.Now()
+	resp, err := c.next.RoundTrip(req)
+	c.latency.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		c.requests.WithLabelValues(endpoint, "error").Inc()
+		c.errors.WithLabelValues(endpoint, "transport").Inc()
+		return resp, err
+	}
+
+	c.requests.WithLabelValues(endpoint, strconv.Itoa(resp.StatusCode)).Inc()
+	if resp.StatusCode >= 400 {
+		apiErr := nfd.ParseError(&client.ErrorResponse{Response: resp, Status: resp.StatusCode})
+		c.errors.WithLabelValues(endpoint, ErrorClass(apiErr)).Inc()
+	}
+
+	return resp, nil
+}
+
+// endpoint maps a request path onto one of the Endpoint label values.
+func (c *Collector) endpoint(path string) string {
+	path = strings.TrimPrefix(path, c.basePath)
+
+	switch {
+	case path == "/data":
+		return EndpointData
+	case path == "/databatch":
+		return EndpointDataBatch
+	case path == "/floodmapraw":
+		return EndpointFloodMapRaw
+	case strings.HasPrefix(path, "/tiles/flood-vector/"):
+		return EndpointFloodVectorTile
+	case strings.HasPrefix(path, "/tiles/stormsurge/"):
+		return EndpointStormSurgeTile
+	case path == "/staticmap":
+		return EndpointStaticFloodMap
+	case path == "/dynamic.html":
+		return EndpointDynamicFloodMap
+	default:
+		return EndpointOther
+	}
+}
+
+// ErrorClass returns the label value used for err in the
+// nfd_request_errors_total metric. Typed API errors map to their class,
+// any other *client.ErrorResponse is "api_error" and everything else is
+// reported as a "transport" failure.
+func ErrorClass(err error) string {
+	var (
+		invalidRequest  *client.InvalidRequestError
+		authentication  *client.AuthenticationError
+		noData          *client.NoDataAvailableError
+		locationMissing *client.LocationNotFoundError
+		parcelMissing   *client.ParcelNotFoundError
+		internal        *client.InternalServerError
+		generic         *client.ErrorResponse
+	)
+
+	switch {
+	case errors.As(err, &invalidRequest):
+		return "invalid_request"
+	case errors.As(err, &authentication):
+		return "authentication"
+	case errors.As(err, &noData):
+		return "no_data_available"
+	case errors.As(err, &locationMissing):
+		return "location_not_found"
+	case errors.As(err, &parcelMissing):
+		return "parcel_not_found"
+	case errors.As(err, &internal):
+		return "internal_server"
+	case errors.As(err, &generic):
+		return "api_error"
+	default:
+		return "transport"
+	}
+}
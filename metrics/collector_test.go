@@ -0,0 +1,136 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/metrics"
+)
+
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func newTestService(t *testing.T) *nfd.Service {
+	t.Helper()
+
+	service, err := nfd.NewService("test-api-key")
+	require.NoError(t, err)
+
+	return service
+}
+
+func newInstrumentedService(t *testing.T, status int, body string) (*nfd.Service, *metrics.Collector) {
+	t.Helper()
+
+	service := newTestService(t)
+	service.HTTPClient = &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: status,
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Header:     make(http.Header),
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	collector := metrics.NewCollector(service)
+	require.NoError(t, prometheus.NewRegistry().Register(collector))
+
+	return service, collector
+}
+
+func TestNewCollector_ShouldNotMutateDefaultHTTPClient(t *testing.T) {
+	service := newTestService(t)
+	metrics.NewCollector(service)
+
+	assert.NotSame(t, http.DefaultClient, service.HTTPClient)
+	assert.Nil(t, http.DefaultClient.Transport)
+}
+
+func TestCollector_ShouldCountRequestsPerEndpoint(t *testing.T) {
+	service, collector := newInstrumentedService(t, http.StatusOK, `{}`)
+
+	_, err := service.GetFloodVectorTile(context.Background(), 13, 2043, 3140)
+	require.NoError(t, err)
+	_, err = service.GetFloodVectorTile(context.Background(), 13, 2044, 3140)
+	require.NoError(t, err)
+	_, err = service.GetStaticFloodMap(context.Background(), client.StaticMapOptions{Lat: 1, Lng: 1})
+	require.NoError(t, err)
+
+	expected := `
+# HELP nfd_requests_total Total number of National Flood Data API requests by endpoint and HTTP status code.
+# TYPE nfd_requests_total counter
+nfd_requests_total{code="200",endpoint="flood_vector_tile"} 2
+nfd_requests_total{code="200",endpoint="staticmap"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected), "nfd_requests_total"))
+	assert.Equal(t, 2, testutil.CollectAndCount(collector, "nfd_request_duration_seconds"))
+}
+
+func TestCollector_ShouldLabelErrorsByTypedErrorClass(t *testing.T) {
+	service, collector := newInstrumentedService(t, http.StatusPaymentRequired, `{"message": "quota exceeded"}`)
+
+	_, err := service.GetFloodData(context.Background(), client.FloodDataOptions{SearchType: client.SearchTypeCoord})
+	require.Error(t, err)
+
+	expected := `
+# HELP nfd_request_errors_total Total number of failed National Flood Data API requests by endpoint and error class.
+# TYPE nfd_request_errors_total counter
+nfd_request_errors_total{class="no_data_available",endpoint="data"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected), "nfd_request_errors_total"))
+}
+
+func TestCollector_ShouldRecordTransportFailures(t *testing.T) {
+	service := newTestService(t)
+	service.HTTPClient = &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		}),
+	}
+	collector := metrics.NewCollector(service)
+
+	_, err := service.GetFloodMapRaw(context.Background(), client.FloodMapRawOptions{})
+	require.Error(t, err)
+
+	expected := `
+# HELP nfd_request_errors_total Total number of failed National Flood Data API requests by endpoint and error class.
+# TYPE nfd_request_errors_total counter
+nfd_request_errors_total{class="transport",endpoint="floodmapraw"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected), "nfd_request_errors_total"))
+}
+
+func TestErrorClass_ShouldClassifyTypedErrors(t *testing.T) {
+	tests := map[int]string{
+		400: "invalid_request",
+		401: "authentication",
+		402: "no_data_available",
+		404: "location_not_found",
+		405: "parcel_not_found",
+		500: "internal_server",
+		503: "api_error",
+	}
+
+	for status, class := range tests {
+		err := nfd.ParseError(&client.ErrorResponse{Status: status})
+		assert.Equal(t, class, metrics.ErrorClass(err), "status %d", status)
+	}
+
+	assert.Equal(t, "transport", metrics.ErrorClass(errors.New("dial tcp: timeout")))
+}
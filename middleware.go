@@ -0,0 +1,115 @@
+package go_nationalflooddata
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Endpoint names reported in Invocation.Endpoint. They match the Service
+// method that issued the call.
+const (
+	EndpointGetFloodData            = "GetFloodData"
+	EndpointGetFloodMapRaw          = "GetFloodMapRaw"
+	EndpointGetFloodDataBatch       = "GetFloodDataBatch"
+	EndpointGetFloodDataBatchResult = "GetFloodDataBatchResult"
+	EndpointGetFloodVectorTile      = "GetFloodVectorTile"
+	EndpointGetStormSurgeTile       = "GetStormSurgeTile"
+	EndpointGetDynamicFloodMap      = "GetDynamicFloodMap"
+	EndpointGetStaticFloodMap       = "GetStaticFloodMap"
+	EndpointDoRequest               = "DoRequest"
+)
+
+// Invocation describes a single API call as it travels through the
+// middleware chain configured on a Service.
+type Invocation struct {
+	// Endpoint is the name of the Service method that issued the call,
+	// such as EndpointGetFloodData. Calls made directly through DoRequest
+	// report EndpointDoRequest.
+	Endpoint string
+
+	// Options is the typed options struct passed to the Service method, for
+	// example client.FloodDataOptions or client.BatchDataRequest. It is nil
+	// for methods that take plain arguments and for direct DoRequest calls.
+	Options interface{}
+
+	// Request is the outgoing HTTP request. Middleware may modify it, or
+	// replace it, before calling the next Handler.
+	Request *http.Request
+}
+
+// Handler executes an Invocation and returns the raw HTTP response. The
+// response body is read and closed by the Service once the chain returns,
+// so a Handler that consumes the body must replace it.
+type Handler func(inv *Invocation) (*http.Response, error)
+
+// Middleware wraps a Handler to add behaviour around every API call, in the
+// same spirit as an http.RoundTripper but with access to the endpoint name
+// and typed options.
+type Middleware func(next Handler) Handler
+
+// Use appends middleware to the Service. Middleware run in the order they
+// were added: the first one registered is the outermost and sees the call
+// before, and the response after, all others.
+func (s *Service) Use(mw ...Middleware) {
+	s.Middleware = append(s.Middleware, mw...)
+}
+
+// handler builds the middleware chain around the Service's HTTP client. From
+// the outside in: user middleware, cache, retry, limiter and logger, so that
+// middleware sees cache hits and every retry is throttled and logged.
+func (s *Service) handler() Handler {
+	h := Handler(func(inv *Invocation) (*http.Response, error) {
+		return s.HTTPClient.Do(inv.Request)
+	})
+
+	if s.Logger != nil {
+		h = loggerMiddleware(s.Logger)(h)
+	}
+	if s.Limiter != nil {
+		h = limiterMiddleware(s.Limiter)(h)
+	}
+	if s.Retry != nil {
+		h = retryMiddleware(*s.Retry)(h)
+	}
+	if s.Cache != nil {
+		h = cacheMiddleware(s.Cache)(h)
+	}
+
+	for i := len(s.Middleware) - 1; i >= 0; i-- {
+		h = s.Middleware[i](h)
+	}
+
+	return h
+}
+
+// limiterMiddleware waits for limiter before every attempt.
+func limiterMiddleware(limiter Limiter) Middleware {
+	return func(next Handler) Handler {
+		return func(inv *Invocation) (*http.Response, error) {
+			if err := limiter.Wait(inv.Request.Context()); err != nil {
+				return nil, fmt.Errorf("rate limiter: %w", err)
+			}
+			return next(inv)
+		}
+	}
+}
+
+// loggerMiddleware logs the outcome and duration of every attempt. The query
+// string is left out because /dynamic.html carries the API key in it.
+func loggerMiddleware(logger Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(inv *Invocation) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(inv)
+
+			if err != nil {
+				logger.Printf("nfd: %s %s %s: %v (%s)", inv.Endpoint, inv.Request.Method, inv.Request.URL.Path, err, time.Since(start))
+			} else {
+				logger.Printf("nfd: %s %s %s: %d (%s)", inv.Endpoint, inv.Request.Method, inv.Request.URL.Path, resp.StatusCode, time.Since(start))
+			}
+
+			return resp, err
+		}
+	}
+}
@@ -1,6 +1,12 @@
+// Code generated by nfdgen from docs/openapi.json; DO NOT EDIT.
+
 package models
 
+// Coords corresponds to the "Coords" schema.
 type Coords struct {
+	// Lat corresponds to the "lat" property. Coordinate's latitude.
 	Lat string `json:"lat"`
+
+	// Lng corresponds to the "lng" property. Coordinate's longitude.
 	Lng string `json:"lng"`
 }
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dateLayouts are the formats ParseDate accepts, in the order it tries
+// them. The API writes dates as ISO dates or timestamps, and Community
+// Status Book dates as MMDDYY.
+var dateLayouts = []string{
+	"2006-01-02",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006/01/02",
+	"20060102",
+	"010206",
+	"01/02/2006",
+	"1/2/2006",
+	"01/02/06",
+	"1/2/06",
+	"01-02-2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"02-Jan-2006",
+	"02-Jan-06",
+}
+
+// ParseDate parses a date in any of the formats the API uses. Community
+// Status Book annotations, such as "(S)" for suspended, are stripped. A two
+// digit year is taken to be at most the current year, so the initial FIRM
+// date "070168" is 1968, unless the date is annotated "(>)", a future
+// date. Dates without a zone are UTC.
+func ParseDate(s string) (time.Time, error) {
+	v := strings.TrimSpace(s)
+	var future bool
+	if i := strings.IndexByte(v, '('); i >= 0 {
+		future = strings.Contains(v[i:], "(>)")
+		v = strings.TrimSpace(v[:i])
+	}
+	if v == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+
+	for _, layout := range dateLayouts {
+		t, err := time.Parse(layout, v)
+		if err != nil {
+			continue
+		}
+		if !strings.Contains(layout, "2006") && !future && t.Year() > time.Now().Year() {
+			t = t.AddDate(-100, 0, 0)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", s)
+}
+
+// EffectiveDate returns the panel's parsed effective date.
+func (p FloodFirmPan) EffectiveDate() (time.Time, error) {
+	return ParseDate(p.EffDate)
+}
+
+// EffectiveSince reports whether the panel was already in effect at t.
+// It is false when the effective date cannot be parsed.
+func (p FloodFirmPan) EffectiveSince(t time.Time) bool {
+	eff, err := p.EffectiveDate()
+	return err == nil && !eff.After(t)
+}
+
+// MostRecentPanel returns the panel with the latest effective date, the
+// one in effect today, or nil if there are no panels. Panels without a
+// parseable date are chosen only if no panel has one.
+func MostRecentPanel(panels []FloodFirmPan) *FloodFirmPan {
+	var recent *FloodFirmPan
+	var latest time.Time
+	for i := range panels {
+		eff, err := panels[i].EffectiveDate()
+		if recent == nil || err == nil && eff.After(latest) {
+			recent, latest = &panels[i], eff
+		}
+	}
+	return recent
+}
+
+// CurrentEffectiveDate returns the parsed date the community's current
+// FIRM became effective.
+func (c Community) CurrentEffectiveDate() (time.Time, error) {
+	return ParseDate(c.Curreff)
+}
+
+// InitialFIRMDate returns the parsed date of the community's first FIRM.
+// Buildings started before it are pre-FIRM.
+func (c Community) InitialFIRMDate() (time.Time, error) {
+	return ParseDate(c.Firm)
+}
+
+// InitialFHBMDate returns the parsed date of the community's first flood
+// hazard boundary map.
+func (c Community) InitialFHBMDate() (time.Time, error) {
+	return ParseDate(c.Fhbm)
+}
+
+// EndedDate returns the parsed date the LOMA case concluded, when the
+// letter was issued.
+func (l Loma) EndedDate() (time.Time, error) {
+	return ParseDate(l.DateEnded)
+}
+
+// IssuedAfter reports whether the LOMA was issued on or after t. It is
+// false when the issue date cannot be parsed.
+func (l Loma) IssuedAfter(t time.Time) bool {
+	ended, err := l.EndedDate()
+	return err == nil && !ended.Before(t)
+}
+
+// IssuedAfterPanel reports whether the LOMA was issued on or after p
+// became effective. A new or revised panel supersedes earlier LOMAs, so
+// only letters issued after it still apply; those that predate it must be
+// revalidated. It is false when either date cannot be parsed.
+func (l Loma) IssuedAfterPanel(p FloodFirmPan) bool {
+	eff, err := p.EffectiveDate()
+	return err == nil && l.IssuedAfter(eff)
+}
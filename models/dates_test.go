@@ -0,0 +1,107 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata/models"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2024-12-20", date(2024, 12, 20)},
+		{" 2024-12-20T00:00:00Z ", date(2024, 12, 20)},
+		{"2024-12-20 00:00:00", date(2024, 12, 20)},
+		{"20241220", date(2024, 12, 20)},
+		{"100517      ", date(2017, 10, 5)},
+		{"051578", date(1978, 5, 15)},
+		{"070168", date(1968, 7, 1)},
+		{"07/01/68", date(1968, 7, 1)},
+		{"01-Jul-68", date(1968, 7, 1)},
+		{"070168(>)", date(2068, 7, 1)},
+		{"100517(S)", date(2017, 10, 5)},
+		{"(>) ", time.Time{}},
+		{"12/20/2024", date(2024, 12, 20)},
+		{"1/2/2024", date(2024, 1, 2)},
+		{"07/29/98", date(1998, 7, 29)},
+		{"Dec 20, 2024", date(2024, 12, 20)},
+		{"20-Dec-2024", date(2024, 12, 20)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := models.ParseDate(tt.in)
+			if tt.want.IsZero() {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, in := range []string{"", "soon", "13/45/2024", "2024-02-30"} {
+		_, err := models.ParseDate(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestFloodFirmPan_EffectiveSince(t *testing.T) {
+	p := models.FloodFirmPan{EffDate: "2024-12-20"}
+	assert.True(t, p.EffectiveSince(date(2024, 12, 20)))
+	assert.True(t, p.EffectiveSince(date(2025, 1, 1)))
+	assert.False(t, p.EffectiveSince(date(2024, 12, 19)))
+	assert.False(t, models.FloodFirmPan{}.EffectiveSince(date(2025, 1, 1)))
+}
+
+func TestMostRecentPanel(t *testing.T) {
+	assert.Nil(t, models.MostRecentPanel(nil))
+
+	panels := []models.FloodFirmPan{
+		{FirmPan: "undated"},
+		{FirmPan: "old", EffDate: "2008-10-05"},
+		{FirmPan: "new", EffDate: "2024-12-20"},
+		{FirmPan: "mid", EffDate: "2017-10-05"},
+	}
+	assert.Equal(t, "new", models.MostRecentPanel(panels).FirmPan)
+	assert.Equal(t, "undated", models.MostRecentPanel(panels[:1]).FirmPan)
+}
+
+func TestCommunity_Dates(t *testing.T) {
+	c := models.Community{Firm: "051578      ", Curreff: "100517      ", Fhbm: "091374      "}
+
+	firm, err := c.InitialFIRMDate()
+	require.NoError(t, err)
+	assert.Equal(t, date(1978, 5, 15), firm)
+
+	curreff, err := c.CurrentEffectiveDate()
+	require.NoError(t, err)
+	assert.Equal(t, date(2017, 10, 5), curreff)
+
+	fhbm, err := c.InitialFHBMDate()
+	require.NoError(t, err)
+	assert.Equal(t, date(1974, 9, 13), fhbm)
+}
+
+func TestLoma_IssuedAfterPanel(t *testing.T) {
+	panel := models.FloodFirmPan{EffDate: "2024-12-20"}
+
+	assert.False(t, models.Loma{DateEnded: "2000-07-26"}.IssuedAfterPanel(panel))
+	assert.True(t, models.Loma{DateEnded: "2024-12-20"}.IssuedAfterPanel(panel))
+	assert.True(t, models.Loma{DateEnded: "03/01/2025"}.IssuedAfterPanel(panel))
+	assert.False(t, models.Loma{}.IssuedAfterPanel(panel))
+	assert.False(t, models.Loma{DateEnded: "2025-03-01"}.IssuedAfterPanel(models.FloodFirmPan{}))
+
+	ended, err := models.Loma{DateEnded: "1998-07-29"}.EndedDate()
+	require.NoError(t, err)
+	assert.Equal(t, date(1998, 7, 29), ended)
+}
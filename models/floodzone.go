@@ -0,0 +1,291 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FloodZone is a FEMA flood zone designation, such as "AE", "A12", "VE" or
+// "X500". Values returned by ParseFloodZone are normalized: upper case,
+// with numbered zones written without leading zeros. The methods normalize
+// z first, so FloodZone("ae") and FloodZone("Zone AE") classify like AE.
+type FloodZone string
+
+// FEMA flood zone designations. Numbered zones A1-A30 and V1-V30 have no
+// constants; build them with ParseFloodZone.
+const (
+	ZoneA   FloodZone = "A"
+	ZoneAE  FloodZone = "AE"
+	ZoneAH  FloodZone = "AH"
+	ZoneAO  FloodZone = "AO"
+	ZoneAR  FloodZone = "AR"
+	ZoneA99 FloodZone = "A99"
+	ZoneV   FloodZone = "V"
+	ZoneVE  FloodZone = "VE"
+	ZoneB   FloodZone = "B"
+	ZoneC   FloodZone = "C"
+	ZoneD   FloodZone = "D"
+	ZoneX   FloodZone = "X"
+
+	// ZoneX500 is shaded Zone X, the 0.2% annual chance floodplain. The
+	// API reports it as zone "X" with a subtype; see
+	// ParseFloodZoneWithSubtype.
+	ZoneX500 FloodZone = "X500"
+
+	ZoneOpenWater       FloodZone = "OPEN WATER"
+	ZoneAreaNotIncluded FloodZone = "AREA NOT INCLUDED"
+)
+
+// simpleZones are the designations without a number or AR/ prefix.
+var simpleZones = map[FloodZone]bool{
+	ZoneA: true, ZoneAE: true, ZoneAH: true, ZoneAO: true, ZoneAR: true, ZoneA99: true,
+	ZoneV: true, ZoneVE: true, ZoneB: true, ZoneC: true, ZoneD: true, ZoneX: true, ZoneX500: true,
+	ZoneOpenWater: true, ZoneAreaNotIncluded: true,
+}
+
+// zoneAliases are other spellings of designations.
+var zoneAliases = map[string]FloodZone{
+	"SHADED X":     ZoneX500,
+	"X (SHADED)":   ZoneX500,
+	"X SHADED":     ZoneX500,
+	"UNSHADED X":   ZoneX,
+	"X (UNSHADED)": ZoneX,
+	"X UNSHADED":   ZoneX,
+	"X 500":        ZoneX500,
+}
+
+// ParseFloodZone parses a FEMA flood zone designation, including numbered
+// zones A1-A30 and V1-V30 and dual AR zones such as "AR/AE". On error it
+// still returns the input, trimmed and upper-cased, so callers that only
+// display a zone can ignore the error.
+func ParseFloodZone(s string) (FloodZone, error) {
+	norm := strings.ToUpper(strings.Join(strings.Fields(s), " "))
+	norm = strings.TrimPrefix(norm, "ZONE ")
+
+	if z, ok := zoneAliases[norm]; ok {
+		return z, nil
+	}
+	if simpleZones[FloodZone(norm)] {
+		return FloodZone(norm), nil
+	}
+	if z, ok := numberedZone(norm); ok {
+		return z, nil
+	}
+	if rest, ok := strings.CutPrefix(norm, "AR/"); ok {
+		z, err := ParseFloodZone(rest)
+		if err == nil && z.IsSFHA() && z != ZoneAR && z != ZoneA99 && !z.IsCoastalHighHazard() {
+			return "AR/" + z, nil
+		}
+	}
+
+	if norm == "" {
+		return "", fmt.Errorf("empty flood zone")
+	}
+	return FloodZone(norm), fmt.Errorf("unknown flood zone %q", s)
+}
+
+// ParseFloodZoneWithSubtype parses zone like ParseFloodZone, using the
+// API's zone subtype to tell shaded Zone X (the 0.2% annual chance
+// floodplain, and areas protected by levees) from unshaded Zone X.
+func ParseFloodZoneWithSubtype(zone, subtype string) (FloodZone, error) {
+	z, err := ParseFloodZone(zone)
+	if err == nil && z == ZoneX {
+		subtype = strings.ToUpper(subtype)
+		if strings.Contains(subtype, "PCT") || strings.Contains(subtype, "LEVEE") {
+			return ZoneX500, nil
+		}
+	}
+	return z, err
+}
+
+// numberedZone parses A1-A30 and V1-V30.
+func numberedZone(s string) (FloodZone, bool) {
+	if len(s) < 2 || (s[0] != 'A' && s[0] != 'V') {
+		return "", false
+	}
+	n, err := strconv.Atoi(s[1:])
+	if err != nil || n < 1 || n > 30 || s[1] == '+' || s[1] == '-' {
+		return "", false
+	}
+	return FloodZone(s[:1] + strconv.Itoa(n)), true
+}
+
+// normalized returns z as ParseFloodZone normalizes it, and whether it is
+// valid.
+func (z FloodZone) normalized() (FloodZone, bool) {
+	n, err := ParseFloodZone(string(z))
+	return n, err == nil
+}
+
+// Valid reports whether z is a FEMA flood zone designation.
+func (z FloodZone) Valid() bool {
+	_, ok := z.normalized()
+	return ok
+}
+
+// IsSFHA reports whether z is in a Special Flood Hazard Area, the 1%
+// annual chance floodplain where flood insurance is mandatory for
+// federally backed mortgages: any A or V zone.
+func (z FloodZone) IsSFHA() bool {
+	n, ok := z.normalized()
+	if !ok {
+		return false
+	}
+	return n[0] == 'A' && n != ZoneAreaNotIncluded || n[0] == 'V'
+}
+
+// IsCoastalHighHazard reports whether z is a coastal high hazard area, a
+// V zone, subject to velocity hazard from storm-driven waves.
+func (z FloodZone) IsCoastalHighHazard() bool {
+	n, ok := z.normalized()
+	return ok && n[0] == 'V'
+}
+
+// AnnualChance is the flood probability class of a zone.
+type AnnualChance int
+
+// Annual chance classes, from most to least hazardous.
+const (
+	AnnualChanceUnknown AnnualChance = iota
+
+	// AnnualChanceOnePercent is the 1% annual chance (100-year)
+	// floodplain: the A and V zones.
+	AnnualChanceOnePercent
+
+	// AnnualChancePointTwoPercent is the 0.2% annual chance (500-year)
+	// floodplain: zones B and X500 (shaded X).
+	AnnualChancePointTwoPercent
+
+	// AnnualChanceMinimal is outside the 0.2% floodplain: zones C and X.
+	AnnualChanceMinimal
+
+	// AnnualChanceUndetermined is zone D, where flooding is possible but
+	// has not been studied.
+	AnnualChanceUndetermined
+)
+
+func (a AnnualChance) String() string {
+	switch a {
+	case AnnualChanceOnePercent:
+		return "1%"
+	case AnnualChancePointTwoPercent:
+		return "0.2%"
+	case AnnualChanceMinimal:
+		return "minimal"
+	case AnnualChanceUndetermined:
+		return "undetermined"
+	default:
+		return "unknown"
+	}
+}
+
+// AnnualChance returns the probability class of z.
+func (z FloodZone) AnnualChance() AnnualChance {
+	z, _ = z.normalized()
+	switch {
+	case z.IsSFHA():
+		return AnnualChanceOnePercent
+	case z == ZoneB || z == ZoneX500:
+		return AnnualChancePointTwoPercent
+	case z == ZoneC || z == ZoneX:
+		return AnnualChanceMinimal
+	case z == ZoneD:
+		return AnnualChanceUndetermined
+	default:
+		return AnnualChanceUnknown
+	}
+}
+
+// ExplanationKey returns the FloodZoneExplanations key covering z, such
+// as "A1-A30" for A12 or "B, X500" for X500, or "" if none does.
+func (z FloodZone) ExplanationKey() string {
+	z, ok := z.normalized()
+	if !ok {
+		return ""
+	}
+
+	// Dual AR zones are explained as AR.
+	if strings.HasPrefix(string(z), "AR/") {
+		z = ZoneAR
+	}
+
+	if _, ok := FloodZoneExplanations[string(z)]; ok {
+		return string(z)
+	}
+
+	keys := make([]string, 0, len(FloodZoneExplanations))
+	for key := range FloodZoneExplanations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, part := range strings.Split(key, ",") {
+			part = strings.TrimSpace(part)
+			if FloodZone(part) == z || inZoneRange(part, z) {
+				return key
+			}
+		}
+	}
+	return ""
+}
+
+// Explanation returns the FloodZoneExplanations text for z, or "" if
+// there is none.
+func (z FloodZone) Explanation() string {
+	return FloodZoneExplanations[z.ExplanationKey()]
+}
+
+// inZoneRange reports whether z is in a range of numbered zones such as
+// "A1-A30".
+func inZoneRange(r string, z FloodZone) bool {
+	lo, hi, ok := strings.Cut(r, "-")
+	if !ok {
+		return false
+	}
+	from, ok1 := numberedZone(lo)
+	to, ok2 := numberedZone(hi)
+	n, ok3 := numberedZone(string(z))
+	if !ok1 || !ok2 || !ok3 || from[0] != to[0] || n[0] != from[0] {
+		return false
+	}
+
+	num := func(z FloodZone) int {
+		v, _ := strconv.Atoi(string(z[1:]))
+		return v
+	}
+	return num(n) >= num(from) && num(n) <= num(to)
+}
+
+// Zone returns the parsed flood zone of the hazard area, taking the zone
+// subtype into account. Unknown designations are returned as is; check
+// Valid.
+func (h FloodFieldHazard) Zone() FloodZone {
+	var subtype string
+	if h.ZoneSubty != nil {
+		subtype = *h.ZoneSubty
+	}
+	z, _ := ParseFloodZoneWithSubtype(h.FldZone, subtype)
+	return z
+}
+
+// Zone returns the parsed flood zone of the region, taking the zone
+// subtype into account. Unknown designations are returned as is; check
+// Valid.
+func (r FloodRegion) Zone() FloodZone {
+	z, _ := ParseFloodZoneWithSubtype(r.FldZone, r.ZoneSubty)
+	return z
+}
+
+// Zone returns the parsed flood zone the BFE applies to. Unknown
+// designations are returned as is; check Valid.
+func (b BaseFloodElevation) Zone() FloodZone {
+	var subtype string
+	if b.ZoneSubty != nil {
+		subtype = *b.ZoneSubty
+	}
+	z, _ := ParseFloodZoneWithSubtype(b.FldZone, subtype)
+	return z
+}
@@ -0,0 +1,149 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata/models"
+)
+
+func TestParseFloodZone(t *testing.T) {
+	tests := []struct {
+		in   string
+		want models.FloodZone
+	}{
+		{"AE", models.ZoneAE},
+		{" ae ", models.ZoneAE},
+		{"Zone VE", models.ZoneVE},
+		{"A1", "A1"},
+		{"A07", "A7"},
+		{"A30", "A30"},
+		{"V12", "V12"},
+		{"A99", models.ZoneA99},
+		{"AR/AE", "AR/AE"},
+		{"ar/a12", "AR/A12"},
+		{"X", models.ZoneX},
+		{"shaded x", models.ZoneX500},
+		{"X (UNSHADED)", models.ZoneX},
+		{"open  water", models.ZoneOpenWater},
+		{"AREA NOT INCLUDED", models.ZoneAreaNotIncluded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			z, err := models.ParseFloodZone(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, z)
+			assert.True(t, z.Valid())
+		})
+	}
+}
+
+func TestParseFloodZone_ShouldRejectUnknownDesignations(t *testing.T) {
+	for _, in := range []string{"", "A0", "A31", "V-1", "E", "AR/VE", "AR/AR", "X1"} {
+		z, err := models.ParseFloodZone(in)
+		assert.Error(t, err, in)
+		assert.False(t, z.Valid(), in)
+		assert.False(t, z.IsSFHA(), in)
+	}
+
+	z, _ := models.ParseFloodZone(" zz ")
+	assert.Equal(t, models.FloodZone("ZZ"), z)
+}
+
+func TestParseFloodZoneWithSubtype(t *testing.T) {
+	z, err := models.ParseFloodZoneWithSubtype("X", "0.2 PCT ANNUAL CHANCE FLOOD HAZARD")
+	require.NoError(t, err)
+	assert.Equal(t, models.ZoneX500, z)
+
+	z, _ = models.ParseFloodZoneWithSubtype("X", "AREA WITH REDUCED FLOOD RISK DUE TO LEVEE")
+	assert.Equal(t, models.ZoneX500, z)
+
+	z, _ = models.ParseFloodZoneWithSubtype("X", "AREA OF MINIMAL FLOOD HAZARD")
+	assert.Equal(t, models.ZoneX, z)
+
+	z, _ = models.ParseFloodZoneWithSubtype("AE", "COASTAL FLOODPLAIN")
+	assert.Equal(t, models.ZoneAE, z)
+}
+
+func TestFloodZone_Classification(t *testing.T) {
+	tests := []struct {
+		zone    models.FloodZone
+		sfha    bool
+		coastal bool
+		chance  models.AnnualChance
+	}{
+		{models.ZoneA, true, false, models.AnnualChanceOnePercent},
+		{models.ZoneAE, true, false, models.AnnualChanceOnePercent},
+		{"A12", true, false, models.AnnualChanceOnePercent},
+		{models.ZoneA99, true, false, models.AnnualChanceOnePercent},
+		{"AR/AE", true, false, models.AnnualChanceOnePercent},
+		{models.ZoneVE, true, true, models.AnnualChanceOnePercent},
+		{"V5", true, true, models.AnnualChanceOnePercent},
+		{models.ZoneB, false, false, models.AnnualChancePointTwoPercent},
+		{models.ZoneX500, false, false, models.AnnualChancePointTwoPercent},
+		{models.ZoneC, false, false, models.AnnualChanceMinimal},
+		{models.ZoneX, false, false, models.AnnualChanceMinimal},
+		{models.ZoneD, false, false, models.AnnualChanceUndetermined},
+		{models.ZoneOpenWater, false, false, models.AnnualChanceUnknown},
+		{models.ZoneAreaNotIncluded, false, false, models.AnnualChanceUnknown},
+
+		// Unparsed values are normalized first.
+		{"ae", true, false, models.AnnualChanceOnePercent},
+		{"Zone AE", true, false, models.AnnualChanceOnePercent},
+		{" ve ", true, true, models.AnnualChanceOnePercent},
+		{"a07", true, false, models.AnnualChanceOnePercent},
+		{"shaded x", false, false, models.AnnualChancePointTwoPercent},
+		{"x", false, false, models.AnnualChanceMinimal},
+		{"area not included", false, false, models.AnnualChanceUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.zone), func(t *testing.T) {
+			assert.Equal(t, tt.sfha, tt.zone.IsSFHA())
+			assert.Equal(t, tt.coastal, tt.zone.IsCoastalHighHazard())
+			assert.Equal(t, tt.chance, tt.zone.AnnualChance())
+		})
+	}
+
+	assert.Equal(t, "1%", models.AnnualChanceOnePercent.String())
+	assert.Equal(t, "unknown", models.AnnualChanceUnknown.String())
+}
+
+func TestFloodZone_Explanation(t *testing.T) {
+	tests := []struct {
+		zone models.FloodZone
+		key  string
+	}{
+		{models.ZoneAE, "AE"},
+		{"A1", "A1-A30"},
+		{"A30", "A1-A30"},
+		{models.ZoneVE, "VE, V1-V30"},
+		{"V17", "VE, V1-V30"},
+		{models.ZoneX500, "B, X500"},
+		{models.ZoneB, "B, X500"},
+		{models.ZoneX, "C, X"},
+		{"AR/AE", "AR"},
+		{models.ZoneOpenWater, ""},
+		{"A31", ""},
+		{"zone a12", "A1-A30"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.zone), func(t *testing.T) {
+			assert.Equal(t, tt.key, tt.zone.ExplanationKey())
+			assert.Equal(t, models.FloodZoneExplanations[tt.key], tt.zone.Explanation())
+		})
+	}
+}
+
+func TestFloodRegion_Zone(t *testing.T) {
+	r := models.FloodRegion{FldZone: "X", ZoneSubty: "0.2 PCT ANNUAL CHANCE FLOOD HAZARD"}
+	assert.Equal(t, models.ZoneX500, r.Zone())
+
+	subtype := "COASTAL FLOODPLAIN"
+	h := models.FloodFieldHazard{FldZone: "ae", ZoneSubty: &subtype}
+	assert.Equal(t, models.ZoneAE, h.Zone())
+
+	b := models.BaseFloodElevation{FldZone: "VE"}
+	assert.True(t, b.Zone().IsCoastalHighHazard())
+}
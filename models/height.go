@@ -0,0 +1,159 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LengthUnit is the unit of an elevation.
+type LengthUnit string
+
+// Length units.
+const (
+	Meters LengthUnit = "m"
+	Feet   LengthUnit = "ft"
+)
+
+// metersPerFoot is the international foot. FEMA maps in feet use it; the
+// U.S. survey foot differs by two parts per million, well below map
+// precision.
+const metersPerFoot = 0.3048
+
+// ParseLengthUnit parses a len_unit value such as "Feet" or "Meters".
+func ParseLengthUnit(s string) (LengthUnit, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "FEET", "FOOT", "FT", "US SURVEY FEET", "INTERNATIONAL FEET":
+		return Feet, nil
+	case "METERS", "METRES", "METER", "METRE", "M":
+		return Meters, nil
+	default:
+		return "", fmt.Errorf("unknown length unit %q", s)
+	}
+}
+
+// VerticalDatum is the reference surface an elevation is measured from.
+type VerticalDatum string
+
+// Vertical datums used on FEMA flood maps. Older maps use NGVD29; newer
+// ones NAVD88.
+const (
+	NAVD88 VerticalDatum = "NAVD88"
+	NGVD29 VerticalDatum = "NGVD29"
+)
+
+// ParseVerticalDatum parses a v_datum value such as "NAVD88" or
+// "NGVD 1929".
+func ParseVerticalDatum(s string) (VerticalDatum, error) {
+	v := strings.ToUpper(strings.Join(strings.Fields(s), ""))
+	switch v {
+	case "NAVD88", "NAVD1988":
+		return NAVD88, nil
+	case "NGVD29", "NGVD1929":
+		return NGVD29, nil
+	default:
+		return "", fmt.Errorf("unknown vertical datum %q", s)
+	}
+}
+
+// ErrDatumMismatch is returned when comparing heights on different vertical
+// datums. Convert one with the datum package first.
+var ErrDatumMismatch = errors.New("heights are on different vertical datums")
+
+// Height is an elevation normalized to meters, on a vertical datum.
+type Height struct {
+	Meters float64
+	Datum  VerticalDatum
+}
+
+// NewHeight returns the height of value in unit on datum.
+func NewHeight(value float64, unit LengthUnit, datum VerticalDatum) Height {
+	if unit == Feet {
+		value *= metersPerFoot
+	}
+	return Height{Meters: value, Datum: datum}
+}
+
+// Feet returns the height in feet.
+func (h Height) Feet() float64 {
+	return h.Meters / metersPerFoot
+}
+
+// In returns the height in unit.
+func (h Height) In(unit LengthUnit) float64 {
+	if unit == Feet {
+		return h.Feet()
+	}
+	return h.Meters
+}
+
+// Above returns how far h is above o, in meters; negative when it is
+// below. It returns ErrDatumMismatch if the datums differ.
+func (h Height) Above(o Height) (float64, error) {
+	if h.Datum != o.Datum {
+		return 0, fmt.Errorf("%w: %s and %s", ErrDatumMismatch, h.Datum, o.Datum)
+	}
+	return h.Meters - o.Meters, nil
+}
+
+func (h Height) String() string {
+	return fmt.Sprintf("%.2f m %s", h.Meters, h.Datum)
+}
+
+// height builds a Height from the string fields of a BFE.
+func height(value float64, unit, datum string) (Height, error) {
+	u, err := ParseLengthUnit(unit)
+	if err != nil {
+		return Height{}, err
+	}
+	d, err := ParseVerticalDatum(datum)
+	if err != nil {
+		return Height{}, err
+	}
+	return NewHeight(value, u, d), nil
+}
+
+// Height returns the BFE as a Height.
+func (b BaseFloodElevation) Height() (Height, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(b.Elevation), 64)
+	if err != nil {
+		return Height{}, fmt.Errorf("base flood elevation %q: %w", b.Elevation, err)
+	}
+	return height(v, b.LenUnit, b.VDatum)
+}
+
+// Height returns the BFE line's elevation as a Height.
+func (b BFEListItem) Height() (Height, error) {
+	return height(b.Elev, b.LenUnit, b.VDatum)
+}
+
+// MissingElevation is the PropertyElevation the API reports when it has no
+// elevation for the property.
+const MissingElevation = -1000000
+
+// ErrNoPropertyElevation is returned by Freeboard when the API reported no
+// elevation for the property.
+var ErrNoPropertyElevation = errors.New("no property elevation")
+
+// PropertyHeight returns PropertyElevation as a Height, and false if it is
+// MissingElevation. The API reports it in feet on NAVD88, the datum of the
+// USGS elevation model it is sampled from.
+func (e Elevation) PropertyHeight() (Height, bool) {
+	if e.PropertyElevation <= MissingElevation {
+		return Height{}, false
+	}
+	return NewHeight(e.PropertyElevation, Feet, NAVD88), true
+}
+
+// Freeboard returns how far the property is above bfe, in meters;
+// negative when it is below. bfe must be on NAVD88, or ErrDatumMismatch is
+// returned; convert NGVD29 BFEs with the datum package. It returns
+// ErrNoPropertyElevation if the property elevation is missing.
+func (e Elevation) Freeboard(bfe Height) (float64, error) {
+	h, ok := e.PropertyHeight()
+	if !ok {
+		return 0, ErrNoPropertyElevation
+	}
+	return h.Above(bfe)
+}
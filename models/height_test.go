@@ -0,0 +1,104 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata/models"
+)
+
+func TestParseLengthUnit(t *testing.T) {
+	for in, want := range map[string]models.LengthUnit{
+		"Feet": models.Feet, " ft ": models.Feet, "US Survey Feet": models.Feet,
+		"Meters": models.Meters, "metres": models.Meters,
+	} {
+		got, err := models.ParseLengthUnit(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	_, err := models.ParseLengthUnit("cubits")
+	assert.Error(t, err)
+}
+
+func TestParseVerticalDatum(t *testing.T) {
+	for in, want := range map[string]models.VerticalDatum{
+		"NAVD88": models.NAVD88, "navd 88": models.NAVD88, "NAVD 1988": models.NAVD88,
+		"NGVD29": models.NGVD29, "NGVD 1929": models.NGVD29,
+	} {
+		got, err := models.ParseVerticalDatum(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	_, err := models.ParseVerticalDatum("")
+	assert.Error(t, err)
+}
+
+func TestHeight(t *testing.T) {
+	h := models.NewHeight(6, models.Feet, models.NAVD88)
+	assert.InDelta(t, 1.8288, h.Meters, 1e-9)
+	assert.InDelta(t, 6, h.Feet(), 1e-9)
+	assert.InDelta(t, 6, h.In(models.Feet), 1e-9)
+	assert.InDelta(t, 1.8288, h.In(models.Meters), 1e-9)
+	assert.Equal(t, "1.83 m NAVD88", h.String())
+
+	above, err := models.NewHeight(2, models.Meters, models.NAVD88).Above(h)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.1712, above, 1e-9)
+
+	_, err = h.Above(models.Height{Datum: models.NGVD29})
+	assert.ErrorIs(t, err, models.ErrDatumMismatch)
+}
+
+func TestBaseFloodElevation_Height(t *testing.T) {
+	b := models.BaseFloodElevation{Elevation: "6", LenUnit: "Feet", VDatum: "NAVD88"}
+	h, err := b.Height()
+	require.NoError(t, err)
+	assert.Equal(t, models.NAVD88, h.Datum)
+	assert.InDelta(t, 6, h.Feet(), 1e-9)
+
+	for _, bad := range []models.BaseFloodElevation{
+		{Elevation: "", LenUnit: "Feet", VDatum: "NAVD88"},
+		{Elevation: "6", LenUnit: "", VDatum: "NAVD88"},
+		{Elevation: "6", LenUnit: "Feet", VDatum: "MSL"},
+	} {
+		_, err := bad.Height()
+		assert.Error(t, err)
+	}
+
+	item := models.BFEListItem{Elev: 2.1, LenUnit: "Meters", VDatum: "NGVD29"}
+	h, err = item.Height()
+	require.NoError(t, err)
+	assert.Equal(t, models.Height{Meters: 2.1, Datum: models.NGVD29}, h)
+}
+
+func TestElevation_Freeboard(t *testing.T) {
+	e := models.Elevation{PropertyElevation: 6.1}
+	h, ok := e.PropertyHeight()
+	require.True(t, ok)
+	assert.Equal(t, models.NAVD88, h.Datum)
+	assert.InDelta(t, 6.1, h.Feet(), 1e-9)
+
+	freeboard, err := e.Freeboard(models.NewHeight(6, models.Feet, models.NAVD88))
+	require.NoError(t, err)
+	assert.InDelta(t, 0.1*0.3048, freeboard, 1e-9)
+
+	freeboard, err = e.Freeboard(models.NewHeight(7, models.Feet, models.NAVD88))
+	require.NoError(t, err)
+	assert.Less(t, freeboard, 0.0)
+
+	_, err = e.Freeboard(models.NewHeight(7, models.Feet, models.NGVD29))
+	assert.ErrorIs(t, err, models.ErrDatumMismatch)
+}
+
+func TestElevation_ShouldReportMissingPropertyElevation(t *testing.T) {
+	e := models.Elevation{PropertyElevation: models.MissingElevation}
+	_, ok := e.PropertyHeight()
+	assert.False(t, ok)
+
+	_, err := e.Freeboard(models.NewHeight(6, models.Feet, models.NAVD88))
+	assert.ErrorIs(t, err, models.ErrNoPropertyElevation)
+}
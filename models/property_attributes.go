@@ -0,0 +1,202 @@
+package models
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Construction is a normalized construction type.
+type Construction string
+
+// Construction types. ConstructionUnknown is empty, for missing
+// descriptions.
+const (
+	ConstructionUnknown      Construction = ""
+	ConstructionFrame        Construction = "frame"
+	ConstructionMasonry      Construction = "masonry"
+	ConstructionConcrete     Construction = "concrete"
+	ConstructionSteel        Construction = "steel"
+	ConstructionManufactured Construction = "manufactured"
+	ConstructionOther        Construction = "other"
+)
+
+// constructionKeywords map words in a construction description to a
+// type. They are tried in order, so "CONCRETE BLOCK" is masonry before it
+// can be concrete.
+var constructionKeywords = []struct {
+	words []string
+	c     Construction
+}{
+	{[]string{"MANUFACTURED", "MOBILE", "MODULAR"}, ConstructionManufactured},
+	{[]string{"MASONRY", "BRICK", "BLOCK", "CBS", "STONE", "ADOBE"}, ConstructionMasonry},
+	{[]string{"CONCRETE", "POURED", "TILT"}, ConstructionConcrete},
+	{[]string{"STEEL", "METAL", "IRON"}, ConstructionSteel},
+	{[]string{"FRAME", "WOOD", "TIMBER", "LOG"}, ConstructionFrame},
+}
+
+// ParseConstruction normalizes a construction description, such as
+// "Masonry" or "WOOD FRAME".
+func ParseConstruction(s string) Construction {
+	desc := strings.ToUpper(strings.TrimSpace(s))
+	if desc == "" {
+		return ConstructionUnknown
+	}
+	for _, k := range constructionKeywords {
+		for _, w := range k.words {
+			if strings.Contains(desc, w) {
+				return k.c
+			}
+		}
+	}
+	return ConstructionOther
+}
+
+// GarageType is a normalized parking garage type.
+type GarageType string
+
+// Garage types. GarageUnknown is empty, for missing descriptions.
+const (
+	GarageUnknown  GarageType = ""
+	GarageNone     GarageType = "none"
+	GarageAttached GarageType = "attached"
+	GarageDetached GarageType = "detached"
+	GarageBuiltIn  GarageType = "built_in"
+	GarageBasement GarageType = "basement"
+	GarageCarport  GarageType = "carport"
+	GarageOther    GarageType = "other"
+)
+
+// ParseGarageType normalizes a parking garage description, such as
+// "Attached Garage".
+func ParseGarageType(s string) GarageType {
+	desc := strings.ToUpper(strings.TrimSpace(s))
+	switch {
+	case desc == "":
+		return GarageUnknown
+	case desc == "NONE" || strings.HasPrefix(desc, "NO "):
+		return GarageNone
+	case strings.Contains(desc, "DETACHED"):
+		return GarageDetached
+	case strings.Contains(desc, "ATTACHED"):
+		return GarageAttached
+	case strings.Contains(desc, "BUILT"):
+		return GarageBuiltIn
+	case strings.Contains(desc, "BASEMENT"), strings.Contains(desc, "UNDERGROUND"), strings.Contains(desc, "TUCK"):
+		return GarageBasement
+	case strings.Contains(desc, "CARPORT"):
+		return GarageCarport
+	default:
+		return GarageOther
+	}
+}
+
+// FIRMStatus is whether a building predates its community's flood maps.
+type FIRMStatus string
+
+// FIRM statuses.
+const (
+	// PreFIRM buildings were started on or before December 31, 1974, or
+	// before the community's initial FIRM, and so were not built to
+	// floodplain management standards.
+	PreFIRM FIRMStatus = "pre_firm"
+
+	// PostFIRM buildings were started after both.
+	PostFIRM FIRMStatus = "post_firm"
+
+	// FIRMStatusUnknown means the year built or the FIRM date is missing,
+	// or the building was finished in the year of the initial FIRM, when
+	// the year alone cannot tell.
+	FIRMStatusUnknown FIRMStatus = "unknown"
+)
+
+// preFIRMCutoffYear is the last year whose buildings are pre-FIRM
+// everywhere.
+const preFIRMCutoffYear = 1974
+
+// PropertyAttributes is a parsed view of a Property. Numbers are nil when
+// missing or unparseable.
+type PropertyAttributes struct {
+	SqFt       *int `json:"sqft,omitempty"`
+	YearBuilt  *int `json:"year_built,omitempty"`
+	Stories    *int `json:"stories,omitempty"`
+	GarageArea *int `json:"garage_area,omitempty"`
+
+	Use          string       `json:"use,omitempty"`
+	Construction Construction `json:"construction,omitempty"`
+	Garage       GarageType   `json:"garage,omitempty"`
+}
+
+// leadingNumber matches the number at the start of a value such as
+// "2,450 sqft" or "1.5".
+var leadingNumber = regexp.MustCompile(`^[0-9][0-9,]*(\.[0-9]*)?`)
+
+// parseCount parses a whole number, ignoring thousands separators, units
+// and any fraction: "1.5" stories is 1.
+func parseCount(s *string) *int {
+	if s == nil {
+		return nil
+	}
+	m := leadingNumber.FindString(strings.TrimSpace(*s))
+	if m == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(strings.ReplaceAll(m, ",", ""), 64)
+	if err != nil {
+		return nil
+	}
+	n := int(f)
+	return &n
+}
+
+func trimmed(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return strings.TrimSpace(*s)
+}
+
+// Attributes parses p.
+func (p Property) Attributes() PropertyAttributes {
+	a := PropertyAttributes{
+		SqFt:         parseCount(p.SqFt),
+		YearBuilt:    parseCount(p.YearBuilt),
+		Stories:      parseCount(p.StoriesCount),
+		GarageArea:   parseCount(p.ParkingGarageArea),
+		Use:          trimmed(p.PropertyUseDescription),
+		Construction: ParseConstruction(trimmed(p.ConstructionDesc)),
+		Garage:       ParseGarageType(trimmed(p.ParkingGarageType)),
+	}
+	if a.YearBuilt != nil && (*a.YearBuilt < 1600 || *a.YearBuilt > 2200) {
+		a.YearBuilt = nil
+	}
+	return a
+}
+
+// FIRMStatus classifies the building as pre- or post-FIRM by comparing
+// YearBuilt with c's initial FIRM date. Buildings from 1974 or earlier are
+// pre-FIRM whatever c says.
+func (a PropertyAttributes) FIRMStatus(c *Community) FIRMStatus {
+	if a.YearBuilt == nil {
+		return FIRMStatusUnknown
+	}
+	year := *a.YearBuilt
+	if year <= preFIRMCutoffYear {
+		return PreFIRM
+	}
+	if c == nil {
+		return FIRMStatusUnknown
+	}
+
+	firm, err := c.InitialFIRMDate()
+	switch {
+	case err != nil:
+		return FIRMStatusUnknown
+	case year < firm.Year():
+		return PreFIRM
+	case year > firm.Year():
+		return PostFIRM
+	default:
+		return FIRMStatusUnknown
+	}
+}
@@ -0,0 +1,112 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata/models"
+)
+
+func ptr(s string) *string {
+	return &s
+}
+
+func TestProperty_Attributes(t *testing.T) {
+	p := models.Property{
+		SqFt:                   ptr("2450"),
+		YearBuilt:              ptr("1968"),
+		PropertyUseDescription: ptr(" Single Family Residential "),
+		ConstructionDesc:       ptr("Masonry"),
+		StoriesCount:           ptr("2"),
+		ParkingGarageType:      ptr("Attached Garage"),
+		ParkingGarageArea:      ptr("420"),
+	}
+
+	a := p.Attributes()
+	require.NotNil(t, a.SqFt)
+	assert.Equal(t, 2450, *a.SqFt)
+	require.NotNil(t, a.YearBuilt)
+	assert.Equal(t, 1968, *a.YearBuilt)
+	require.NotNil(t, a.Stories)
+	assert.Equal(t, 2, *a.Stories)
+	require.NotNil(t, a.GarageArea)
+	assert.Equal(t, 420, *a.GarageArea)
+	assert.Equal(t, "Single Family Residential", a.Use)
+	assert.Equal(t, models.ConstructionMasonry, a.Construction)
+	assert.Equal(t, models.GarageAttached, a.Garage)
+}
+
+func TestProperty_Attributes_ShouldParseLooseNumbers(t *testing.T) {
+	a := models.Property{
+		SqFt:         ptr("2,450 sqft"),
+		StoriesCount: ptr("1.5"),
+		YearBuilt:    ptr("0"),
+	}.Attributes()
+
+	assert.Equal(t, 2450, *a.SqFt)
+	assert.Equal(t, 1, *a.Stories)
+	assert.Nil(t, a.YearBuilt)
+
+	a = models.Property{SqFt: ptr("n/a"), StoriesCount: ptr("")}.Attributes()
+	assert.Nil(t, a.SqFt)
+	assert.Nil(t, a.Stories)
+	assert.Nil(t, a.GarageArea)
+	assert.Equal(t, models.ConstructionUnknown, a.Construction)
+	assert.Equal(t, models.GarageUnknown, a.Garage)
+}
+
+func TestParseConstruction(t *testing.T) {
+	for in, want := range map[string]models.Construction{
+		"Masonry":             models.ConstructionMasonry,
+		"CONCRETE BLOCK":      models.ConstructionMasonry,
+		"Brick Veneer":        models.ConstructionMasonry,
+		"Reinforced Concrete": models.ConstructionConcrete,
+		"Wood Frame":          models.ConstructionFrame,
+		"Steel":               models.ConstructionSteel,
+		"Mobile Home":         models.ConstructionManufactured,
+		"Adobe":               models.ConstructionMasonry,
+		"Straw bale":          models.ConstructionOther,
+		"  ":                  models.ConstructionUnknown,
+	} {
+		assert.Equal(t, want, models.ParseConstruction(in), in)
+	}
+}
+
+func TestParseGarageType(t *testing.T) {
+	for in, want := range map[string]models.GarageType{
+		"Attached Garage":   models.GarageAttached,
+		"Detached Garage":   models.GarageDetached,
+		"Built-in Garage":   models.GarageBuiltIn,
+		"Basement Garage":   models.GarageBasement,
+		"Underground":       models.GarageBasement,
+		"Carport":           models.GarageCarport,
+		"None":              models.GarageNone,
+		"No Garage":         models.GarageNone,
+		"Parking Structure": models.GarageOther,
+		"":                  models.GarageUnknown,
+	} {
+		assert.Equal(t, want, models.ParseGarageType(in), in)
+	}
+}
+
+func TestPropertyAttributes_FIRMStatus(t *testing.T) {
+	palmBeach := &models.Community{Firm: "051578      "}
+	year := func(y int) models.PropertyAttributes {
+		return models.PropertyAttributes{YearBuilt: &y}
+	}
+
+	assert.Equal(t, models.PreFIRM, year(1968).FIRMStatus(palmBeach))
+	assert.Equal(t, models.PreFIRM, year(1976).FIRMStatus(palmBeach))
+	assert.Equal(t, models.FIRMStatusUnknown, year(1978).FIRMStatus(palmBeach))
+	assert.Equal(t, models.PostFIRM, year(1979).FIRMStatus(palmBeach))
+
+	// 1974 and earlier are pre-FIRM everywhere.
+	assert.Equal(t, models.PreFIRM, year(1974).FIRMStatus(nil))
+	assert.Equal(t, models.PreFIRM, year(1974).FIRMStatus(&models.Community{Firm: "010170"}))
+
+	assert.Equal(t, models.FIRMStatusUnknown, year(1990).FIRMStatus(nil))
+	assert.Equal(t, models.FIRMStatusUnknown, year(1990).FIRMStatus(&models.Community{Firm: "(NSFHA)"}))
+	assert.Equal(t, models.FIRMStatusUnknown, models.PropertyAttributes{}.FIRMStatus(palmBeach))
+}
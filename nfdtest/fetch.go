@@ -0,0 +1,63 @@
+package nfdtest
+
+import (
+	"context"
+	"testing"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+	"github.com/kmesiab/go-nationalflooddata/client"
+)
+
+// FloodData returns the default /data fixture as the service decodes it,
+// served from a Server that is closed when t ends: 430 Australian Ave with
+// every add-on section, an AE zone in a participating community with an
+// area BFE of 6 ft, a property elevation of 6.1 ft and three LOMAs that
+// predate the current panel.
+func FloodData(t testing.TB) *client.Response {
+	t.Helper()
+
+	svc := newService(t)
+	resp, err := svc.GetFloodData(context.Background(), client.FloodDataOptions{
+		SearchType: client.SearchTypeAddressParcel,
+		Address:    "430 Australian Ave Palm Beach, FL 33480",
+		LOMA:       true,
+		Elevation:  true,
+		Property:   true,
+	})
+	if err != nil {
+		t.Fatalf("nfdtest: fetching flood data fixture: %v", err)
+	}
+	return resp
+}
+
+// FloodMapRaw returns the default /floodmapraw fixture as the service
+// decodes it, served like FloodData: AE, VE and X regions around 430
+// Australian Ave, and BFE lines of 6 ft at 0.05 km and 7 ft at 0.21 km.
+func FloodMapRaw(t testing.TB) *client.FloodMapContent {
+	t.Helper()
+
+	svc := newService(t)
+	content, err := svc.GetFloodMapRaw(context.Background(), client.FloodMapRawOptions{
+		Lat:       26.7032,
+		Lng:       -80.0424,
+		GeoJSON:   true,
+		Elevation: true,
+	})
+	if err != nil {
+		t.Fatalf("nfdtest: fetching flood map fixture: %v", err)
+	}
+	return content
+}
+
+// newService returns a Service for a Server that is closed when t ends.
+func newService(t testing.TB) *nfd.Service {
+	t.Helper()
+
+	srv := NewServer()
+	t.Cleanup(srv.Close)
+	svc, err := srv.NewService()
+	if err != nil {
+		t.Fatalf("nfdtest: %v", err)
+	}
+	return svc
+}
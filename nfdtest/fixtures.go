@@ -0,0 +1,69 @@
+package nfdtest
+
+import (
+	"bytes"
+	_ "embed"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+//go:embed testdata/flood_data.json
+var floodDataFixture []byte
+
+//go:embed testdata/flood_map_raw.json
+var floodMapRawFixture []byte
+
+// Fixtures are the canned payloads a Server answers with.
+type Fixtures struct {
+	// FloodData is the full /data response, including every add-on section.
+	// Sections the request did not ask for are removed before it is served,
+	// and "request" is rewritten to echo the query.
+	FloodData []byte
+
+	// FloodMapRaw is the /floodmapraw response with geojson and BFE lines.
+	FloodMapRaw []byte
+
+	// VectorTile is served for every /tiles/flood-vector tile.
+	VectorTile []byte
+
+	// StormSurgeTile is served for every /tiles/stormsurge tile.
+	StormSurgeTile []byte
+
+	// StaticMap is served for every /staticmap image.
+	StaticMap []byte
+}
+
+// DefaultFixtures returns fixtures for 430 Australian Ave, Palm Beach, FL, a
+// coastal AE zone parcel with LOMA, elevation and property data.
+func DefaultFixtures() Fixtures {
+	return Fixtures{
+		FloodData:      floodDataFixture,
+		FloodMapRaw:    floodMapRawFixture,
+		VectorTile:     vectorTile,
+		StormSurgeTile: solidPNG(color.NRGBA{B: 255, A: 96}),
+		StaticMap:      solidPNG(color.NRGBA{R: 200, G: 220, B: 255, A: 255}),
+	}
+}
+
+// vectorTile is a Mapbox Vector Tile holding a single empty "flood" layer.
+var vectorTile = []byte{
+	0x1a, 0x0c, // layers, 12 bytes
+	0x78, 0x02, // version 2
+	0x0a, 0x05, 'f', 'l', 'o', 'o', 'd', // name
+	0x28, 0x80, 0x20, // extent 4096
+}
+
+// solidPNG encodes a 256x256 PNG filled with c.
+func solidPNG(c color.Color) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, 256, 256))
+	for y := 0; y < 256; y++ {
+		for x := 0; x < 256; x++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+	return buf.Bytes()
+}
@@ -0,0 +1,370 @@
+package nfdtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+	"github.com/kmesiab/go-nationalflooddata/client"
+)
+
+// errorMessages are the messages the API documents for each error status.
+var errorMessages = map[int]string{
+	http.StatusBadRequest:          "Invalid Request",
+	http.StatusUnauthorized:        "Unauthorized",
+	http.StatusPaymentRequired:     "No data available",
+	http.StatusNotFound:            "Address/ Parcel not found",
+	http.StatusMethodNotAllowed:    "Parcel not found",
+	http.StatusTooManyRequests:     "Too Many Requests",
+	http.StatusInternalServerError: "Internal Server Error. Try again later.",
+}
+
+var stormSurgeCategories = map[string]bool{
+	"category1": true, "category2": true, "category3": true, "category4": true, "category5": true,
+}
+
+// keyFunc extracts the API key a request authenticates with.
+type keyFunc func(r *http.Request) string
+
+func headerKey(r *http.Request) string { return r.Header.Get("x-api-key") }
+
+func queryKey(r *http.Request) string { return r.URL.Query().Get("key") }
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.Handle("GET /v3/data", s.endpoint(nfd.EndpointGetFloodData, headerKey, s.handleData))
+	mux.Handle("POST /v3/databatch", s.endpoint(nfd.EndpointGetFloodDataBatch, headerKey, s.handleDataBatch))
+	mux.Handle("GET /v3/floodmapraw", s.endpoint(nfd.EndpointGetFloodMapRaw, headerKey, s.handleFloodMapRaw))
+	mux.Handle("GET /v3/tiles/flood-vector/{z}/{x}/{tile}", s.endpoint(nfd.EndpointGetFloodVectorTile, headerKey, s.handleVectorTile))
+	mux.Handle("GET /v3/tiles/stormsurge/{category}/{z}/{x}/{tile}", s.endpoint(nfd.EndpointGetStormSurgeTile, headerKey, s.handleStormSurgeTile))
+	mux.Handle("GET /v3/staticmap", s.endpoint(nfd.EndpointGetStaticFloodMap, headerKey, s.handleStaticMap))
+	mux.Handle("GET /v3/dynamic.html", s.endpoint(nfd.EndpointGetDynamicFloodMap, queryKey, s.handleDynamicMap))
+
+	// Batch results live behind a presigned URL and need no API key.
+	mux.Handle("GET /results/{id}", s.endpoint(EndpointBatchResult, nil, s.handleBatchResult))
+
+	return mux
+}
+
+// endpoint wraps a handler with request recording, latency, fault
+// injection and API key checks.
+func (s *Server) endpoint(name string, key keyFunc, h http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+
+		rec := Request{Endpoint: name, Method: r.Method, URL: r.URL, Body: body}
+		if key != nil {
+			rec.APIKey = key(r)
+		}
+		s.record(rec)
+
+		delay := s.latency
+		fault := s.nextFault(name)
+		if fault != nil {
+			delay += fault.Latency
+		}
+		if delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if fault != nil && fault.Status != 0 {
+			writeError(w, fault.Status, fault.Message)
+			return
+		}
+
+		if key != nil && s.apiKey != "" && rec.APIKey != s.apiKey {
+			writeError(w, http.StatusUnauthorized, "")
+			return
+		}
+
+		h(w, r)
+	})
+}
+
+func (s *Server) handleData(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if msg := validateSearch(client.SearchType(q.Get("searchtype")), q.Get("address"), q.Get("lat"), q.Get("lng"), q.Get("polygon")); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	data, err := s.floodData(q)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, data)
+}
+
+func (s *Server) handleDataBatch(w http.ResponseWriter, r *http.Request) {
+	var req client.BatchDataRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Requests) == 0 {
+		writeError(w, http.StatusBadRequest, "")
+		return
+	}
+	if s.apiKey != "" && req.APIKey != s.apiKey {
+		writeError(w, http.StatusUnauthorized, "")
+		return
+	}
+
+	results := make([]json.RawMessage, 0, len(req.Requests))
+	for _, br := range req.Requests {
+		if msg := validateSearch(br.SearchType, br.Address, br.Lat, br.Lng, br.Polygon); msg != "" {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("request %s: %s", br.ID, msg))
+			return
+		}
+
+		data, err := s.floodData(batchQuery(br))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		data["id"] = br.ID
+
+		raw, err := json.Marshal(data)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		results = append(results, raw)
+	}
+
+	s.mu.Lock()
+	id := fmt.Sprintf("batch-%d", len(s.batches)+1)
+	s.batches[id] = &batch{results: results, polls: s.pending}
+	s.mu.Unlock()
+
+	writeJSON(w, client.FloodDataBatch{
+		BatchID: id,
+		Result:  fmt.Sprintf("%s/results/%s.json", s.URL, id),
+	})
+}
+
+func (s *Server) handleBatchResult(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSuffix(r.PathValue("id"), ".json")
+
+	s.mu.Lock()
+	b, ok := s.batches[id]
+	pending := ok && b.polls > 0
+	if pending {
+		b.polls--
+	}
+	s.mu.Unlock()
+
+	if !ok || pending {
+		http.Error(w, "NoSuchKey", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, b.results)
+}
+
+func (s *Server) handleFloodMapRaw(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if !isFloat(q.Get("lat")) || !isFloat(q.Get("lng")) {
+		writeError(w, http.StatusBadRequest, "")
+		return
+	}
+
+	var content client.FloodMapContent
+	if err := json.Unmarshal(s.fixtures.FloodMapRaw, &content); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	regions := content.Result.FloodRegions[:0]
+	for _, region := range content.Result.FloodRegions {
+		if q.Get("excludex") == "true" && region.FldZone == "X" {
+			continue
+		}
+		if q.Get("geojson") == "false" {
+			region.GeoJSON = ""
+		}
+		regions = append(regions, region)
+	}
+	content.Result.FloodRegions = regions
+
+	if q.Get("elevation") != "true" {
+		content.Result.BFEList = nil
+	}
+
+	writeJSON(w, content)
+}
+
+func (s *Server) handleVectorTile(w http.ResponseWriter, r *http.Request) {
+	if !validTile(r, ".mvt") {
+		writeError(w, http.StatusBadRequest, "")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.mapbox-vector-tile")
+	_, _ = w.Write(s.fixtures.VectorTile)
+}
+
+func (s *Server) handleStormSurgeTile(w http.ResponseWriter, r *http.Request) {
+	if !stormSurgeCategories[r.PathValue("category")] || !validTile(r, ".png") {
+		writeError(w, http.StatusBadRequest, "")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	_, _ = w.Write(s.fixtures.StormSurgeTile)
+}
+
+func (s *Server) handleStaticMap(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if !isFloat(q.Get("lat")) || !isFloat(q.Get("lng")) {
+		writeError(w, http.StatusBadRequest, "")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	_, _ = w.Write(s.fixtures.StaticMap)
+}
+
+func (s *Server) handleDynamicMap(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if !isFloat(q.Get("lat")) || !isFloat(q.Get("lng")) || q.Get("zoom") == "" {
+		writeError(w, http.StatusBadRequest, "")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>National Flood Data</title></head>
+<body>
+<div id="map" data-lat="%s" data-lng="%s" data-zoom="%s" data-legend="%s"></div>
+</body>
+</html>
+`, html.EscapeString(q.Get("lat")), html.EscapeString(q.Get("lng")),
+		html.EscapeString(q.Get("zoom")), html.EscapeString(q.Get("showLegend")))
+}
+
+// floodData builds a /data response for q from the fixture. Add-on sections
+// that were not requested are removed and denied ones read "Access Denied".
+func (s *Server) floodData(q url.Values) (map[string]interface{}, error) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(s.fixtures.FloodData, &data); err != nil {
+		return nil, fmt.Errorf("flood data fixture: %w", err)
+	}
+
+	data["request"] = map[string]interface{}{
+		"searchtype": q.Get("searchtype"),
+		"address":    q.Get("address"),
+		"lat":        noneIfEmpty(q.Get("lat")),
+		"lng":        noneIfEmpty(q.Get("lng")),
+		"apn":        nil,
+		"county":     nil,
+		"state":      nil,
+	}
+	data["match_type"] = q.Get("searchtype")
+
+	result, _ := data["result"].(map[string]interface{})
+	if result == nil {
+		return data, nil
+	}
+
+	for _, section := range []string{SectionLOMA, SectionElevation, SectionProperty, SectionParcel} {
+		switch {
+		case q.Get(section) != "true":
+			delete(result, section)
+		case s.isDenied(section):
+			result[section] = "Access Denied"
+		}
+	}
+
+	return data, nil
+}
+
+// validateSearch checks the parameters a search type requires and returns
+// a message describing the first problem, or "" if there is none.
+func validateSearch(searchType client.SearchType, address, lat, lng, polygon string) string {
+	switch searchType {
+	case client.SearchTypeAddressCoord, client.SearchTypeAddressParcel:
+		if address == "" {
+			return "address is required"
+		}
+	case client.SearchTypeCoord, client.SearchTypeCoordParcel:
+		if !isFloat(lat) || !isFloat(lng) {
+			return "lat and lng are required"
+		}
+	case client.SearchTypePolygon:
+		if polygon == "" {
+			return "polygon is required"
+		}
+	default:
+		return "Invalid Request"
+	}
+	return ""
+}
+
+func batchQuery(br client.BatchRequest) url.Values {
+	q := url.Values{}
+	q.Set("searchtype", string(br.SearchType))
+	q.Set("address", br.Address)
+	q.Set("lat", br.Lat)
+	q.Set("lng", br.Lng)
+	q.Set("polygon", br.Polygon)
+	q.Set(SectionLOMA, strconv.FormatBool(br.LOMA))
+	q.Set(SectionElevation, strconv.FormatBool(br.Elevation))
+	q.Set(SectionProperty, strconv.FormatBool(br.Property))
+	q.Set(SectionParcel, strconv.FormatBool(br.Parcel))
+	return q
+}
+
+func validTile(r *http.Request, ext string) bool {
+	y, ok := strings.CutSuffix(r.PathValue("tile"), ext)
+	if !ok {
+		return false
+	}
+	for _, v := range []string{r.PathValue("z"), r.PathValue("x"), y} {
+		if _, err := strconv.Atoi(v); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func isFloat(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+// noneIfEmpty mirrors the API, which echoes missing coordinates as "None".
+func noneIfEmpty(s string) string {
+	if s == "" {
+		return "None"
+	}
+	return s
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	if message == "" {
+		message = errorMessages[status]
+	}
+	if message == "" {
+		message = http.StatusText(status)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": status, "message": message})
+}
@@ -0,0 +1,245 @@
+// Package nfdtest provides an in-process fake of the National Flood Data API
+// for tests. A Server answers every v3 endpoint from fixtures and can be
+// programmed to fail, slow down or deny add-on sections on demand.
+package nfdtest
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+)
+
+// DefaultAPIKey is the key a Server accepts when none is configured with
+// WithAPIKey.
+const DefaultAPIKey = "nfdtest-api-key"
+
+// EndpointBatchResult identifies the presigned batch result URL returned by
+// /databatch. The other endpoints use the nfd.Endpoint* names.
+const EndpointBatchResult = "BatchResult"
+
+// Add-on sections of a /data result that can be denied with DenyAccess.
+const (
+	SectionLOMA      = "loma"
+	SectionElevation = "elevation"
+	SectionProperty  = "property"
+	SectionParcel    = "parcel"
+)
+
+// Fault describes an injected failure for an endpoint.
+type Fault struct {
+	// Status is the HTTP status to answer with, such as 401 or 500. Zero
+	// serves the normal response, which is useful with Latency alone.
+	Status int
+
+	// Message is the "message" field of the error body. It defaults to the
+	// message the API documents for Status.
+	Message string
+
+	// Latency delays the response.
+	Latency time.Duration
+
+	// Times limits the fault to the next Times requests. Zero applies it to
+	// every request until Reset.
+	Times int
+}
+
+// Request is a request the Server received.
+type Request struct {
+	// Endpoint is the nfd.Endpoint* name, or EndpointBatchResult.
+	Endpoint string
+	Method   string
+	URL      *url.URL
+
+	// APIKey is the x-api-key header, or the key query parameter for
+	// /dynamic.html.
+	APIKey string
+
+	// Body is the request body, if any.
+	Body []byte
+}
+
+// Server is a fake National Flood Data API. The embedded httptest.Server
+// serves the API under "/v3"; use BaseURL or NewService to point a client at
+// it.
+type Server struct {
+	*httptest.Server
+
+	apiKey   string
+	latency  time.Duration
+	pending  int
+	fixtures Fixtures
+
+	mu       sync.Mutex
+	faults   map[string][]*Fault
+	denied   map[string]bool
+	batches  map[string]*batch
+	requests []Request
+}
+
+type batch struct {
+	results []json.RawMessage
+	polls   int
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithAPIKey sets the key the Server accepts. An empty key accepts any key.
+func WithAPIKey(key string) Option {
+	return func(s *Server) {
+		s.apiKey = key
+	}
+}
+
+// WithFixtures replaces the default fixtures. Empty fields keep their
+// defaults.
+func WithFixtures(f Fixtures) Option {
+	return func(s *Server) {
+		if f.FloodData != nil {
+			s.fixtures.FloodData = f.FloodData
+		}
+		if f.FloodMapRaw != nil {
+			s.fixtures.FloodMapRaw = f.FloodMapRaw
+		}
+		if f.VectorTile != nil {
+			s.fixtures.VectorTile = f.VectorTile
+		}
+		if f.StormSurgeTile != nil {
+			s.fixtures.StormSurgeTile = f.StormSurgeTile
+		}
+		if f.StaticMap != nil {
+			s.fixtures.StaticMap = f.StaticMap
+		}
+	}
+}
+
+// WithLatency delays every response by d.
+func WithLatency(d time.Duration) Option {
+	return func(s *Server) {
+		s.latency = d
+	}
+}
+
+// WithBatchPending makes each batch result URL answer 404 for the first n
+// polls, as if the batch were still processing.
+func WithBatchPending(n int) Option {
+	return func(s *Server) {
+		s.pending = n
+	}
+}
+
+// NewServer starts a Server. Callers should Close it when done.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		apiKey:   DefaultAPIKey,
+		fixtures: DefaultFixtures(),
+		faults:   make(map[string][]*Fault),
+		denied:   make(map[string]bool),
+		batches:  make(map[string]*batch),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.Server = httptest.NewServer(s.routes())
+	return s
+}
+
+// BaseURL returns the v3 base URL of the Server.
+func (s *Server) BaseURL() string {
+	return s.URL + "/v3"
+}
+
+// NewService returns a Service configured against the Server with the
+// accepted API key. opts are applied after the base URL and HTTP client.
+func (s *Server) NewService(opts ...nfd.Option) (*nfd.Service, error) {
+	apiKey := s.apiKey
+	if apiKey == "" {
+		apiKey = DefaultAPIKey
+	}
+
+	opts = append([]nfd.Option{
+		nfd.WithBaseURL(s.BaseURL()),
+		nfd.WithHTTPClient(s.Client()),
+	}, opts...)
+
+	return nfd.NewService(apiKey, opts...)
+}
+
+// Inject queues a fault for endpoint, one of the nfd.Endpoint* names or
+// EndpointBatchResult. Faults for an endpoint apply in the order injected.
+func (s *Server) Inject(endpoint string, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults[endpoint] = append(s.faults[endpoint], &f)
+}
+
+// DenyAccess makes /data answer "Access Denied" for the given add-on
+// sections when they are requested, as the API does for keys that are not
+// licensed for them.
+func (s *Server) DenyAccess(sections ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, section := range sections {
+		s.denied[section] = true
+	}
+}
+
+// Reset clears injected faults, denied sections and recorded requests.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = make(map[string][]*Fault)
+	s.denied = make(map[string]bool)
+	s.requests = nil
+}
+
+// Requests returns the requests received so far, oldest first.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// nextFault returns the fault to apply to the current request for
+// endpoint, consuming one use of it.
+func (s *Server) nextFault(endpoint string) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue := s.faults[endpoint]
+	if len(queue) == 0 {
+		return nil
+	}
+
+	f := queue[0]
+	if f.Times > 0 {
+		f.Times--
+		if f.Times == 0 {
+			s.faults[endpoint] = queue[1:]
+		}
+	}
+	return f
+}
+
+func (s *Server) isDenied(section string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.denied[section]
+}
+
+func (s *Server) record(r Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r)
+}
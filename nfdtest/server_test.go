@@ -0,0 +1,215 @@
+package nfdtest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+)
+
+func newServer(t *testing.T, opts ...nfdtest.Option) (*nfdtest.Server, *nfd.Service) {
+	t.Helper()
+
+	srv := nfdtest.NewServer(opts...)
+	t.Cleanup(srv.Close)
+
+	svc, err := srv.NewService()
+	require.NoError(t, err)
+
+	return srv, svc
+}
+
+var addressParcel = client.FloodDataOptions{
+	SearchType: client.SearchTypeAddressParcel,
+	Address:    "430 Australian Ave Palm Beach, FL 33480",
+	LOMA:       true,
+	Elevation:  true,
+	Property:   true,
+}
+
+func TestServer_GetFloodDataShouldServeRequestedSections(t *testing.T) {
+	_, svc := newServer(t)
+
+	resp, err := svc.GetFloodData(context.Background(), addressParcel)
+	require.NoError(t, err)
+
+	assert.Equal(t, "OK", resp.Status)
+	assert.Equal(t, addressParcel.Address, resp.Request.Address)
+	assert.Equal(t, "AE", resp.Result.FloodFldHazAr[0].FldZone)
+	require.NotNil(t, resp.Result.Elevation)
+	assert.NotEmpty(t, resp.Result.Elevation.FloodBaseFloodElevation)
+	require.NotNil(t, resp.Result.Property)
+	require.NotNil(t, resp.Result.Loma)
+}
+
+func TestServer_GetFloodDataShouldOmitSectionsNotRequested(t *testing.T) {
+	_, svc := newServer(t)
+
+	resp, err := svc.GetFloodData(context.Background(), client.FloodDataOptions{
+		SearchType: client.SearchTypeCoord,
+		Lat:        26.7032278,
+		Lng:        -80.0423758,
+	})
+	require.NoError(t, err)
+
+	assert.Nil(t, resp.Result.Elevation)
+	assert.Nil(t, resp.Result.Property)
+	assert.Nil(t, resp.Result.Loma)
+}
+
+func TestServer_DenyAccessShouldAnswerAccessDenied(t *testing.T) {
+	srv, svc := newServer(t)
+	srv.DenyAccess(nfdtest.SectionElevation, nfdtest.SectionProperty)
+
+	raw, _, err := svc.DoRequest(context.Background(), http.MethodGet, "/data",
+		map[string][]string{"searchtype": {"addressparcel"}, "address": {"x"}, "elevation": {"true"}, "property": {"true"}}, nil)
+	require.NoError(t, err)
+
+	var data struct {
+		Result map[string]interface{} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &data))
+	assert.Equal(t, "Access Denied", data.Result["elevation"])
+	assert.Equal(t, "Access Denied", data.Result["property"])
+
+	resp, err := svc.GetFloodData(context.Background(), addressParcel)
+	require.NoError(t, err)
+	assert.Nil(t, resp.Result.Elevation)
+	assert.Nil(t, resp.Result.Property)
+	assert.NotNil(t, resp.Result.Loma)
+}
+
+func TestServer_InjectShouldReturnTypedErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		target interface{}
+	}{
+		{http.StatusBadRequest, new(*client.InvalidRequestError)},
+		{http.StatusUnauthorized, new(*client.AuthenticationError)},
+		{http.StatusPaymentRequired, new(*client.NoDataAvailableError)},
+		{http.StatusNotFound, new(*client.LocationNotFoundError)},
+		{http.StatusMethodNotAllowed, new(*client.ParcelNotFoundError)},
+		{http.StatusInternalServerError, new(*client.InternalServerError)},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			srv, svc := newServer(t)
+			srv.Inject(nfd.EndpointGetFloodData, nfdtest.Fault{Status: tt.status, Times: 1})
+
+			_, err := svc.GetFloodData(context.Background(), addressParcel)
+			require.ErrorAs(t, err, tt.target)
+
+			// The fault was used up, so the next call succeeds.
+			_, err = svc.GetFloodData(context.Background(), addressParcel)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestServer_ShouldRejectUnknownAPIKey(t *testing.T) {
+	srv := nfdtest.NewServer()
+	defer srv.Close()
+
+	svc, err := nfd.NewService("wrong-key", nfd.WithBaseURL(srv.BaseURL()))
+	require.NoError(t, err)
+
+	_, err = svc.GetFloodVectorTile(context.Background(), 13, 2043, 3140)
+	var authErr *client.AuthenticationError
+	require.ErrorAs(t, err, &authErr)
+	assert.Equal(t, "Unauthorized", authErr.Message)
+}
+
+func TestServer_InjectedLatencyShouldTripClientTimeout(t *testing.T) {
+	srv := nfdtest.NewServer()
+	defer srv.Close()
+	srv.Inject(nfd.EndpointGetStaticFloodMap, nfdtest.Fault{Latency: time.Second})
+
+	svc, err := srv.NewService(nfd.WithTimeout(50 * time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = svc.GetStaticFloodMap(context.Background(), client.StaticMapOptions{Lat: 26.7, Lng: -80.04, Height: 300, Width: 300, Zoom: 13})
+	require.Error(t, err)
+}
+
+func TestServer_BatchResultShouldBecomeAvailableAfterPendingPolls(t *testing.T) {
+	srv, svc := newServer(t, nfdtest.WithBatchPending(1))
+
+	batch, err := svc.GetFloodDataBatch(context.Background(), client.BatchDataRequest{
+		Requests: []client.BatchRequest{
+			{ID: "r1", SearchType: client.SearchTypeCoord, Lat: "26.70", Lng: "-80.04", Elevation: true},
+			{ID: "r2", SearchType: client.SearchTypeAddressCoord, Address: "1 Main St"},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(batch.Result, srv.URL))
+
+	resp, err := http.Get(batch.Result)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = http.Get(batch.Result)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var results []struct {
+		ID string `json:"id"`
+		client.Response
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&results))
+	require.Len(t, results, 2)
+	assert.Equal(t, "r1", results[0].ID)
+	assert.NotNil(t, results[0].Result.Elevation)
+	assert.Equal(t, "r2", results[1].ID)
+	assert.Nil(t, results[1].Result.Elevation)
+}
+
+func TestServer_MapAndTileEndpointsShouldServeFixtures(t *testing.T) {
+	srv, svc := newServer(t)
+	ctx := context.Background()
+
+	content, err := svc.GetFloodMapRaw(ctx, client.FloodMapRawOptions{Lat: 26.70, Lng: -80.04, GeoJSON: true, ExcludeX: true, Elevation: true})
+	require.NoError(t, err)
+	assert.Len(t, content.Result.FloodRegions, 2)
+	assert.NotEmpty(t, content.Result.BFEList)
+
+	tile, err := svc.GetFloodVectorTile(ctx, 13, 2043, 3140)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tile)
+
+	surge, err := svc.GetStormSurgeTile(ctx, "category3", 13, 2043, 3140)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(surge), "\x89PNG"))
+
+	html, err := svc.GetDynamicFloodMap(ctx, nfdtest.DefaultAPIKey, 26.70, -80.04, 13, true)
+	require.NoError(t, err)
+	assert.Contains(t, html, `data-zoom="13"`)
+
+	requests := srv.Requests()
+	require.Len(t, requests, 4)
+	assert.Equal(t, nfd.EndpointGetDynamicFloodMap, requests[3].Endpoint)
+	assert.Equal(t, nfdtest.DefaultAPIKey, requests[3].APIKey)
+}
+
+func TestFloodDataAndFloodMapRaw_ShouldDecodeDefaultFixtures(t *testing.T) {
+	resp := nfdtest.FloodData(t)
+	require.NotNil(t, resp.Result.Elevation)
+	assert.Equal(t, 6.1, resp.Result.Elevation.PropertyElevation)
+	require.NotNil(t, resp.Result.Loma)
+	assert.Len(t, *resp.Result.Loma, 3)
+	require.NotNil(t, resp.Result.Property)
+
+	content := nfdtest.FloodMapRaw(t)
+	assert.Len(t, content.Result.BFEList, 2)
+}
@@ -0,0 +1,168 @@
+package go_nationalflooddata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Environment variables read by NewServiceFromEnv.
+const (
+	EnvAPIKey  = "NFD_API_KEY"
+	EnvBaseURL = "NFD_BASE_URL"
+)
+
+// ErrInvalidConfig is wrapped by every configuration error returned from
+// NewService and NewServiceFromEnv.
+var ErrInvalidConfig = errors.New("invalid service configuration")
+
+// Logger is the logging interface used by the Service. *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Limiter throttles outgoing requests. Wait blocks until a request may be
+// sent or the context is done. *rate.Limiter from golang.org/x/time/rate
+// satisfies it.
+type Limiter interface {
+	Wait(ctx context.Context) error
+}
+
+// Option configures a Service created with NewService.
+type Option func(*config)
+
+// config collects the settings from every Option so they can be validated
+// together before the Service is built.
+type config struct {
+	baseURL    string
+	httpClient *http.Client
+	timeout    time.Duration
+	userAgent  string
+	retry      *RetryPolicy
+	cache      Cache
+	logger     Logger
+	limiter    Limiter
+	keys       KeyProvider
+	usage      UsageTracker
+}
+
+// WithBaseURL overrides the default API base URL. It must be an absolute
+// http or https URL.
+func WithBaseURL(baseURL string) Option {
+	return func(c *config) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to make requests.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *config) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout sets the overall timeout for each HTTP request. The configured
+// HTTP client is copied, never modified in place.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.timeout = timeout
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *config) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithRetry retries transient failures according to policy.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *config) {
+		c.retry = &policy
+	}
+}
+
+// WithCache serves repeated GET requests from cache. Responses are cached
+// per API key, and the keys themselves are never stored.
+func WithCache(cache Cache) Option {
+	return func(c *config) {
+		c.cache = cache
+	}
+}
+
+// WithLogger logs every HTTP attempt to logger.
+func WithLogger(logger Logger) Option {
+	return func(c *config) {
+		c.logger = logger
+	}
+}
+
+// WithLimiter waits on limiter before every HTTP attempt.
+func WithLimiter(limiter Limiter) Option {
+	return func(c *config) {
+		c.limiter = limiter
+	}
+}
+
+// WithKeyProvider chooses the API key for every call from provider, such as
+// a KeyPool, instead of the key passed to NewService.
+func WithKeyProvider(provider KeyProvider) Option {
+	return func(c *config) {
+		c.keys = provider
+	}
+}
+
+// WithUsageTracker records every lookup to tracker.
+func WithUsageTracker(tracker UsageTracker) Option {
+	return func(c *config) {
+		c.usage = tracker
+	}
+}
+
+// validate reports every problem with the configuration at once.
+func (c *config) validate() error {
+	var errs []error
+
+	if u, err := url.Parse(c.baseURL); err != nil {
+		errs = append(errs, fmt.Errorf("%w: base URL %q: %v", ErrInvalidConfig, c.baseURL, err))
+	} else if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("%w: base URL %q must be an absolute http or https URL", ErrInvalidConfig, c.baseURL))
+	}
+
+	if c.httpClient == nil {
+		errs = append(errs, fmt.Errorf("%w: HTTP client must not be nil", ErrInvalidConfig))
+	}
+
+	if c.timeout < 0 {
+		errs = append(errs, fmt.Errorf("%w: timeout must not be negative, got %s", ErrInvalidConfig, c.timeout))
+	}
+
+	if c.retry != nil {
+		if err := c.retry.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %v", ErrInvalidConfig, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// NewServiceFromEnv creates a Service using the API key in NFD_API_KEY and,
+// if set, the base URL in NFD_BASE_URL. Options passed explicitly are applied
+// after the environment, so they take precedence.
+func NewServiceFromEnv(opts ...Option) (*Service, error) {
+	apiKey := os.Getenv(EnvAPIKey)
+	if apiKey == "" {
+		return nil, fmt.Errorf("%w: %s is not set", ErrInvalidConfig, EnvAPIKey)
+	}
+
+	if baseURL := os.Getenv(EnvBaseURL); baseURL != "" {
+		opts = append([]Option{WithBaseURL(baseURL)}, opts...)
+	}
+
+	return NewService(apiKey, opts...)
+}
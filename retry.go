@@ -0,0 +1,160 @@
+package go_nationalflooddata
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how transient failures are retried. Transport errors
+// and 429, 500, 502, 503 and 504 responses to GET and HEAD requests are
+// retried with exponential backoff, or after the response's Retry-After
+// when it has one; every other response is returned immediately.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+
+	// InitialBackoff is the wait before the second attempt. It doubles after
+	// every further attempt.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the wait between attempts. Zero means no cap.
+	MaxBackoff time.Duration
+
+	// RetryPOST also retries POST requests, such as GetFloodDataBatch.
+	// A POST whose first attempt reached the API may then be submitted,
+	// and billed, twice.
+	RetryPOST bool
+}
+
+func (p RetryPolicy) validate() error {
+	if p.MaxAttempts < 1 {
+		return fmt.Errorf("retry max attempts must be at least 1, got %d", p.MaxAttempts)
+	}
+	if p.InitialBackoff < 0 || p.MaxBackoff < 0 {
+		return errors.New("retry backoff must not be negative")
+	}
+	if p.MaxBackoff > 0 && p.MaxBackoff < p.InitialBackoff {
+		return fmt.Errorf("retry max backoff %s is shorter than initial backoff %s", p.MaxBackoff, p.InitialBackoff)
+	}
+	return nil
+}
+
+// backoff returns the wait before the given retry, counting from 1.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < retry; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return d
+}
+
+// retries reports whether requests with method may be retried at all.
+func (p RetryPolicy) retries(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return true
+	case http.MethodPost:
+		return p.RetryPOST
+	default:
+		return false
+	}
+}
+
+// wait returns how long to wait before the given retry: the response's
+// Retry-After when it has a valid one, and the backoff otherwise.
+func (p RetryPolicy) wait(resp *http.Response, retry int) time.Duration {
+	if resp != nil {
+		if d, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			return d
+		}
+	}
+	return p.backoff(retry)
+}
+
+// retryAfter parses a Retry-After header, either delay seconds or an HTTP
+// date, relative to now.
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	at, err := http.ParseTime(header)
+	if err != nil {
+		return 0, false
+	}
+	if d := at.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// retryable reports whether an attempt with this outcome should be retried.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryMiddleware re-issues an Invocation according to policy.
+func retryMiddleware(policy RetryPolicy) Middleware {
+	return func(next Handler) Handler {
+		return func(inv *Invocation) (*http.Response, error) {
+			ctx := inv.Request.Context()
+
+			for attempt := 1; ; attempt++ {
+				resp, err := next(inv)
+				if attempt >= policy.MaxAttempts || !policy.retries(inv.Request.Method) ||
+					!retryable(resp, err) || ctx.Err() != nil {
+					return resp, err
+				}
+
+				wait := policy.wait(resp, attempt)
+
+				// Release the failed attempt before trying again.
+				if resp != nil {
+					_, _ = io.Copy(io.Discard, resp.Body)
+					resp.Body.Close()
+				}
+
+				if inv.Request.GetBody != nil {
+					body, bodyErr := inv.Request.GetBody()
+					if bodyErr != nil {
+						return nil, fmt.Errorf("rewinding request body: %w", bodyErr)
+					}
+					inv.Request.Body = body
+				}
+
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return nil, ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+	}
+}
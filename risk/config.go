@@ -0,0 +1,195 @@
+package risk
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Factor names, as used in Config.Weights and Factor.Name.
+const (
+	FactorZone       = "zone"
+	FactorCoastline  = "coastline"
+	FactorWaterbody  = "waterbody"
+	FactorStormSurge = "storm_surge"
+	FactorElevation  = "elevation"
+	FactorProperty   = "property"
+)
+
+// factorNames lists the factors in the order a Score explains them.
+var factorNames = []string{
+	FactorZone, FactorCoastline, FactorWaterbody, FactorStormSurge, FactorElevation, FactorProperty,
+}
+
+// Config weighs and scales the factors of a Scorer. Every factor scores
+// from 0, no risk, to 100; the total is their weighted mean. Load one with
+// LoadConfig, which starts from DefaultConfig so files need only list what
+// they change.
+type Config struct {
+	// Weights are the relative weights of the factors, by name. Factors
+	// without data are left out and the others reweighted.
+	Weights map[string]float64 `yaml:"weights" json:"weights"`
+
+	// Zone scores each zone class: coastal, sfha, moderate, minimal and
+	// undetermined.
+	Zone map[string]float64 `yaml:"zone" json:"zone"`
+
+	// Coastline and Waterbody score distance to water.
+	Coastline Distance `yaml:"coastline" json:"coastline"`
+	Waterbody Distance `yaml:"waterbody" json:"waterbody"`
+
+	// StormSurge scores the lowest hurricane category, 1 to 5, that
+	// floods the property.
+	StormSurge [5]float64 `yaml:"storm_surge" json:"storm_surge"`
+
+	// Elevation scores each elevation Indicator except NoBFE, for which
+	// the factor is left out.
+	Elevation map[Indicator]float64 `yaml:"elevation" json:"elevation"`
+
+	Property PropertyConfig `yaml:"property" json:"property"`
+
+	// Bands name ranges of the total score, each from its Min up to the
+	// next band's.
+	Bands []Band `yaml:"bands" json:"bands"`
+}
+
+// Distance scores a distance: 100 at NearKm or closer, 0 at FarKm or
+// farther, and linearly in between.
+type Distance struct {
+	NearKm float64 `yaml:"near_km" json:"near_km"`
+	FarKm  float64 `yaml:"far_km" json:"far_km"`
+}
+
+// PropertyConfig scores the building: Base, plus PreFIRM if it is
+// pre-FIRM (see models.PropertyAttributes.FIRMStatus), and so not built to
+// flood elevation standards, plus SingleStory if it has one story and no
+// floor to retreat to. The sum is capped at 100.
+type PropertyConfig struct {
+	Base        float64 `yaml:"base" json:"base"`
+	PreFIRM     float64 `yaml:"pre_firm" json:"pre_firm"`
+	SingleStory float64 `yaml:"single_story" json:"single_story"`
+}
+
+// Band is a named range of total scores.
+type Band struct {
+	Name string  `yaml:"name" json:"name"`
+	Min  float64 `yaml:"min" json:"min"`
+}
+
+// DefaultConfig returns the configuration scores use unless told
+// otherwise.
+func DefaultConfig() Config {
+	return Config{
+		Weights: map[string]float64{
+			FactorZone:       35,
+			FactorCoastline:  10,
+			FactorWaterbody:  10,
+			FactorStormSurge: 15,
+			FactorElevation:  20,
+			FactorProperty:   10,
+		},
+		Zone: map[string]float64{
+			ZoneClassCoastal:      100,
+			ZoneClassSFHA:         85,
+			ZoneClassUndetermined: 50,
+			ZoneClassModerate:     40,
+			ZoneClassMinimal:      10,
+		},
+		Coastline:  Distance{NearKm: 0.5, FarKm: 10},
+		Waterbody:  Distance{NearKm: 0.1, FarKm: 2},
+		StormSurge: [5]float64{100, 80, 60, 40, 20},
+		Elevation: map[Indicator]float64{
+			BelowBFE:     100,
+			NearBFE:      70,
+			AboveBFE:     35,
+			WellAboveBFE: 10,
+		},
+		Property: PropertyConfig{Base: 20, PreFIRM: 40, SingleStory: 20},
+		Bands: []Band{
+			{Name: "low", Min: 0},
+			{Name: "moderate", Min: 25},
+			{Name: "high", Min: 50},
+			{Name: "severe", Min: 75},
+		},
+	}
+}
+
+// LoadConfig reads a YAML (or JSON) config file over DefaultConfig.
+func LoadConfig(path string) (Config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("reading risk config: %w", err)
+	}
+	cfg, err := ParseConfig(raw)
+	if err != nil {
+		return Config{}, fmt.Errorf("parsing risk config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ParseConfig parses a YAML (or JSON) config over DefaultConfig and
+// validates it. Maps are merged key by key; lists replace the defaults.
+func ParseConfig(raw []byte) (Config, error) {
+	cfg := DefaultConfig()
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// Validate reports the first problem with c.
+func (c Config) Validate() error {
+	var total float64
+	for name, w := range c.Weights {
+		if !knownFactor(name) {
+			return fmt.Errorf("unknown factor %q in weights", name)
+		}
+		if w < 0 {
+			return fmt.Errorf("weight of %s is negative", name)
+		}
+		total += w
+	}
+	if total == 0 {
+		return fmt.Errorf("weights sum to zero")
+	}
+
+	for class := range c.Zone {
+		switch class {
+		case ZoneClassCoastal, ZoneClassSFHA, ZoneClassModerate, ZoneClassMinimal, ZoneClassUndetermined:
+		default:
+			return fmt.Errorf("unknown zone class %q", class)
+		}
+	}
+	for ind := range c.Elevation {
+		switch ind {
+		case BelowBFE, NearBFE, AboveBFE, WellAboveBFE:
+		default:
+			return fmt.Errorf("unknown elevation indicator %q", ind)
+		}
+	}
+
+	for name, d := range map[string]Distance{FactorCoastline: c.Coastline, FactorWaterbody: c.Waterbody} {
+		if d.NearKm < 0 || d.FarKm <= d.NearKm {
+			return fmt.Errorf("%s: far_km must be greater than near_km, which must not be negative", name)
+		}
+	}
+
+	if !sort.SliceIsSorted(c.Bands, func(i, j int) bool { return c.Bands[i].Min < c.Bands[j].Min }) {
+		return fmt.Errorf("bands must be in ascending order of min")
+	}
+	return nil
+}
+
+func knownFactor(name string) bool {
+	for _, f := range factorNames {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
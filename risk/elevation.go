@@ -0,0 +1,267 @@
+// Package risk turns flood data into indicators underwriters can act on.
+// AnalyzeElevation compares a property's ground elevation with the base
+// flood elevation that applies to it, and a Scorer combines it with the
+// flood zone, distance to water, storm surge and the building into one
+// score, weighted by a Config and explained factor by factor.
+package risk
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/datum"
+	"github.com/kmesiab/go-nationalflooddata/models"
+)
+
+// Indicator classifies a property's elevation against its BFE.
+type Indicator string
+
+// Elevation indicators, from most to least exposed. The bands follow
+// common freeboard requirements: one foot above the BFE is the usual
+// minimum, and three feet or more rarely floods.
+const (
+	// BelowBFE means the ground is below the BFE, so the base flood would
+	// inundate it.
+	BelowBFE Indicator = "below_bfe"
+
+	// NearBFE means the ground is at the BFE or less than a foot above it.
+	NearBFE Indicator = "near_bfe"
+
+	// AboveBFE means the ground is one to three feet above the BFE.
+	AboveBFE Indicator = "above_bfe"
+
+	// WellAboveBFE means the ground is three feet or more above the BFE.
+	WellAboveBFE Indicator = "well_above_bfe"
+
+	// NoBFE means no BFE applies, as in A and X zones, or none could be
+	// put on the property's datum, or the property elevation is missing.
+	NoBFE Indicator = "no_bfe"
+)
+
+// Freeboard bands, in meters, between the indicators.
+const (
+	nearBFEMeters      = 0.3048
+	wellAboveBFEMeters = 3 * 0.3048
+)
+
+// BFE sources, saying how ElevationAnalysis.BFE was chosen.
+const (
+	// SourceArea is a BFE for the whole flood area the property is in,
+	// as in coastal zones with static BFEs.
+	SourceArea = "area"
+
+	// SourceInterpolated is interpolated between the nearest two BFE
+	// lines of different elevations.
+	SourceInterpolated = "interpolated"
+
+	// SourceNearest is the nearest BFE line.
+	SourceNearest = "nearest"
+)
+
+// DefaultMaxDistanceKm is the default ElevationOptions.MaxDistanceKm.
+const DefaultMaxDistanceKm = 0.5
+
+// ElevationOptions adjust AnalyzeElevation.
+type ElevationOptions struct {
+	// Datum converts NGVD29 BFEs to NAVD88, the datum of the property
+	// elevation. Without it, NGVD29 BFEs are skipped.
+	Datum datum.OffsetProvider
+
+	// Lat and Lng locate the property, for Datum.
+	Lat, Lng float64
+
+	// FloodMap adds the BFE lines of a GetFloodMapRaw response around the
+	// property to those of the elevation data, for interpolation.
+	FloodMap *client.FloodMapContent
+
+	// MaxDistanceKm is how far away BFE lines are considered. Zero means
+	// DefaultMaxDistanceKm.
+	MaxDistanceKm float64
+}
+
+// BFEReference is a BFE considered by AnalyzeElevation.
+type BFEReference struct {
+	// LineID is the BFE line's bfe_ln_id, or empty for an area BFE.
+	LineID string `json:"line_id,omitempty"`
+
+	// Height is the BFE on NAVD88, in meters.
+	Height models.Height `json:"height"`
+
+	// Original is the BFE as published, before datum conversion.
+	Original models.Height `json:"original"`
+
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// ElevationAnalysis is the result of AnalyzeElevation. Heights are in
+// meters on NAVD88.
+type ElevationAnalysis struct {
+	// Property is the property elevation, or nil if the API reported none.
+	Property *models.Height `json:"property,omitempty"`
+
+	// BFE is the BFE applying to the property, chosen from Area,
+	// Interpolated and Nearest in that order, or nil if none does.
+	BFE       *models.Height `json:"bfe,omitempty"`
+	BFESource string         `json:"bfe_source,omitempty"`
+
+	// Area is the BFE of the flood area containing the property.
+	Area *BFEReference `json:"area,omitempty"`
+
+	// Interpolated is the BFE interpolated between the two Lines,
+	// weighted by inverse distance.
+	Interpolated *models.Height `json:"interpolated,omitempty"`
+	Lines        []BFEReference `json:"lines,omitempty"`
+
+	// Nearest is the closest BFE line.
+	Nearest *BFEReference `json:"nearest,omitempty"`
+
+	// DepthMeters is the property's height above BFE; negative when the
+	// ground is below it and the base flood would stand that deep.
+	DepthMeters float64   `json:"depth_m"`
+	Indicator   Indicator `json:"indicator"`
+
+	// Notes explain BFEs that were skipped, or a missing property
+	// elevation.
+	Notes []string `json:"notes,omitempty"`
+}
+
+// DepthFeet returns DepthMeters in feet.
+func (a ElevationAnalysis) DepthFeet() float64 {
+	return a.DepthMeters / 0.3048
+}
+
+// AnalyzeElevation compares e.PropertyElevation with the BFEs of e, and
+// of opts.FloodMap. Without a property elevation the indicator is NoBFE.
+func AnalyzeElevation(ctx context.Context, e models.Elevation, opts ElevationOptions) ElevationAnalysis {
+	a := ElevationAnalysis{Indicator: NoBFE}
+	property, ok := e.PropertyHeight()
+	if !ok {
+		a.Notes = append(a.Notes, "no property elevation reported")
+		return a
+	}
+	a.Property = &property
+
+	maxKm := opts.MaxDistanceKm
+	if maxKm == 0 {
+		maxKm = DefaultMaxDistanceKm
+	}
+
+	var lines []BFEReference
+	seen := make(map[string]bool)
+	addLine := func(ref BFEReference) {
+		if ref.DistanceKm > maxKm || ref.LineID != "" && seen[ref.LineID] {
+			return
+		}
+		seen[ref.LineID] = true
+		lines = append(lines, ref)
+	}
+
+	// Map lines first: their distances are to the line itself.
+	if opts.FloodMap != nil {
+		for _, item := range opts.FloodMap.Result.BFEList {
+			h, err := item.Height()
+			if ref, ok := a.reference(ctx, opts, item.BfeLnID, h, err, item.DistKm); ok {
+				addLine(ref)
+			}
+		}
+	}
+	for _, b := range e.FloodBaseFloodElevation {
+		var id string
+		if b.BfeLnID != nil {
+			id = *b.BfeLnID
+		}
+		h, err := b.Height()
+		ref, ok := a.reference(ctx, opts, id, h, err, b.DistKm)
+		switch {
+		case !ok:
+		case id == "" && b.DistKm == 0:
+			if a.Area == nil || ref.Height.Meters > a.Area.Height.Meters {
+				a.Area = &ref
+			}
+		default:
+			addLine(ref)
+		}
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].DistanceKm < lines[j].DistanceKm })
+	if len(lines) > 0 {
+		a.Nearest = &lines[0]
+		a.interpolate(lines)
+	}
+
+	switch {
+	case a.Area != nil:
+		a.BFE, a.BFESource = &a.Area.Height, SourceArea
+	case a.Interpolated != nil:
+		a.BFE, a.BFESource = a.Interpolated, SourceInterpolated
+	case a.Nearest != nil:
+		a.BFE, a.BFESource = &a.Nearest.Height, SourceNearest
+	default:
+		return a
+	}
+
+	a.DepthMeters = a.Property.Meters - a.BFE.Meters
+	switch {
+	case a.DepthMeters < 0:
+		a.Indicator = BelowBFE
+	case a.DepthMeters < nearBFEMeters:
+		a.Indicator = NearBFE
+	case a.DepthMeters < wellAboveBFEMeters:
+		a.Indicator = AboveBFE
+	default:
+		a.Indicator = WellAboveBFE
+	}
+	return a
+}
+
+// reference builds a BFEReference on NAVD88 from a parsed BFE, noting why
+// it was skipped if it cannot be.
+func (a *ElevationAnalysis) reference(ctx context.Context, opts ElevationOptions, id string, h models.Height, err error, distKm float64) (BFEReference, bool) {
+	name := id
+	if name == "" {
+		name = fmt.Sprintf("BFE at %g km", distKm)
+	}
+	if err != nil {
+		a.Notes = append(a.Notes, fmt.Sprintf("%s skipped: %v", name, err))
+		return BFEReference{}, false
+	}
+
+	ref := BFEReference{LineID: id, Height: h, Original: h, DistanceKm: distKm}
+	if h.Datum == models.NAVD88 {
+		return ref, true
+	}
+	if opts.Datum == nil {
+		a.Notes = append(a.Notes, fmt.Sprintf("%s skipped: on %s and no datum conversion was configured", name, h.Datum))
+		return BFEReference{}, false
+	}
+	ref.Height, err = datum.ToNAVD88(ctx, opts.Datum, h, opts.Lat, opts.Lng)
+	if err != nil {
+		a.Notes = append(a.Notes, fmt.Sprintf("%s skipped: %v", name, err))
+		return BFEReference{}, false
+	}
+	return ref, true
+}
+
+// interpolate sets Interpolated between the nearest line and the nearest
+// line of a different elevation, which lie on either side of the property
+// along the flooding source when it is between them.
+func (a *ElevationAnalysis) interpolate(lines []BFEReference) {
+	first := lines[0]
+	for _, second := range lines[1:] {
+		if math.Abs(second.Height.Meters-first.Height.Meters) < 1e-9 {
+			continue
+		}
+
+		d1, d2 := first.DistanceKm, second.DistanceKm
+		m := first.Height.Meters
+		if d1+d2 > 0 {
+			m = (first.Height.Meters*d2 + second.Height.Meters*d1) / (d1 + d2)
+		}
+		a.Interpolated = &models.Height{Meters: m, Datum: models.NAVD88}
+		a.Lines = []BFEReference{first, second}
+		return
+	}
+}
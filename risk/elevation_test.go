@@ -0,0 +1,121 @@
+package risk_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata/datum"
+	"github.com/kmesiab/go-nationalflooddata/models"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+	"github.com/kmesiab/go-nationalflooddata/risk"
+)
+
+func feet(f float64) float64 {
+	return f * 0.3048
+}
+
+func TestAnalyzeElevation(t *testing.T) {
+	resp, content := nfdtest.FloodData(t), nfdtest.FloodMapRaw(t)
+
+	a := risk.AnalyzeElevation(context.Background(), *resp.Result.Elevation, risk.ElevationOptions{FloodMap: content})
+
+	assert.Equal(t, risk.SourceArea, a.BFESource)
+	require.NotNil(t, a.BFE)
+	assert.InDelta(t, feet(6), a.BFE.Meters, 1e-9)
+	assert.InDelta(t, feet(6.1-6), a.DepthMeters, 1e-9)
+	assert.InDelta(t, a.DepthMeters/0.3048, a.DepthFeet(), 1e-9)
+	assert.Equal(t, risk.NearBFE, a.Indicator)
+
+	require.NotNil(t, a.Nearest)
+	assert.Equal(t, "12099C_4402", a.Nearest.LineID)
+
+	// 6 ft at 0.05 km and 7 ft at 0.21 km.
+	require.NotNil(t, a.Interpolated)
+	assert.InDelta(t, feet((6*0.21+7*0.05)/0.26), a.Interpolated.Meters, 1e-9)
+	require.Len(t, a.Lines, 2)
+	assert.Equal(t, "12099C_4410", a.Lines[1].LineID)
+	assert.Empty(t, a.Notes)
+}
+
+func TestAnalyzeElevation_ShouldInterpolateWithoutAreaBFE(t *testing.T) {
+	resp, content := nfdtest.FloodData(t), nfdtest.FloodMapRaw(t)
+	e := *resp.Result.Elevation
+	e.FloodBaseFloodElevation = e.FloodBaseFloodElevation[1:]
+
+	a := risk.AnalyzeElevation(context.Background(), e, risk.ElevationOptions{FloodMap: content})
+	assert.Equal(t, risk.SourceInterpolated, a.BFESource)
+	assert.Nil(t, a.Area)
+	assert.Equal(t, risk.BelowBFE, a.Indicator)
+	assert.Less(t, a.DepthMeters, 0.0)
+
+	// Without the map there is only one line.
+	a = risk.AnalyzeElevation(context.Background(), e, risk.ElevationOptions{})
+	assert.Equal(t, risk.SourceNearest, a.BFESource)
+	assert.Equal(t, "12099C_4410", a.Nearest.LineID)
+	assert.InDelta(t, feet(6.1-7), a.DepthMeters, 1e-9)
+
+	// Beyond MaxDistanceKm.
+	a = risk.AnalyzeElevation(context.Background(), e, risk.ElevationOptions{MaxDistanceKm: 0.1})
+	assert.Equal(t, risk.NoBFE, a.Indicator)
+	assert.Nil(t, a.BFE)
+}
+
+func TestAnalyzeElevation_Indicators(t *testing.T) {
+	bfe := []models.BaseFloodElevation{{Elevation: "10", LenUnit: "Feet", VDatum: "NAVD88"}}
+	tests := []struct {
+		ft   float64
+		want risk.Indicator
+	}{
+		{9, risk.BelowBFE},
+		{10, risk.NearBFE},
+		{10.9, risk.NearBFE},
+		{11.5, risk.AboveBFE},
+		{13.5, risk.WellAboveBFE},
+	}
+	for _, tt := range tests {
+		e := models.Elevation{PropertyElevation: tt.ft, FloodBaseFloodElevation: bfe}
+		a := risk.AnalyzeElevation(context.Background(), e, risk.ElevationOptions{})
+		assert.Equal(t, tt.want, a.Indicator, "%g ft", tt.ft)
+	}
+
+	a := risk.AnalyzeElevation(context.Background(), models.Elevation{PropertyElevation: 3}, risk.ElevationOptions{})
+	assert.Equal(t, risk.NoBFE, a.Indicator)
+}
+
+func TestAnalyzeElevation_ShouldSkipMissingPropertyElevation(t *testing.T) {
+	resp, content := nfdtest.FloodData(t), nfdtest.FloodMapRaw(t)
+	e := *resp.Result.Elevation
+	e.PropertyElevation = models.MissingElevation
+
+	a := risk.AnalyzeElevation(context.Background(), e, risk.ElevationOptions{FloodMap: content})
+	assert.Equal(t, risk.NoBFE, a.Indicator)
+	assert.Nil(t, a.Property)
+	assert.Nil(t, a.BFE)
+	assert.Zero(t, a.DepthMeters)
+	assert.Equal(t, []string{"no property elevation reported"}, a.Notes)
+}
+
+func TestAnalyzeElevation_ShouldConvertNGVD29(t *testing.T) {
+	e := models.Elevation{
+		PropertyElevation: 6,
+		FloodBaseFloodElevation: []models.BaseFloodElevation{
+			{Elevation: "7.5", LenUnit: "Feet", VDatum: "NGVD29"},
+			{Elevation: "n/a", LenUnit: "Feet", VDatum: "NAVD88", DistKm: 0.1},
+		},
+	}
+
+	a := risk.AnalyzeElevation(context.Background(), e, risk.ElevationOptions{})
+	assert.Equal(t, risk.NoBFE, a.Indicator)
+	assert.Len(t, a.Notes, 2)
+	assert.Contains(t, a.Notes[0], "no datum conversion")
+
+	a = risk.AnalyzeElevation(context.Background(), e, risk.ElevationOptions{Datum: datum.FixedFeet(-1.5)})
+	require.NotNil(t, a.Area)
+	assert.InDelta(t, 7.5, a.Area.Original.Feet(), 1e-9)
+	assert.InDelta(t, 6, a.Area.Height.Feet(), 1e-9)
+	assert.InDelta(t, 0, a.DepthMeters, 1e-9)
+	assert.Equal(t, risk.NearBFE, a.Indicator)
+}
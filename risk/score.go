@@ -0,0 +1,299 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/models"
+)
+
+// Zone classes, the keys of Config.Zone.
+const (
+	ZoneClassCoastal      = "coastal"
+	ZoneClassSFHA         = "sfha"
+	ZoneClassModerate     = "moderate"
+	ZoneClassMinimal      = "minimal"
+	ZoneClassUndetermined = "undetermined"
+)
+
+// Score is a composite flood risk score with its explanation.
+type Score struct {
+	// Total is the weighted mean of the factors with data, from 0 to 100.
+	Total float64 `json:"total"`
+
+	// Band is the name of the Config.Bands range Total falls in.
+	Band string `json:"band,omitempty"`
+
+	// Factors explain Total, one per factor in Config.Weights, including
+	// those without data.
+	Factors []Factor `json:"factors"`
+}
+
+// Factor is one component of a Score.
+type Factor struct {
+	Name string `json:"name"`
+
+	// Input is the data the factor was scored from, such as "AE" or
+	// "0.48 km".
+	Input string `json:"input,omitempty"`
+
+	// Score is the factor's score, from 0 to 100.
+	Score float64 `json:"score"`
+
+	// Weight is the configured weight, and Share the fraction of Total it
+	// accounts for after factors without data are left out.
+	Weight float64 `json:"weight"`
+	Share  float64 `json:"share"`
+
+	// Contribution is Score times Share: what the factor adds to Total.
+	Contribution float64 `json:"contribution"`
+
+	// Missing is set when the response had no data for the factor.
+	Missing bool `json:"missing,omitempty"`
+
+	Reason string `json:"reason"`
+}
+
+// Scorer scores flood data with a Config.
+type Scorer struct {
+	cfg Config
+}
+
+// NewScorer returns a Scorer for cfg.
+func NewScorer(cfg Config) (*Scorer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("risk config: %w", err)
+	}
+	return &Scorer{cfg: cfg}, nil
+}
+
+// Score scores resp. opts configure the elevation factor, as for
+// AnalyzeElevation; its location defaults to the response's coordinates.
+func (s *Scorer) Score(ctx context.Context, resp *client.Response, opts ElevationOptions) Score {
+	if opts.Lat == 0 && opts.Lng == 0 {
+		opts.Lat, _ = strconv.ParseFloat(resp.Coords.Lat, 64)
+		opts.Lng, _ = strconv.ParseFloat(resp.Coords.Lng, 64)
+	}
+
+	result := resp.Result
+	scorers := map[string]func() Factor{
+		FactorZone:       func() Factor { return s.zone(result.FloodFldHazAr) },
+		FactorCoastline:  func() Factor { return s.coastline(result.Elevation) },
+		FactorWaterbody:  func() Factor { return s.waterbody(result.Elevation) },
+		FactorStormSurge: func() Factor { return s.stormSurge(result.Elevation) },
+		FactorElevation:  func() Factor { return s.elevation(ctx, result.Elevation, opts) },
+		FactorProperty:   func() Factor { return s.property(result.Property, result.Community) },
+	}
+
+	var score Score
+	var weights float64
+	for _, name := range factorNames {
+		w, ok := s.cfg.Weights[name]
+		if !ok {
+			continue
+		}
+		f := scorers[name]()
+		f.Name, f.Weight = name, w
+		if !f.Missing {
+			weights += w
+		}
+		score.Factors = append(score.Factors, f)
+	}
+
+	for i := range score.Factors {
+		f := &score.Factors[i]
+		if f.Missing || weights == 0 {
+			continue
+		}
+		f.Share = f.Weight / weights
+		f.Contribution = f.Score * f.Share
+		score.Total += f.Contribution
+	}
+
+	for _, b := range s.cfg.Bands {
+		if score.Total >= b.Min {
+			score.Band = b.Name
+		}
+	}
+	return score
+}
+
+func missing(reason string) Factor {
+	return Factor{Missing: true, Reason: reason}
+}
+
+// zoneClass returns the Config.Zone class of z, or "" if it has none.
+func zoneClass(z models.FloodZone) string {
+	switch {
+	case z.IsCoastalHighHazard():
+		return ZoneClassCoastal
+	case z.IsSFHA():
+		return ZoneClassSFHA
+	}
+	switch z.AnnualChance() {
+	case models.AnnualChancePointTwoPercent:
+		return ZoneClassModerate
+	case models.AnnualChanceMinimal:
+		return ZoneClassMinimal
+	case models.AnnualChanceUndetermined:
+		return ZoneClassUndetermined
+	}
+	return ""
+}
+
+// zone scores the most hazardous zone the property is in.
+func (s *Scorer) zone(hazards []models.FloodFieldHazard) Factor {
+	var f Factor
+	found := false
+	for _, h := range hazards {
+		z := h.Zone()
+		class := zoneClass(z)
+		if class == "" {
+			continue
+		}
+		if v := s.cfg.Zone[class]; !found || v > f.Score {
+			found = true
+			f = Factor{
+				Input:  string(z),
+				Score:  v,
+				Reason: fmt.Sprintf("zone %s is %s (%s annual chance)", z, class, z.AnnualChance()),
+			}
+		}
+	}
+	if !found {
+		return missing("no classified flood zone")
+	}
+	return f
+}
+
+// distance scores the nearest of kms with d. names label each distance;
+// what labels those without a name.
+func distance(d Distance, what string, kms []float64, names []string) Factor {
+	if len(kms) == 0 {
+		return Factor{Reason: fmt.Sprintf("no %s reported nearby", what)}
+	}
+	nearest := 0
+	for i, km := range kms {
+		if km < kms[nearest] {
+			nearest = i
+		}
+	}
+	km := kms[nearest]
+	if names[nearest] != "" {
+		what = names[nearest]
+	}
+
+	score := 100 * (d.FarKm - km) / (d.FarKm - d.NearKm)
+	score = math.Max(0, math.Min(100, score))
+	return Factor{
+		Input:  fmt.Sprintf("%g km", km),
+		Score:  score,
+		Reason: fmt.Sprintf("%s is %g km away; full score within %g km, none beyond %g km", what, km, d.NearKm, d.FarKm),
+	}
+}
+
+func (s *Scorer) coastline(e *models.Elevation) Factor {
+	if e == nil {
+		return missing("no elevation data")
+	}
+	kms, names := make([]float64, len(e.Coastline)), make([]string, len(e.Coastline))
+	for i, c := range e.Coastline {
+		kms[i] = c.DistKm
+	}
+	return distance(s.cfg.Coastline, "coastline", kms, names)
+}
+
+func (s *Scorer) waterbody(e *models.Elevation) Factor {
+	if e == nil {
+		return missing("no elevation data")
+	}
+	kms, names := make([]float64, len(e.Waterbody)), make([]string, len(e.Waterbody))
+	for i, w := range e.Waterbody {
+		kms[i], names[i] = w.DistKm, w.Name
+	}
+	return distance(s.cfg.Waterbody, "waterbody", kms, names)
+}
+
+// stormSurge scores the lowest category that floods the property.
+func (s *Scorer) stormSurge(e *models.Elevation) Factor {
+	if e == nil {
+		return missing("no elevation data")
+	}
+	surge := e.StormSurge
+	for i, v := range []*float64{surge.Category1, surge.Category2, surge.Category3, surge.Category4, surge.Category5} {
+		if v != nil && *v > 0 {
+			return Factor{
+				Input:  fmt.Sprintf("category %d: %g", i+1, *v),
+				Score:  s.cfg.StormSurge[i],
+				Reason: fmt.Sprintf("a category %d hurricane surge reaches the property (%g)", i+1, *v),
+			}
+		}
+	}
+	return Factor{Reason: "no hurricane category surge reaches the property"}
+}
+
+func (s *Scorer) elevation(ctx context.Context, e *models.Elevation, opts ElevationOptions) Factor {
+	if e == nil {
+		return missing("no elevation data")
+	}
+	if _, ok := e.PropertyHeight(); !ok {
+		return missing("no property elevation")
+	}
+	a := AnalyzeElevation(ctx, *e, opts)
+	if a.Indicator == NoBFE {
+		return missing("no base flood elevation applies")
+	}
+	return Factor{
+		Input: fmt.Sprintf("%.2f ft", a.DepthFeet()),
+		Score: s.cfg.Elevation[a.Indicator],
+		Reason: fmt.Sprintf("the ground is %.2f ft %s the %s BFE (%s)",
+			math.Abs(a.DepthFeet()), aboveOrBelow(a.DepthMeters), a.BFESource, a.Indicator),
+	}
+}
+
+func aboveOrBelow(depth float64) string {
+	if depth < 0 {
+		return "below"
+	}
+	return "above"
+}
+
+// property scores the building's FIRM status and number of stories.
+func (s *Scorer) property(p *models.Property, c *models.Community) Factor {
+	if p == nil {
+		return missing("no property data")
+	}
+
+	cfg := s.cfg.Property
+	attrs := p.Attributes()
+	f := Factor{Score: cfg.Base}
+	reasons := []string{fmt.Sprintf("base %g", cfg.Base)}
+	var inputs []string
+
+	if attrs.YearBuilt != nil {
+		inputs = append(inputs, fmt.Sprintf("built %d", *attrs.YearBuilt))
+	}
+	if status := attrs.FIRMStatus(c); status != models.FIRMStatusUnknown {
+		inputs = append(inputs, string(status))
+		if status == models.PreFIRM {
+			f.Score += cfg.PreFIRM
+			reasons = append(reasons, fmt.Sprintf("+%g pre-FIRM, built before floodplain standards applied", cfg.PreFIRM))
+		}
+	}
+	if attrs.Stories != nil {
+		inputs = append(inputs, fmt.Sprintf("%d stories", *attrs.Stories))
+		if *attrs.Stories == 1 {
+			f.Score += cfg.SingleStory
+			reasons = append(reasons, fmt.Sprintf("+%g single story", cfg.SingleStory))
+		}
+	}
+
+	f.Score = math.Min(f.Score, 100)
+	f.Input = strings.Join(inputs, ", ")
+	f.Reason = strings.Join(reasons, "; ")
+	return f
+}
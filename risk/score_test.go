@@ -0,0 +1,164 @@
+package risk_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kmesiab/go-nationalflooddata/models"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+	"github.com/kmesiab/go-nationalflooddata/risk"
+)
+
+func factor(t *testing.T, s risk.Score, name string) risk.Factor {
+	t.Helper()
+	for _, f := range s.Factors {
+		if f.Name == name {
+			return f
+		}
+	}
+	t.Fatalf("no factor %s", name)
+	return risk.Factor{}
+}
+
+func TestScorer_Score(t *testing.T) {
+	resp := nfdtest.FloodData(t)
+	scorer, err := risk.NewScorer(risk.DefaultConfig())
+	require.NoError(t, err)
+
+	s := scorer.Score(context.Background(), resp, risk.ElevationOptions{})
+
+	names := make([]string, len(s.Factors))
+	for i, f := range s.Factors {
+		names[i] = f.Name
+	}
+	assert.Equal(t, []string{"zone", "coastline", "waterbody", "storm_surge", "elevation", "property"}, names)
+
+	zone := factor(t, s, risk.FactorZone)
+	assert.Equal(t, "AE", zone.Input)
+	assert.Equal(t, 85.0, zone.Score)
+	assert.InDelta(t, 0.35, zone.Share, 1e-9)
+	assert.InDelta(t, 29.75, zone.Contribution, 1e-9)
+
+	assert.Equal(t, 100.0, factor(t, s, risk.FactorCoastline).Score)
+
+	water := factor(t, s, risk.FactorWaterbody)
+	assert.InDelta(t, 100*(2-0.22)/1.9, water.Score, 1e-9)
+	assert.Contains(t, water.Reason, "Lake Worth Lagoon is 0.22 km away")
+
+	surge := factor(t, s, risk.FactorStormSurge)
+	assert.Equal(t, 80.0, surge.Score)
+	assert.Equal(t, "category 2: 0.6", surge.Input)
+
+	elev := factor(t, s, risk.FactorElevation)
+	assert.Equal(t, 70.0, elev.Score)
+	assert.Contains(t, elev.Reason, "above the area BFE (near_bfe)")
+
+	prop := factor(t, s, risk.FactorProperty)
+	assert.Equal(t, 60.0, prop.Score)
+	assert.Equal(t, "built 1968, pre_firm, 2 stories", prop.Input)
+	assert.Contains(t, prop.Reason, "+40 pre-FIRM")
+
+	want := (85*35 + 100*10 + 100*(2-0.22)/1.9*10 + 80*15 + 70*20 + 60*10) / 100
+	assert.InDelta(t, want, s.Total, 1e-9)
+	assert.Equal(t, "severe", s.Band)
+
+	var sum float64
+	for _, f := range s.Factors {
+		sum += f.Contribution
+	}
+	assert.InDelta(t, s.Total, sum, 1e-9)
+}
+
+func TestScorer_Score_ShouldReweightMissingFactors(t *testing.T) {
+	resp := nfdtest.FloodData(t)
+	resp.Result.Elevation = nil
+	resp.Result.Property = nil
+
+	scorer, err := risk.NewScorer(risk.DefaultConfig())
+	require.NoError(t, err)
+	s := scorer.Score(context.Background(), resp, risk.ElevationOptions{})
+
+	for _, name := range []string{risk.FactorCoastline, risk.FactorWaterbody, risk.FactorStormSurge, risk.FactorElevation, risk.FactorProperty} {
+		f := factor(t, s, name)
+		assert.True(t, f.Missing, name)
+		assert.Zero(t, f.Contribution, name)
+	}
+	assert.InDelta(t, 1, factor(t, s, risk.FactorZone).Share, 1e-9)
+	assert.InDelta(t, 85, s.Total, 1e-9)
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := risk.LoadConfig(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	assert.Equal(t, 60.0, cfg.Zone[risk.ZoneClassModerate])
+	assert.Equal(t, 85.0, cfg.Zone[risk.ZoneClassSFHA], "defaults are kept")
+	assert.Len(t, cfg.Bands, 2)
+
+	resp := nfdtest.FloodData(t)
+	scorer, err := risk.NewScorer(cfg)
+	require.NoError(t, err)
+	s := scorer.Score(context.Background(), resp, risk.ElevationOptions{})
+	assert.InDelta(t, (85*60+70*40)/100.0, s.Total, 1e-9)
+	assert.Equal(t, "refer", s.Band)
+	assert.Zero(t, factor(t, s, risk.FactorCoastline).Share)
+
+	_, err = risk.LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "reading risk config")
+}
+
+func TestParseConfig_ShouldRejectInvalidConfigs(t *testing.T) {
+	tests := map[string]string{
+		"weights: {zone: -1}":                    "negative",
+		"weights: {elevator: 1}":                 "unknown factor",
+		"zone: {swamp: 1}":                       "unknown zone class",
+		"elevation: {no_bfe: 1}":                 "unknown elevation indicator",
+		"coastline: {near_km: 2, far_km: 1}":     "far_km must be greater",
+		"bands: [{name: b, min: 50}, {name: a}]": "ascending",
+		"weights: [1, 2]":                        "cannot unmarshal",
+	}
+	for in, want := range tests {
+		_, err := risk.ParseConfig([]byte(in))
+		assert.ErrorContains(t, err, want, in)
+	}
+
+	zero := risk.DefaultConfig()
+	for name := range zero.Weights {
+		zero.Weights[name] = 0
+	}
+	_, err := risk.NewScorer(zero)
+	assert.ErrorContains(t, err, "weights sum to zero")
+}
+
+func TestLoadConfig_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"weights": {"zone": 1}}`), 0o600))
+
+	cfg, err := risk.LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, cfg.Weights[risk.FactorZone])
+	assert.Equal(t, 20.0, cfg.Weights[risk.FactorElevation])
+}
+
+func TestScorer_Score_ShouldLeaveOutMissingPropertyElevation(t *testing.T) {
+	resp := nfdtest.FloodData(t)
+	resp.Result.Elevation.PropertyElevation = models.MissingElevation
+
+	scorer, err := risk.NewScorer(risk.DefaultConfig())
+	require.NoError(t, err)
+	s := scorer.Score(context.Background(), resp, risk.ElevationOptions{})
+
+	elev := factor(t, s, risk.FactorElevation)
+	assert.True(t, elev.Missing)
+	assert.Zero(t, elev.Score)
+	assert.Zero(t, elev.Contribution)
+	assert.Equal(t, "no property elevation", elev.Reason)
+
+	want := (85*35 + 100*10 + 100*(2-0.22)/1.9*10 + 80*15 + 60*10) / 80
+	assert.InDelta(t, want, s.Total, 1e-9)
+}
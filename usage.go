@@ -0,0 +1,198 @@
+package go_nationalflooddata
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kmesiab/go-nationalflooddata/client"
+)
+
+// UsageTracker receives a UsageRecord for every lookup a Service makes. The
+// usage package provides in-memory, CSV and JSONL implementations.
+type UsageTracker interface {
+	Record(rec UsageRecord) error
+}
+
+// AddOns are the optional, separately billed datasets requested by a lookup.
+type AddOns struct {
+	LOMA      bool `json:"loma"`
+	Elevation bool `json:"elevation"`
+	Property  bool `json:"property"`
+	Parcel    bool `json:"parcel"`
+}
+
+// String returns the enabled add-ons joined with "+", or "none".
+func (a AddOns) String() string {
+	var parts []string
+	if a.LOMA {
+		parts = append(parts, "loma")
+	}
+	if a.Elevation {
+		parts = append(parts, "elevation")
+	}
+	if a.Property {
+		parts = append(parts, "property")
+	}
+	if a.Parcel {
+		parts = append(parts, "parcel")
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, "+")
+}
+
+// UsageRecord describes a single lookup. A batch is recorded twice, one
+// record per lookup each time: when GetFloodDataBatch submits it, which is
+// never billable since the API has served nothing yet, and when
+// GetFloodDataBatchResult retrieves its results, which is.
+type UsageRecord struct {
+	// Time is when the call completed.
+	Time time.Time `json:"time"`
+
+	// Endpoint is the Service method that made the call, such as EndpointGetFloodData.
+	Endpoint string `json:"endpoint"`
+
+	// SearchType is the search type of flood data lookups; empty for map and tile calls.
+	SearchType client.SearchType `json:"searchtype,omitempty"`
+
+	// AddOns are the optional datasets the lookup requested. For batch
+	// results they are the add-on sections the result contains, so Parcel,
+	// which has no section of its own, is never set.
+	AddOns AddOns `json:"addons"`
+
+	// Tags are the caller-supplied tags attached with WithUsageTags.
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Status is the HTTP status code, or zero if no response was received.
+	Status int `json:"status"`
+
+	// Cached is true when the response was served from the Service's Cache
+	// and never reached the API.
+	Cached bool `json:"cached,omitempty"`
+
+	// Error is the error message for failed calls, and for batch results
+	// whose status is not OK.
+	Error string `json:"error,omitempty"`
+}
+
+// Billable reports whether the record represents a lookup the API served
+// successfully. Batch submissions are not billable; their results are.
+func (r UsageRecord) Billable() bool {
+	return r.Endpoint != EndpointGetFloodDataBatch && !r.Cached && r.Error == "" &&
+		r.Status >= 200 && r.Status <= 299
+}
+
+type usageTagsKey struct{}
+
+// WithUsageTags returns a context whose calls are recorded with tags, for
+// example {"team": "underwriting"}. Tags already on ctx are kept unless
+// overridden.
+func WithUsageTags(ctx context.Context, tags map[string]string) context.Context {
+	merged := make(map[string]string, len(tags))
+	for k, v := range UsageTags(ctx) {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, usageTagsKey{}, merged)
+}
+
+// UsageTags returns the usage tags attached to ctx.
+func UsageTags(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(usageTagsKey{}).(map[string]string)
+	return tags
+}
+
+// TagString formats tags as sorted "key=value" pairs joined by ";".
+func TagString(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + tags[k]
+	}
+	return strings.Join(pairs, ";")
+}
+
+// ParseTagString is the inverse of TagString.
+func ParseTagString(s string) map[string]string {
+	if s == "" {
+		return nil
+	}
+
+	tags := make(map[string]string)
+	for _, pair := range strings.Split(s, ";") {
+		k, v, _ := strings.Cut(pair, "=")
+		tags[k] = v
+	}
+	return tags
+}
+
+// recordUsage reports the outcome of a call to the UsageTracker, if any.
+// Failures to record are logged rather than failing the call.
+func (s *Service) recordUsage(ctx context.Context, endpoint string, opts interface{}, resp *http.Response, err error) {
+	if s.Usage == nil {
+		return
+	}
+
+	base := UsageRecord{
+		Time:     time.Now().UTC(),
+		Endpoint: endpoint,
+		Tags:     UsageTags(ctx),
+	}
+	if resp != nil {
+		base.Status = resp.StatusCode
+		base.Cached = resp.Header.Get(CacheStatusHeader) == "HIT"
+	}
+	if err != nil {
+		base.Error = err.Error()
+	}
+
+	var records []UsageRecord
+	switch o := opts.(type) {
+	case client.FloodDataOptions:
+		rec := base
+		rec.SearchType = o.SearchType
+		rec.AddOns = AddOns{LOMA: o.LOMA, Elevation: o.Elevation, Property: o.Property, Parcel: o.Parcel}
+		records = append(records, rec)
+	case client.BatchDataRequest:
+		for _, r := range o.Requests {
+			rec := base
+			rec.SearchType = r.SearchType
+			rec.AddOns = AddOns{LOMA: r.LOMA, Elevation: r.Elevation, Property: r.Property, Parcel: r.Parcel}
+			records = append(records, rec)
+		}
+	case []client.BatchResult:
+		for _, r := range o {
+			rec := base
+			rec.SearchType = client.SearchType(r.Request.Searchtype)
+			rec.AddOns = AddOns{
+				LOMA:      r.Result.Loma != nil,
+				Elevation: r.Result.Elevation != nil,
+				Property:  r.Result.Property != nil,
+			}
+			if r.Status != "OK" && rec.Error == "" {
+				rec.Error = fmt.Sprintf("batch request %s: status %q", r.ID, r.Status)
+			}
+			records = append(records, rec)
+		}
+	default:
+		records = append(records, base)
+	}
+
+	for _, rec := range records {
+		if recErr := s.Usage.Record(rec); recErr != nil && s.Logger != nil {
+			s.Logger.Printf("nfd: recording usage for %s: %v", endpoint, recErr)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package usage
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+	"github.com/kmesiab/go-nationalflooddata/client"
+)
+
+// csvHeader is the column layout written by CSVLedger and expected by ReadCSV.
+var csvHeader = []string{
+	"time", "endpoint", "searchtype", "loma", "elevation", "property", "parcel",
+	"tags", "status", "cached", "error",
+}
+
+// CSVLedger is an nfd.UsageTracker that appends one CSV row per record. It
+// is safe for concurrent use.
+type CSVLedger struct {
+	mu  sync.Mutex
+	w   io.Writer
+	csv *csv.Writer
+}
+
+// NewCSVLedger returns a CSVLedger writing to w. When header is true the
+// column names are written first; pass false when appending to an existing
+// ledger.
+func NewCSVLedger(w io.Writer, header bool) (*CSVLedger, error) {
+	l := &CSVLedger{w: w, csv: csv.NewWriter(w)}
+
+	if header {
+		if err := l.write(csvHeader); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// OpenCSVLedger opens, or creates, the file at path for appending, writing
+// the header only if the file is new or empty. Close the ledger when done.
+func OpenCSVLedger(path string) (*CSVLedger, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening usage ledger: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("opening usage ledger: %w", err)
+	}
+
+	l, err := NewCSVLedger(f, info.Size() == 0)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// Record implements nfd.UsageTracker.
+func (l *CSVLedger) Record(rec nfd.UsageRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.write([]string{
+		rec.Time.UTC().Format(time.RFC3339Nano),
+		rec.Endpoint,
+		string(rec.SearchType),
+		strconv.FormatBool(rec.AddOns.LOMA),
+		strconv.FormatBool(rec.AddOns.Elevation),
+		strconv.FormatBool(rec.AddOns.Property),
+		strconv.FormatBool(rec.AddOns.Parcel),
+		nfd.TagString(rec.Tags),
+		strconv.Itoa(rec.Status),
+		strconv.FormatBool(rec.Cached),
+		rec.Error,
+	})
+}
+
+// Close closes the underlying writer if it is an io.Closer.
+func (l *CSVLedger) Close() error {
+	if c, ok := l.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (l *CSVLedger) write(row []string) error {
+	if err := l.csv.Write(row); err != nil {
+		return fmt.Errorf("writing usage record: %w", err)
+	}
+	l.csv.Flush()
+	if err := l.csv.Error(); err != nil {
+		return fmt.Errorf("writing usage record: %w", err)
+	}
+	return nil
+}
+
+// ReadCSV reads every record written by a CSVLedger.
+func ReadCSV(r io.Reader) ([]nfd.UsageRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(csvHeader)
+
+	var records []nfd.UsageRecord
+	for line := 1; ; line++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading usage ledger: %w", err)
+		}
+		if line == 1 && row[0] == csvHeader[0] {
+			continue
+		}
+
+		rec, err := parseCSVRow(row)
+		if err != nil {
+			return nil, fmt.Errorf("usage ledger line %d: %w", line, err)
+		}
+		records = append(records, rec)
+	}
+}
+
+func parseCSVRow(row []string) (nfd.UsageRecord, error) {
+	var (
+		rec  nfd.UsageRecord
+		errs []error
+	)
+
+	parseBool := func(s string) bool {
+		b, err := strconv.ParseBool(s)
+		errs = append(errs, err)
+		return b
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, row[0])
+	errs = append(errs, err)
+	status, err := strconv.Atoi(row[8])
+	errs = append(errs, err)
+
+	rec.Time = t
+	rec.Endpoint = row[1]
+	rec.SearchType = client.SearchType(row[2])
+	rec.AddOns = nfd.AddOns{
+		LOMA:      parseBool(row[3]),
+		Elevation: parseBool(row[4]),
+		Property:  parseBool(row[5]),
+		Parcel:    parseBool(row[6]),
+	}
+	rec.Tags = nfd.ParseTagString(row[7])
+	rec.Status = status
+	rec.Cached = parseBool(row[9])
+	rec.Error = row[10]
+
+	return rec, errors.Join(errs...)
+}
@@ -0,0 +1,78 @@
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+)
+
+// JSONLLedger is an nfd.UsageTracker that appends one JSON object per
+// record to a writer. It is safe for concurrent use.
+type JSONLLedger struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+}
+
+// NewJSONLLedger returns a JSONLLedger writing to w.
+func NewJSONLLedger(w io.Writer) *JSONLLedger {
+	return &JSONLLedger{w: w, enc: json.NewEncoder(w)}
+}
+
+// OpenJSONLLedger opens, or creates, the file at path for appending. Close
+// the ledger when done.
+func OpenJSONLLedger(path string) (*JSONLLedger, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening usage ledger: %w", err)
+	}
+	return NewJSONLLedger(f), nil
+}
+
+// Record implements nfd.UsageTracker.
+func (l *JSONLLedger) Record(rec nfd.UsageRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.enc.Encode(rec); err != nil {
+		return fmt.Errorf("writing usage record: %w", err)
+	}
+	return nil
+}
+
+// Close closes the underlying writer if it is an io.Closer.
+func (l *JSONLLedger) Close() error {
+	if c, ok := l.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// ReadJSONL reads every record written by a JSONLLedger.
+func ReadJSONL(r io.Reader) ([]nfd.UsageRecord, error) {
+	var records []nfd.UsageRecord
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var rec nfd.UsageRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("usage ledger line %d: %w", line, err)
+		}
+		records = append(records, rec)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading usage ledger: %w", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,45 @@
+// Package usage provides UsageTracker backends for recording National Flood
+// Data lookups, and a report API summarising them for cost accounting.
+package usage
+
+import (
+	"sync"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+)
+
+// MemoryLedger is an in-process nfd.UsageTracker. It is safe for concurrent use.
+type MemoryLedger struct {
+	mu      sync.Mutex
+	records []nfd.UsageRecord
+}
+
+// NewMemoryLedger returns an empty MemoryLedger.
+func NewMemoryLedger() *MemoryLedger {
+	return &MemoryLedger{}
+}
+
+// Record implements nfd.UsageTracker.
+func (l *MemoryLedger) Record(rec nfd.UsageRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.records = append(l.records, rec)
+	return nil
+}
+
+// Records returns a copy of every record in the ledger.
+func (l *MemoryLedger) Records() []nfd.UsageRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]nfd.UsageRecord(nil), l.records...)
+}
+
+// Reset discards every record in the ledger.
+func (l *MemoryLedger) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.records = nil
+}
@@ -0,0 +1,194 @@
+package usage
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+)
+
+// Dimension is a way of grouping records in a Report.
+type Dimension struct {
+	// Name is the column heading for the dimension.
+	Name string
+
+	// Value extracts the group value from a record.
+	Value func(rec nfd.UsageRecord) string
+}
+
+var (
+	// ByEndpoint groups records by the Service method that made the call.
+	ByEndpoint = Dimension{Name: "endpoint", Value: func(rec nfd.UsageRecord) string {
+		return rec.Endpoint
+	}}
+
+	// BySearchType groups records by flood data search type.
+	BySearchType = Dimension{Name: "searchtype", Value: func(rec nfd.UsageRecord) string {
+		return string(rec.SearchType)
+	}}
+
+	// ByAddOns groups records by the combination of add-ons requested.
+	ByAddOns = Dimension{Name: "addons", Value: func(rec nfd.UsageRecord) string {
+		return rec.AddOns.String()
+	}}
+
+	// ByMonth groups records by UTC calendar month, formatted as 2006-01.
+	ByMonth = Dimension{Name: "month", Value: func(rec nfd.UsageRecord) string {
+		return rec.Time.UTC().Format("2006-01")
+	}}
+)
+
+// ByTag groups records by the value of a usage tag, such as "team".
+func ByTag(name string) Dimension {
+	return Dimension{Name: "tag:" + name, Value: func(rec nfd.UsageRecord) string {
+		return rec.Tags[name]
+	}}
+}
+
+// Counts are the totals for one group of records.
+type Counts struct {
+	// Lookups is the number of records in the group.
+	Lookups int
+
+	// Billable is the number of lookups the API served successfully. Batch
+	// lookups count when their results are retrieved, not when submitted.
+	Billable int
+
+	// Cached is the number of lookups served from the Service's cache.
+	Cached int
+
+	// Failed is the number of lookups that returned an error.
+	Failed int
+
+	// LOMA, Elevation, Property and Parcel count billable lookups that
+	// requested each add-on.
+	LOMA      int
+	Elevation int
+	Property  int
+	Parcel    int
+}
+
+func (c *Counts) add(rec nfd.UsageRecord) {
+	c.Lookups++
+	if rec.Cached {
+		c.Cached++
+	}
+	if rec.Error != "" {
+		c.Failed++
+	}
+	if !rec.Billable() {
+		return
+	}
+
+	c.Billable++
+	if rec.AddOns.LOMA {
+		c.LOMA++
+	}
+	if rec.AddOns.Elevation {
+		c.Elevation++
+	}
+	if rec.AddOns.Property {
+		c.Property++
+	}
+	if rec.AddOns.Parcel {
+		c.Parcel++
+	}
+}
+
+// Row is one group in a Report.
+type Row struct {
+	// Group holds the value of each of the Report's dimensions, in order.
+	Group []string
+
+	Counts
+}
+
+// Report summarises usage records over a period.
+type Report struct {
+	// From and To bound the period covered; zero values leave it open.
+	From, To time.Time
+
+	// Dimensions are the names of the dimensions rows are grouped by.
+	Dimensions []string
+
+	// Rows are sorted by group.
+	Rows []Row
+
+	// Total covers every record in the period.
+	Total Counts
+}
+
+// Summarize groups the records whose time falls in [from, to) by dims. Zero
+// times leave that end of the period open.
+func Summarize(records []nfd.UsageRecord, from, to time.Time, dims ...Dimension) Report {
+	report := Report{From: from, To: to}
+	for _, d := range dims {
+		report.Dimensions = append(report.Dimensions, d.Name)
+	}
+
+	groups := make(map[string]*Row)
+	for _, rec := range records {
+		if (!from.IsZero() && rec.Time.Before(from)) || (!to.IsZero() && !rec.Time.Before(to)) {
+			continue
+		}
+
+		group := make([]string, len(dims))
+		for i, d := range dims {
+			group[i] = d.Value(rec)
+		}
+
+		key := strings.Join(group, "\x00")
+		row, ok := groups[key]
+		if !ok {
+			row = &Row{Group: group}
+			groups[key] = row
+		}
+
+		row.add(rec)
+		report.Total.add(rec)
+	}
+
+	for _, row := range groups {
+		report.Rows = append(report.Rows, *row)
+	}
+	sort.Slice(report.Rows, func(i, j int) bool {
+		return strings.Join(report.Rows[i].Group, "\x00") < strings.Join(report.Rows[j].Group, "\x00")
+	})
+
+	return report
+}
+
+// WriteCSV writes the report as CSV, one row per group.
+func (r Report) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+
+	header := append(append([]string(nil), r.Dimensions...),
+		"lookups", "billable", "cached", "failed", "loma", "elevation", "property", "parcel")
+	if err := out.Write(header); err != nil {
+		return fmt.Errorf("writing usage report: %w", err)
+	}
+
+	for _, row := range r.Rows {
+		record := append(append([]string(nil), row.Group...),
+			strconv.Itoa(row.Lookups),
+			strconv.Itoa(row.Billable),
+			strconv.Itoa(row.Cached),
+			strconv.Itoa(row.Failed),
+			strconv.Itoa(row.LOMA),
+			strconv.Itoa(row.Elevation),
+			strconv.Itoa(row.Property),
+			strconv.Itoa(row.Parcel),
+		)
+		if err := out.Write(record); err != nil {
+			return fmt.Errorf("writing usage report: %w", err)
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
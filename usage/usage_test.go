@@ -0,0 +1,204 @@
+package usage_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nfd "github.com/kmesiab/go-nationalflooddata"
+	"github.com/kmesiab/go-nationalflooddata/client"
+	"github.com/kmesiab/go-nationalflooddata/nfdtest"
+	"github.com/kmesiab/go-nationalflooddata/usage"
+)
+
+type RoundTripFunc func(req *http.Request) *http.Response
+
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+func newTrackedService(t *testing.T, tracker nfd.UsageTracker, body string, opts ...nfd.Option) *nfd.Service {
+	t.Helper()
+
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Header:     make(http.Header),
+			}
+		}),
+	}
+
+	opts = append([]nfd.Option{nfd.WithHTTPClient(httpClient), nfd.WithUsageTracker(tracker)}, opts...)
+	service, err := nfd.NewService("test-api-key", opts...)
+	require.NoError(t, err)
+
+	return service
+}
+
+func sampleRecords() []nfd.UsageRecord {
+	march := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	april := time.Date(2026, 4, 2, 9, 30, 0, 0, time.UTC)
+
+	return []nfd.UsageRecord{
+		{Time: march, Endpoint: nfd.EndpointGetFloodData, SearchType: client.SearchTypeAddressParcel,
+			AddOns: nfd.AddOns{LOMA: true, Property: true}, Tags: map[string]string{"team": "underwriting"}, Status: 200},
+		{Time: march, Endpoint: nfd.EndpointGetFloodData, SearchType: client.SearchTypeCoord,
+			Tags: map[string]string{"team": "underwriting"}, Status: 200, Cached: true},
+		{Time: march, Endpoint: nfd.EndpointGetFloodData, SearchType: client.SearchTypeCoord,
+			Tags: map[string]string{"team": "claims"}, Status: 404, Error: "Location not found: Address/ Parcel not found"},
+		{Time: april, Endpoint: nfd.EndpointGetFloodDataBatch, SearchType: client.SearchTypeCoord,
+			AddOns: nfd.AddOns{Elevation: true}, Tags: map[string]string{"team": "claims"}, Status: 200},
+		{Time: april, Endpoint: nfd.EndpointGetFloodDataBatchResult, SearchType: client.SearchTypeCoord,
+			AddOns: nfd.AddOns{Elevation: true}, Tags: map[string]string{"team": "claims"}, Status: 200},
+	}
+}
+
+func TestMemoryLedger_ShouldRecordLookupsWithSearchTypeAddOnsAndTags(t *testing.T) {
+	ledger := usage.NewMemoryLedger()
+	service := newTrackedService(t, ledger, `{"status": "OK"}`)
+
+	ctx := nfd.WithUsageTags(context.Background(), map[string]string{"team": "underwriting"})
+	_, err := service.GetFloodData(ctx, client.FloodDataOptions{
+		SearchType: client.SearchTypeAddressParcel,
+		Address:    "123 Test St",
+		LOMA:       true,
+		Parcel:     true,
+	})
+	require.NoError(t, err)
+
+	records := ledger.Records()
+	require.Len(t, records, 1)
+	assert.Equal(t, nfd.EndpointGetFloodData, records[0].Endpoint)
+	assert.Equal(t, client.SearchTypeAddressParcel, records[0].SearchType)
+	assert.Equal(t, nfd.AddOns{LOMA: true, Parcel: true}, records[0].AddOns)
+	assert.Equal(t, map[string]string{"team": "underwriting"}, records[0].Tags)
+	assert.Equal(t, http.StatusOK, records[0].Status)
+	assert.True(t, records[0].Billable())
+}
+
+func TestMemoryLedger_ShouldRecordOneLookupPerBatchRequest(t *testing.T) {
+	ledger := usage.NewMemoryLedger()
+	service := newTrackedService(t, ledger, `{"batch_id": "b1"}`)
+
+	_, err := service.GetFloodDataBatch(context.Background(), client.BatchDataRequest{
+		Requests: []client.BatchRequest{
+			{ID: "r1", SearchType: client.SearchTypeCoord, Elevation: true},
+			{ID: "r2", SearchType: client.SearchTypeAddressCoord},
+		},
+	})
+	require.NoError(t, err)
+
+	records := ledger.Records()
+	require.Len(t, records, 2)
+	assert.Equal(t, client.SearchTypeCoord, records[0].SearchType)
+	assert.True(t, records[0].AddOns.Elevation)
+	assert.Equal(t, client.SearchTypeAddressCoord, records[1].SearchType)
+	assert.False(t, records[0].Billable())
+	assert.False(t, records[1].Billable())
+}
+
+func TestMemoryLedger_ShouldBillBatchLookupsWhenRetrieved(t *testing.T) {
+	srv := nfdtest.NewServer()
+	defer srv.Close()
+
+	ledger := usage.NewMemoryLedger()
+	service, err := srv.NewService(nfd.WithUsageTracker(ledger))
+	require.NoError(t, err)
+
+	batch, err := service.GetFloodDataBatch(context.Background(), client.BatchDataRequest{
+		Requests: []client.BatchRequest{
+			{ID: "r1", SearchType: client.SearchTypeAddressParcel, Address: "430 Australian Ave Palm Beach, FL 33480", Elevation: true},
+			{ID: "r2", SearchType: client.SearchTypeCoord, Lat: "26.7032", Lng: "-80.0424"},
+		},
+	})
+	require.NoError(t, err)
+	results, err := service.WaitFloodDataBatch(context.Background(), batch, time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	report := usage.Summarize(ledger.Records(), time.Time{}, time.Time{}, usage.ByEndpoint)
+	require.Len(t, report.Rows, 2)
+	assert.Equal(t, []string{nfd.EndpointGetFloodDataBatch}, report.Rows[0].Group)
+	assert.Equal(t, usage.Counts{Lookups: 2}, report.Rows[0].Counts)
+	assert.Equal(t, []string{nfd.EndpointGetFloodDataBatchResult}, report.Rows[1].Group)
+	assert.Equal(t, usage.Counts{Lookups: 2, Billable: 2, Elevation: 1}, report.Rows[1].Counts)
+}
+
+func TestMemoryLedger_ShouldMarkCacheHitsAsNotBillable(t *testing.T) {
+	ledger := usage.NewMemoryLedger()
+	service := newTrackedService(t, ledger, "tile", nfd.WithCache(nfd.NewMemoryCache(time.Minute)))
+
+	for i := 0; i < 2; i++ {
+		_, err := service.GetFloodVectorTile(context.Background(), 13, 2043, 3140)
+		require.NoError(t, err)
+	}
+
+	records := ledger.Records()
+	require.Len(t, records, 2)
+	assert.True(t, records[0].Billable())
+	assert.True(t, records[1].Cached)
+	assert.False(t, records[1].Billable())
+}
+
+func TestCSVLedger_ShouldRoundTripRecords(t *testing.T) {
+	var buf bytes.Buffer
+	ledger, err := usage.NewCSVLedger(&buf, true)
+	require.NoError(t, err)
+
+	for _, rec := range sampleRecords() {
+		require.NoError(t, ledger.Record(rec))
+	}
+
+	records, err := usage.ReadCSV(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, sampleRecords(), records)
+}
+
+func TestJSONLLedger_ShouldRoundTripRecords(t *testing.T) {
+	var buf bytes.Buffer
+	ledger := usage.NewJSONLLedger(&buf)
+
+	for _, rec := range sampleRecords() {
+		require.NoError(t, ledger.Record(rec))
+	}
+
+	records, err := usage.ReadJSONL(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, sampleRecords(), records)
+}
+
+func TestSummarize_ShouldGroupByTagWithinPeriod(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	report := usage.Summarize(sampleRecords(), from, to, usage.ByTag("team"))
+
+	require.Len(t, report.Rows, 2)
+	assert.Equal(t, []string{"claims"}, report.Rows[0].Group)
+	assert.Equal(t, usage.Counts{Lookups: 1, Failed: 1}, report.Rows[0].Counts)
+	assert.Equal(t, []string{"underwriting"}, report.Rows[1].Group)
+	assert.Equal(t, usage.Counts{Lookups: 2, Billable: 1, Cached: 1, LOMA: 1, Property: 1}, report.Rows[1].Counts)
+	assert.Equal(t, 3, report.Total.Lookups)
+}
+
+func TestReport_WriteCSV_ShouldWriteOneRowPerGroup(t *testing.T) {
+	report := usage.Summarize(sampleRecords(), time.Time{}, time.Time{}, usage.ByMonth, usage.ByEndpoint)
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteCSV(&buf))
+
+	expected := "month,endpoint,lookups,billable,cached,failed,loma,elevation,property,parcel\n" +
+		"2026-03,GetFloodData,3,1,1,1,1,0,1,0\n" +
+		"2026-04,GetFloodDataBatch,1,0,0,0,0,0,0,0\n" +
+		"2026-04,GetFloodDataBatchResult,1,1,0,0,0,1,0,0\n"
+	assert.Equal(t, expected, buf.String())
+}